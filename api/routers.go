@@ -1,32 +1,84 @@
 package api
 
 import (
-	"neighborguard/api/handlers"
+	v1 "neighborguard/api/v1"
+	"neighborguard/api/v1/handlers"
+	"neighborguard/pkg/health"
 	"neighborguard/pkg/middleware"
+	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// Date the unversioned routes were replaced by /v1, and the date they will be removed
+var (
+	unversionedDeprecatedSince = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	unversionedSunset          = time.Date(2027, time.April, 18, 0, 0, 0, 0, time.UTC)
+)
+
+// Date the singular /user routes were replaced by /users
+var legacyUserRoutesDeprecatedSince = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// Options are what the routes depend on
+type Options struct {
+	Liveness       *health.Registry
+	Readiness      *health.Registry
+	RequestTimeout time.Duration           // after which requests are cancelled, unless their route needs longer
+	Limiter        *middleware.RateLimiter // rate limits by route, none if nil
+	Idempotency    *middleware.Idempotency // replays responses to retried requests of idempotent routes, none if nil
+}
+
 // SetupRoutes sets up the routes for the API
-func SetupRoutes(router *mux.Router) *mux.Router {
-	// Health check endpoint
+func SetupRoutes(router *mux.Router, options Options) *mux.Router {
+	// Answer unmatched requests with problem details too
+	router.NotFoundHandler = http.HandlerFunc(handlers.NotFound)
+	router.MethodNotAllowedHandler = http.HandlerFunc(handlers.MethodNotAllowed)
+
+	// Health check endpoints, not part of any API version
 	router.HandleFunc("/healthz", middleware.Chain(handlers.HealthHandler, middleware.Logging())).Methods("GET")
+	router.HandleFunc("/livez", middleware.Chain(handlers.ProbeHandler(options.Liveness), middleware.Logging())).Methods("GET")
+	router.HandleFunc("/readyz", middleware.Chain(handlers.ProbeHandler(options.Readiness), middleware.Logging())).Methods("GET")
 
-	// Collection endpoints (plural)
-	router.HandleFunc("/users/recipients", middleware.Chain(handlers.GetNearbyRecipients, middleware.Logging())).Methods("GET")
+	// Prometheus metrics
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
 
-	// Single user endpoints (singular)
-	router.HandleFunc("/user", middleware.Chain(handlers.CreateUser, middleware.Logging())).Methods("POST")
-	router.HandleFunc("/user/{email}", middleware.Chain(handlers.GetUserByEmail, middleware.Logging())).Methods("GET")
-	router.HandleFunc("/user/{uid}", middleware.Chain(handlers.UpdateUser, middleware.Logging())).Methods("PUT")
+	// Current API version
+	for _, route := range v1.Routes {
+		router.HandleFunc(v1.Prefix+route.Path, middleware.Chain(route.Handler, routeMiddlewares(route, options)...)).Methods(route.Method)
+	}
 
-	// Meeting endpoints
-	router.HandleFunc("/meeting", middleware.Chain(handlers.CreateMeeting, middleware.Logging())).Methods("POST")
-	router.HandleFunc("/meeting/{uid}/{userID}", middleware.Chain(handlers.CancelMeeting, middleware.Logging())).Methods("DELETE")
-	router.HandleFunc("/meeting/{uid}/status", middleware.Chain(handlers.UpdateMeetingStatus, middleware.Logging())).Methods("PUT")
+	// Unversioned routes serve the v1 endpoints for mobile clients that were not updated,
+	// announcing their removal on every response. They share the rate limits and
+	// idempotency keys of their v1 route.
+	legacy := middleware.DeprecatedVersion(unversionedDeprecatedSince, unversionedSunset, v1.Prefix)
+	for _, route := range v1.Routes {
+		router.HandleFunc(route.Path, middleware.Chain(route.Handler, append(routeMiddlewares(route, options), legacy)...)).Methods(route.Method)
+	}
 
-	// Collection endpoint for getting meetings
-	router.HandleFunc("/meetings", middleware.Chain(handlers.GetMeetings, middleware.Logging())).Methods("GET")
+	// Deprecated single user endpoints (singular), only available without a version.
+	// They share the rate limits of their successor, so GET /user/{email} cannot be
+	// used to enumerate emails faster than GET /users?email=, and POST /user shares its
+	// idempotency keys. Their clients still send and expect lastOK as Unix seconds.
+	timeout := middleware.Timeout(options.RequestTimeout)
+	unixTimes := middleware.UnixTimes("lastOK")
+	router.HandleFunc("/user", middleware.Chain(handlers.CreateUser, unixTimes, middleware.Logging(), middleware.Deprecated(legacyUserRoutesDeprecatedSince, v1.Prefix+"/users"), options.Idempotency.Route("POST /users"), options.Limiter.Route("POST /users"), timeout, legacy)).Methods("POST")
+	router.HandleFunc("/user/{email}", middleware.Chain(handlers.GetUserByEmail, unixTimes, middleware.Logging(), middleware.Deprecated(legacyUserRoutesDeprecatedSince, v1.Prefix+"/users?email={email}"), options.Limiter.Route("GET /users"), timeout, legacy)).Methods("GET")
+	router.HandleFunc("/user/{uid}", middleware.Chain(handlers.UpdateUser, unixTimes, middleware.Logging(), middleware.Deprecated(legacyUserRoutesDeprecatedSince, v1.Prefix+"/users/{uid}"), options.Limiter.Route("PUT /users/{uid}"), timeout, legacy)).Methods("PUT")
+	router.HandleFunc("/user/{uid}", middleware.Chain(handlers.PatchUser, unixTimes, middleware.Logging(), middleware.Deprecated(legacyUserRoutesDeprecatedSince, v1.Prefix+"/users/{uid}"), options.Limiter.Route("PATCH /users/{uid}"), timeout, legacy)).Methods("PATCH")
 
 	return router
 }
+
+// routeMiddlewares returns the middlewares every alias of a v1 route is served with,
+// innermost first. Retries replayed by the idempotency middleware still count
+// against the rate limit.
+func routeMiddlewares(route v1.Route, options Options) []middleware.Middleware {
+	name := route.Method + " " + route.Path
+	middlewares := []middleware.Middleware{}
+	if v1.Idempotent[name] {
+		middlewares = append(middlewares, options.Idempotency.Route(name))
+	}
+	return append(middlewares, options.Limiter.Route(name), middleware.Timeout(route.Timeout(options.RequestTimeout)))
+}
@@ -1,7 +0,0 @@
-package schemas
-
-import "neighborguard/pkg/services"
-
-type SearchMeetingsResponseSchema struct {
-    Meetings []services.Meeting `json:"meetings"`
-}
\ No newline at end of file
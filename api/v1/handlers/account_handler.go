@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"neighborguard/api/v1/schemas"
+	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/services"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+var errInvalidExportFormat = &services.Error{Kind: errBadRequest, Code: "invalid_export_format", Message: "export format must be json or zip"}
+
+// DeleteUser godoc
+// @Summary Delete a user
+// @Description Cancel the user's active meetings and anonymize their personal data. Completed meetings are kept for statistics. Only the user themselves or an administrator may delete an account.
+// @Tags user
+// @Param uid path string true "User ID"
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /users/{uid} [delete]
+func DeleteUser(w http.ResponseWriter, r *http.Request) {
+	uid := mux.Vars(r)["uid"]
+
+	if err := services.DeleteUser(r.Context(), middleware.GetUserID(r.Context()), uid); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportUser godoc
+// @Summary Export a user's data
+// @Description Get a copy of the user's profile, meetings, check-ins, schedule and the audit entries about them, as JSON or as a ZIP archive of JSON files. Only the user themselves or an administrator may export an account.
+// @Tags user
+// @Produce json,application/zip
+// @Param uid path string true "User ID"
+// @Param format query string false "json (default) or zip"
+// @Security BearerAuth
+// @Success 200 {object} schemas.UserExportSchema
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /users/{uid}/export [get]
+func ExportUser(w http.ResponseWriter, r *http.Request) {
+	uid := mux.Vars(r)["uid"]
+
+	// The format is taken from the query, or from the Accept header if not given
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+		if strings.Contains(r.Header.Get("Accept"), "application/zip") {
+			format = "zip"
+		}
+	}
+	if format != "json" && format != "zip" {
+		writeError(w, r, errInvalidExportFormat)
+		return
+	}
+
+	export, err := services.ExportUserData(r.Context(), middleware.GetUserID(r.Context()), uid)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := schemas.NewUserExport(export)
+	filename := fmt.Sprintf("neighborguard-%s-%s", uid, export.ExportedAt.Format("20060102"))
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".json"))
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// One JSON file per part of the export
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+	archive := zip.NewWriter(w)
+	type exportFile struct {
+		name    string
+		content interface{}
+	}
+	files := []exportFile{
+		{"profile.json", response.Profile},
+		{"meetings.json", response.Meetings},
+		{"check-ins.json", response.CheckIns},
+		{"audit.json", response.AuditEntries},
+		{"series.json", response.Series},
+	}
+	if response.Availability != nil {
+		files = append(files, exportFile{"availability.json", response.Availability})
+	}
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			break
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			break
+		}
+	}
+	archive.Close()
+}
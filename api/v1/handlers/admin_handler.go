@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"neighborguard/api/v1/schemas"
+	"neighborguard/pkg/logging"
+	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/services"
+	"net/http"
+	"strings"
+)
+
+var errInvalidLogLevel = &services.Error{Kind: errBadRequest, Code: "invalid_log_level", Message: "level must be one of debug, info, warn or error"}
+
+// GetLogLevel godoc
+// @Summary Get the log level
+// @Description Get the current level of the server's logs. Only administrators can use this endpoint.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} schemas.LogLevelSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Router /admin/log-level [get]
+func GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	if err := services.AuthorizeAdmin(r.Context(), middleware.GetUserID(r.Context())); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeLogLevel(w)
+}
+
+// SetLogLevel godoc
+// @Summary Change the log level
+// @Description Change the level of the server's logs while it runs, such as to debug an issue. The level is reset to the configured one on restart. Only administrators can use this endpoint.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param level body schemas.LogLevelSchema true "New log level"
+// @Success 200 {object} schemas.LogLevelSchema
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Router /admin/log-level [put]
+func SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	if err := services.AuthorizeAdmin(r.Context(), actorID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var body schemas.LogLevelSchema
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	level, err := logging.ParseLevel(body.Level)
+	if err != nil {
+		writeError(w, r, errInvalidLogLevel)
+		return
+	}
+
+	previous := logging.Level()
+	logging.SetLevel(level)
+	slog.WarnContext(r.Context(), "Log level changed", "from", previous.String(), "to", level.String(), "by", actorID)
+
+	writeLogLevel(w)
+}
+
+func writeLogLevel(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.LogLevelSchema{Level: strings.ToLower(logging.Level().String())})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/services"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var errInvalidTimeRange = &services.Error{Kind: errBadRequest, Code: "invalid_time_range", Message: "from and to must be RFC 3339 times such as 2026-10-19T09:00:00+02:00"}
+
+// GetAvailability godoc
+// @Summary Get the free slots of a volunteer
+// @Description Get the weekly windows and exceptions a volunteer published, and the periods between from and to in which they can be booked: covered by their schedule and not taken by one of their meetings. Volunteers without a schedule are free whenever they have no meeting. Windows are wall clock times in the timezone of the schedule, so they follow daylight saving time, and times are RFC 3339 in that timezone.
+// @Tags user
+// @Produce json
+// @Param uid path string true "Volunteer ID"
+// @Param from query string false "Start of the range as an RFC 3339 time, now by default"
+// @Param to query string false "End of the range as an RFC 3339 time, at most 31 days after from; 7 days after from by default"
+// @Success 200 {object} services.FreeSlots
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /users/{uid}/availability [get]
+func GetAvailability(w http.ResponseWriter, r *http.Request) {
+	uid := mux.Vars(r)["uid"]
+
+	// The range starts now and spans the default range unless given
+	from, err := timeQuery(r, "from", time.Now())
+	if err != nil {
+		writeError(w, r, errInvalidTimeRange)
+		return
+	}
+	to, err := timeQuery(r, "to", from.Add(services.DefaultAvailabilityRange))
+	if err != nil {
+		writeError(w, r, errInvalidTimeRange)
+		return
+	}
+
+	slots, err := services.GetFreeSlots(r.Context(), uid, from, to)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(slots)
+}
+
+// SetAvailability godoc
+// @Summary Publish the schedule of a volunteer
+// @Description Replace the weekly windows in which a volunteer is available, as wall clock times in the timezone of the schedule (the volunteer's unless given), and the exceptions that make them unavailable or available in addition between two times. Once published, meetings can only be created in the schedule. Only the volunteer themselves or an administrator may change it.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param uid path string true "Volunteer ID"
+// @Security BearerAuth
+// @Param availability body services.NewAvailability true "Schedule of the volunteer"
+// @Success 200 {object} services.Availability
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /users/{uid}/availability [put]
+func SetAvailability(w http.ResponseWriter, r *http.Request) {
+	uid := mux.Vars(r)["uid"]
+
+	var newAvailability services.NewAvailability
+	if err := decodeJSON(r, &newAvailability); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	availability, err := services.SetAvailability(r.Context(), middleware.GetUserID(r.Context()), uid, newAvailability)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(availability)
+}
+
+// timeQuery parses a query parameter holding an RFC 3339 time, or returns fallback if it is missing
+func timeQuery(r *http.Request, name string, fallback time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
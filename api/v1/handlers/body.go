@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"neighborguard/pkg/services"
+	"neighborguard/pkg/validation"
+)
+
+// errPayloadTooLarge is the kind of failure of request bodies beyond the size limit
+var errPayloadTooLarge = errors.New("payload too large")
+
+var errBodyTooLarge = &services.Error{Kind: errPayloadTooLarge, Code: "body_too_large", Message: "request body is too large"}
+
+// decodeJSON strictly decodes a JSON request body into v. Unknown fields are
+// rejected, rather than silently dropped, as is anything after the JSON value.
+func decodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return bodyError(err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return bodyError(err)
+		}
+		return errMalformedBody
+	}
+	return nil
+}
+
+// bodyError describes why a request body could not be read or decoded
+func bodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errBodyTooLarge
+	}
+
+	// The decoder reports unknown fields as json: unknown field "name"
+	if field, unknown := strings.CutPrefix(err.Error(), "json: unknown field "); unknown {
+		return &services.Error{
+			Kind:    services.ErrValidation,
+			Code:    "validation_failed",
+			Message: "validation failed",
+			Cause:   validation.Errors{{Field: strings.Trim(field, `"`), Message: "is not a known field"}},
+		}
+	}
+	return errMalformedBody
+}
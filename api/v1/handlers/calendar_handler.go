@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"neighborguard/api/v1/schemas"
+	"neighborguard/pkg/ical"
+	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/services"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateCalendarToken godoc
+// @Summary Create a calendar subscription
+// @Description Create the token of the user's calendar feed, which calendar clients can subscribe to without signing in. A new token revokes the previous one. Only the user themselves or an administrator may create or revoke the subscription.
+// @Tags calendar
+// @Produce json
+// @Param uid path string true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} schemas.CalendarTokenSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /users/{uid}/calendar [post]
+func CreateCalendarToken(w http.ResponseWriter, r *http.Request) {
+	uid := mux.Vars(r)["uid"]
+
+	token, err := services.CreateCalendarToken(r.Context(), middleware.GetUserID(r.Context()), uid)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// The feed is next to this endpoint, under the same API version
+	path := strings.TrimSuffix(r.URL.Path, "/calendar") + "/meetings.ics?token=" + url.QueryEscape(token)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(schemas.CalendarTokenSchema{Token: token, Path: path})
+}
+
+// RevokeCalendarToken godoc
+// @Summary Revoke a calendar subscription
+// @Description Revoke the token of the user's calendar feed, so clients subscribed to it no longer get meetings. Only the user themselves or an administrator may create or revoke the subscription.
+// @Tags calendar
+// @Param uid path string true "User ID"
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /users/{uid}/calendar [delete]
+func RevokeCalendarToken(w http.ResponseWriter, r *http.Request) {
+	uid := mux.Vars(r)["uid"]
+
+	if err := services.RevokeCalendarToken(r.Context(), middleware.GetUserID(r.Context()), uid); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetCalendarFeed godoc
+// @Summary Get a user's calendar feed
+// @Description Get the user's meetings from the last 90 days on as an iCalendar (RFC 5545) feed. Events name the services and show the recipient's city and approximate location; cancelled meetings stay in the feed as cancelled events.
+// @Tags calendar
+// @Produce text/calendar
+// @Param uid path string true "User ID"
+// @Param token query string true "Token of the calendar subscription"
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /users/{uid}/meetings.ics [get]
+func GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	uid := mux.Vars(r)["uid"]
+
+	calendar, err := services.CalendarFeed(r.Context(), uid, r.URL.Query().Get("token"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	calendar.Encode(w)
+}
+
+// GetMeetingCalendar godoc
+// @Summary Download a meeting as iCalendar
+// @Description Get a single meeting as an iCalendar (RFC 5545) file to add to a calendar. Only the participants or an administrator may download it.
+// @Tags meeting
+// @Produce text/calendar
+// @Param uid path string true "Meeting ID"
+// @Security BearerAuth
+// @Success 200 {string} string "iCalendar file"
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /meeting/{uid}.ics [get]
+func GetMeetingCalendar(w http.ResponseWriter, r *http.Request) {
+	meetingID := mux.Vars(r)["uid"]
+
+	calendar, err := services.MeetingCalendar(r.Context(), middleware.GetUserID(r.Context()), meetingID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "neighborguard-meeting-"+meetingID+".ics"))
+	calendar.Encode(w)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// GetServiceDefinitions godoc
+// @Summary Get the service catalogue
+// @Description Get every service that users and meetings can reference
+// @Tags services
+// @Produce json
+// @Success 200 {array} services.ServiceDefinition
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /services [get]
+func GetServiceDefinitions(w http.ResponseWriter, r *http.Request) {
+	definitions, err := services.GetServiceDefinitions(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(definitions)
+}
+
+// GetServiceDefinition godoc
+// @Summary Get a catalogue service
+// @Description Get a single service of the catalogue by its ID
+// @Tags service
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {object} services.ServiceDefinition
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /service/{id} [get]
+func GetServiceDefinition(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	definition, err := services.GetServiceDefinition(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(definition)
+}
+
+// CreateServiceDefinition godoc
+// @Summary Add a service to the catalogue
+// @Description Add a service that users and meetings can reference by its ID. Its display names must not name another service. Only administrators can change the catalogue.
+// @Tags service
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param service body services.NewServiceDefinition true "Service to create"
+// @Success 200 {object} services.ServiceDefinition
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /service [post]
+func CreateServiceDefinition(w http.ResponseWriter, r *http.Request) {
+	var newDefinition services.NewServiceDefinition
+	if err := decodeJSON(r, &newDefinition); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	definition, err := services.CreateServiceDefinition(r.Context(), middleware.GetUserID(r.Context()), newDefinition)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(definition)
+}
+
+// UpdateServiceDefinition godoc
+// @Summary Update a catalogue service
+// @Description Update the display names and settings of a service. Its ID cannot change. Only administrators can change the catalogue.
+// @Tags service
+// @Accept json
+// @Produce json
+// @Param id path string true "Service ID"
+// @Security BearerAuth
+// @Param service body services.NewServiceDefinition true "Updated service"
+// @Success 200 {object} services.ServiceDefinition
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /service/{id} [put]
+func UpdateServiceDefinition(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var updatedDefinition services.NewServiceDefinition
+	if err := decodeJSON(r, &updatedDefinition); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	definition, err := services.UpdateServiceDefinition(r.Context(), middleware.GetUserID(r.Context()), id, updatedDefinition)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(definition)
+}
+
+// DeleteServiceDefinition godoc
+// @Summary Remove a service from the catalogue
+// @Description Remove a service that no user or meeting references. Only administrators can change the catalogue.
+// @Tags service
+// @Param id path string true "Service ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /service/{id} [delete]
+func DeleteServiceDefinition(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	err := services.DeleteServiceDefinition(r.Context(), middleware.GetUserID(r.Context()), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"neighborguard/pkg/services"
+)
+
+// MergePatchContentType is the media type of RFC 7396 JSON Merge Patch bodies
+const MergePatchContentType = "application/merge-patch+json"
+
+var errUnsupportedMediaType = errors.New("unsupported media type")
+
+var errNotMergePatch = &services.Error{Kind: errUnsupportedMediaType, Code: "unsupported_media_type", Message: "PATCH bodies must be " + MergePatchContentType}
+
+// setETag exposes the version of a resource as its entity tag
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// unmatchedVersion is the expected version of an If-Match header that no version matches
+const unmatchedVersion int64 = math.MinInt64
+
+// ifMatchVersion returns the version required by the If-Match header. It returns
+// services.AnyVersion if there is no precondition and unmatchedVersion if the
+// header can never match. Version 0 is the version of documents stored before
+// versioning existed, so "0" is a real precondition.
+func ifMatchVersion(r *http.Request) int64 {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return services.AnyVersion
+	}
+
+	tag := strings.TrimPrefix(ifMatch, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return unmatchedVersion
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 0 {
+		return unmatchedVersion
+	}
+	return version
+}
+
+// readMergePatch reads a JSON Merge Patch body, also accepting plain JSON
+func readMergePatch(r *http.Request) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != MergePatchContentType && mediaType != "application/json" {
+		return nil, errNotMergePatch
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, bodyError(err)
+	}
+	return patch, nil
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"neighborguard/api/v1/schemas"
+	"neighborguard/pkg/idempotency"
+	"neighborguard/pkg/logging"
+	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/services"
+	"neighborguard/pkg/validation"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errBadRequest is the kind of failure caused by a malformed request rather than its content
+var errBadRequest = errors.New("bad request")
+
+// Kinds of failure of requests whose context ended before they were served
+var (
+	errCanceled = errors.New("canceled")
+	errTimeout  = errors.New("timeout")
+)
+
+// errTooManyRequests is the kind of failure of clients that exceeded their rate limit
+var errTooManyRequests = errors.New("too many requests")
+
+// statusClientClosedRequest is the non-standard status, introduced by nginx, of
+// requests abandoned by their client
+const statusClientClosedRequest = 499
+
+// Failures detected by the handlers before reaching the service layer
+var (
+	errMalformedBody    = &services.Error{Kind: errBadRequest, Code: "malformed_body", Message: "request body is not valid JSON"}
+	errInvalidStatus    = &services.Error{Kind: errBadRequest, Code: "invalid_meeting_status", Message: "invalid meeting status"}
+	errMissingMeetingID = &services.Error{Kind: errBadRequest, Code: "meeting_id_required", Message: "meeting ID is required"}
+	errMissingEmail     = &services.Error{Kind: errBadRequest, Code: "email_required", Message: "email query parameter is required"}
+	errInternal         = &services.Error{Kind: errors.New("internal"), Code: "internal_error", Message: "internal server error"}
+	errRequestCanceled  = &services.Error{Kind: errCanceled, Code: "request_canceled", Message: "client closed the request"}
+	errRequestTimeout   = &services.Error{Kind: errTimeout, Code: "request_timeout", Message: "request took too long to serve"}
+)
+
+// Response status for each kind of failure
+var errorStatuses = []struct {
+	kind   error
+	status int
+}{
+	{services.ErrNotFound, http.StatusNotFound},
+	{services.ErrConflict, http.StatusConflict},
+	{services.ErrUnauthorized, http.StatusUnauthorized},
+	{services.ErrForbidden, http.StatusForbidden},
+	{services.ErrValidation, http.StatusUnprocessableEntity},
+	{services.ErrPreconditionFailed, http.StatusPreconditionFailed},
+	{errBadRequest, http.StatusBadRequest},
+	{errUnsupportedMediaType, http.StatusUnsupportedMediaType},
+	{errMethodNotAllowed, http.StatusMethodNotAllowed},
+	{errCanceled, statusClientClosedRequest},
+	{errTimeout, http.StatusGatewayTimeout},
+	{errTooManyRequests, http.StatusTooManyRequests},
+	{errPayloadTooLarge, http.StatusRequestEntityTooLarge},
+}
+
+// writeError answers with an RFC 7807 problem describing err.
+// Errors the service layer does not recognize are logged and hidden behind a generic 500.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	err = contextError(r, err)
+
+	status := http.StatusInternalServerError
+	for _, candidate := range errorStatuses {
+		if errors.Is(err, candidate.kind) {
+			status = candidate.status
+			break
+		}
+	}
+
+	var serviceError *services.Error
+	if status == http.StatusInternalServerError || !errors.As(err, &serviceError) {
+		slog.ErrorContext(r.Context(), "Internal error", "method", r.Method, "path", r.URL.Path, "error", err)
+		trace.SpanFromContext(r.Context()).RecordError(errors.New(logging.Redact(err.Error())))
+		serviceError = errInternal
+	}
+
+	problem := schemas.ProblemSchema{
+		Type:      "urn:neighborguard:error:" + serviceError.Code,
+		Title:     statusText(status),
+		Status:    status,
+		Detail:    serviceError.Message,
+		Code:      serviceError.Code,
+		RequestID: middleware.GetRequestID(r.Context()),
+	}
+
+	// List every invalid field of a rejected payload
+	var fieldErrors validation.Errors
+	if errors.As(err, &fieldErrors) {
+		problem.Errors = fieldErrors
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// contextError replaces the failure of a request whose context was cancelled, by
+// its client or its timeout, since whatever the service returned was caused by it
+func contextError(r *http.Request, err error) error {
+	switch {
+	case errors.Is(r.Context().Err(), context.Canceled), errors.Is(err, context.Canceled):
+		return errRequestCanceled
+	case errors.Is(r.Context().Err(), context.DeadlineExceeded), errors.Is(err, context.DeadlineExceeded):
+		return errRequestTimeout
+	}
+	return err
+}
+
+// statusText names a response status, including the non-standard ones
+func statusText(status int) string {
+	if status == statusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
+
+// Failures for requests that do not match any route
+var (
+	errRouteNotFound    = &services.Error{Kind: services.ErrNotFound, Code: "route_not_found", Message: "no such endpoint"}
+	errMethodNotAllowed = &services.Error{Kind: errors.New("method not allowed"), Code: "method_not_allowed", Message: "method not allowed on this endpoint"}
+	errCorsRejected     = &services.Error{Kind: services.ErrForbidden, Code: "cors_rejected", Message: "cross-origin request not allowed by the CORS policy"}
+	errRateLimited      = &services.Error{Kind: errTooManyRequests, Code: "rate_limited", Message: "too many requests, retry after the time given in Retry-After"}
+)
+
+// Failures of requests sent with an Idempotency-Key header
+var (
+	errInvalidIdempotencyKey = &services.Error{Kind: errBadRequest, Code: "invalid_idempotency_key", Message: idempotency.ErrInvalidKey.Error()}
+	errIdempotencyKeyReused  = &services.Error{Kind: services.ErrValidation, Code: "idempotency_key_reused", Message: "idempotency key was already used with a different request body"}
+	errIdempotencyInProgress = &services.Error{Kind: services.ErrConflict, Code: "idempotency_in_progress", Message: "a request with this idempotency key is still in progress, retry later"}
+)
+
+// NotFound answers requests that do not match any route
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, errRouteNotFound)
+}
+
+// MethodNotAllowed answers requests whose path matches a route but not its method
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, errMethodNotAllowed)
+}
+
+// TooManyRequests answers requests rejected by the rate limiter
+func TooManyRequests(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, errRateLimited)
+}
+
+// CorsRejected answers preflight requests that the CORS policy does not allow
+func CorsRejected(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, errCorsRejected)
+}
+
+// IdempotencyFailed answers idempotent requests that cannot be served or replayed
+func IdempotencyFailed(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, idempotency.ErrInvalidKey):
+		err = errInvalidIdempotencyKey
+	case errors.Is(err, idempotency.ErrKeyReused):
+		err = errIdempotencyKeyReused
+	case errors.Is(err, idempotency.ErrInProgress):
+		err = errIdempotencyInProgress
+	case errors.As(err, &tooLarge):
+		err = errBodyTooLarge
+	}
+	writeError(w, r, err)
+}
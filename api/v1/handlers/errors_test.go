@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"neighborguard/api/v1/schemas"
+	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/services"
+	"neighborguard/pkg/validation"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// serveError answers a request with writeError and decodes the problem it wrote
+func serveError(t *testing.T, err error) (*httptest.ResponseRecorder, schemas.ProblemSchema) {
+	t.Helper()
+	handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, err)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/users/ada@example.com", nil)
+	r.Header.Set(middleware.RequestIDHeader, "req-42")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, r)
+
+	var problem schemas.ProblemSchema
+	if err := json.NewDecoder(response.Body).Decode(&problem); err != nil {
+		t.Fatalf("response is not a problem: %v", err)
+	}
+	return response, problem
+}
+
+func TestWriteError(t *testing.T) {
+	fieldErrors := validation.Errors{
+		{Field: "email", Message: "is required"},
+		{Field: "age", Message: "must be between 1 and 120"},
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+		errors []validation.FieldError
+	}{
+		{"not found", services.ErrUserNotFound, http.StatusNotFound, "user_not_found", "user not found", nil},
+		{"conflict", services.ErrEmailTaken, http.StatusConflict, "email_taken", "user with this email already exists", nil},
+		{"forbidden", services.ErrVolunteersOnly, http.StatusForbidden, "volunteers_only", "only volunteers can use this endpoint", nil},
+		{"validation", &services.Error{Kind: services.ErrValidation, Code: "validation_failed", Message: "validation failed", Cause: fieldErrors}, http.StatusUnprocessableEntity, "validation_failed", "validation failed", fieldErrors},
+		{"bad request", errMalformedBody, http.StatusBadRequest, "malformed_body", "request body is not valid JSON", nil},
+		{"wrapped", fmt.Errorf("loading meeting: %w", services.ErrMeetingNotFound), http.StatusNotFound, "meeting_not_found", "meeting not found", nil},
+		{"unknown", errors.New("connection reset by 10.0.0.7"), http.StatusInternalServerError, "internal_error", "internal server error", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, problem := serveError(t, tt.err)
+
+			if response.Code != tt.status {
+				t.Errorf("status = %d, want %d", response.Code, tt.status)
+			}
+			if got := response.Header().Get("Content-Type"); got != "application/problem+json" {
+				t.Errorf("Content-Type = %q, want application/problem+json", got)
+			}
+
+			want := schemas.ProblemSchema{
+				Type:      "urn:neighborguard:error:" + tt.code,
+				Title:     http.StatusText(tt.status),
+				Status:    tt.status,
+				Detail:    tt.detail,
+				Code:      tt.code,
+				RequestID: "req-42",
+				Errors:    tt.errors,
+			}
+			if !reflect.DeepEqual(problem, want) {
+				t.Errorf("problem = %+v, want %+v", problem, want)
+			}
+		})
+	}
+}
+
+func TestWriteErrorHidesInternalDetails(t *testing.T) {
+	response, _ := serveError(t, errors.New("connection reset by 10.0.0.7"))
+	if strings.Contains(response.Body.String(), "10.0.0.7") {
+		t.Errorf("response %s reveals the internal error", response.Body.String())
+	}
+}
+
+func TestUnmatchedRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+		code    string
+	}{
+		{"not found", NotFound, http.StatusNotFound, "route_not_found"},
+		{"method not allowed", MethodNotAllowed, http.StatusMethodNotAllowed, "method_not_allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			tt.handler(response, httptest.NewRequest(http.MethodDelete, "/nowhere", nil))
+
+			var problem schemas.ProblemSchema
+			if err := json.NewDecoder(response.Body).Decode(&problem); err != nil {
+				t.Fatalf("response is not a problem: %v", err)
+			}
+			if response.Code != tt.status || problem.Code != tt.code {
+				t.Errorf("status = %d, code = %q, want %d and %q", response.Code, problem.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
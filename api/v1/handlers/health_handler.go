@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"neighborguard/pkg/health"
+	"net/http"
+)
+
+type HealthResponse struct {
+	Status string
+}
+
+// HealthHandler is the original health check, kept for existing monitors. It does
+// not check any dependency; use /livez and /readyz instead.
+func HealthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	newHealthResponse := HealthResponse{Status: "healthy"}
+	json.NewEncoder(w).Encode(newHealthResponse)
+}
+
+// ProbeHandler runs the checkers of a probe and reports each of them. It answers
+// 503 when a critical checker is down so the orchestrator reacts.
+func ProbeHandler(registry *health.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := registry.Run(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status == health.Down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	}
+}
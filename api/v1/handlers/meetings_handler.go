@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"neighborguard/api/v1/schemas"
+	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateMeeting godoc
+// @Summary Create a new meeting
+// @Description Create a new meeting between a volunteer and a recipient. Only the volunteer of the meeting may create it.
+// @Tags meeting
+// @Accept json
+// @Produce json
+// @Param meeting body services.NewMeeting true "Meeting to create"
+// @Security BearerAuth
+// @Param Idempotency-Key header string false "Key that makes retries replay the first response instead of repeating the request"
+// @Success 200 {object} schemas.MeetingResponseSchema
+// @Header 200 {string} ETag "Version of the created meeting"
+// @Header 200 {string} Idempotent-Replayed "true when the response is replayed for a retry"
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /meeting [post]
+func CreateMeeting(w http.ResponseWriter, r *http.Request) {
+	var newMeeting services.NewMeeting
+	if err := decodeJSON(r, &newMeeting); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	actorID := middleware.GetUserID(r.Context())
+	meeting, err := services.CreateMeeting(r.Context(), actorID, newMeeting)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Contact details are disclosed to the signed-in user, not whoever the payload names
+	setETag(w, meeting.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewMeetingResponse(meeting, actorID))
+}
+
+// GetMeetings godoc
+// @Summary Get meetings based on filters
+// @Description Get the meetings of the signed-in user, as recipient or volunteer, filtered by meeting status. Administrators may list the meetings of any user.
+// @Tags meetings
+// @Produce json
+// @Param userId query string false "ID of the user whose meetings to list, the signed-in user by default"
+// @Param status query string false "Meeting status to filter (IS_PICKED, DONE or CANCELLED)"
+// @Security BearerAuth
+// @Success 200 {object} schemas.SearchMeetingsResponseSchema
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /meetings [get]
+func GetMeetings(w http.ResponseWriter, r *http.Request) {
+	// Get query parameters
+	userId := r.URL.Query().Get("userId")
+	status := services.MeetingStatus(r.URL.Query().Get("status"))
+
+	// Validate status if provided
+	if status != "" && status != services.IsPicked && status != services.Done && status != services.Cancelled {
+		writeError(w, r, errInvalidStatus)
+		return
+	}
+
+	actorID := middleware.GetUserID(r.Context())
+	meetings, err := services.GetMeetings(r.Context(), actorID, userId, status)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Contact details are disclosed to the signed-in user, whoever the filter names
+	response := schemas.SearchMeetingsResponseSchema{Meetings: schemas.NewMeetingResponses(meetings, actorID)}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CancelMeeting godoc
+// @Summary Cancel an existing meeting
+// @Description Cancel a meeting by its ID as the signed-in user. Only the participants or an administrator may cancel a meeting.
+// @Tags meeting
+// @Produce json
+// @Param uid path string true "Meeting ID to cancel"
+// @Param userID path string true "Kept for existing clients, the signed-in user is the one cancelling"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /meeting/{uid}/{userID} [delete]
+func CancelMeeting(w http.ResponseWriter, r *http.Request) {
+	// Get meeting ID from URL parameters, the user cancelling is the signed-in one
+	// whoever the path names
+	meetingID := mux.Vars(r)["uid"]
+
+	// Validate inputs
+	if meetingID == "" {
+		writeError(w, r, errMissingMeetingID)
+		return
+	}
+
+	// Call the service layer to cancel the meeting
+	err := services.CancelMeeting(r.Context(), middleware.GetUserID(r.Context()), meetingID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Return 204 No Content on successful cancellation
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateMeetingStatus godoc
+// @Summary Update meeting status
+// @Description Update the status of an existing meeting. Only the participants or an administrator may update a meeting.
+// @Tags meeting
+// @Produce json
+// @Param uid path string true "Meeting ID"
+// @Param status query string true "New meeting status (IS_PICKED or DONE)"
+// @Security BearerAuth
+// @Param If-Match header string false "ETag of the version being updated"
+// @Success 200 {object} schemas.MeetingResponseSchema
+// @Header 200 {string} ETag "Version of the updated meeting"
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 412 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /meeting/{uid}/status [put]
+func UpdateMeetingStatus(w http.ResponseWriter, r *http.Request) {
+	// Get meeting ID from URL parameters
+	vars := mux.Vars(r)
+	meetingID := vars["uid"]
+
+	// Get status from query parameter
+	status := services.MeetingStatus(r.URL.Query().Get("status"))
+
+	// Validate the status
+	if status != services.IsPicked && status != services.Done {
+		writeError(w, r, errInvalidStatus)
+		return
+	}
+
+	// Update the meeting status
+	actorID := middleware.GetUserID(r.Context())
+	updatedMeeting, err := services.UpdateMeetingStatus(r.Context(), actorID, meetingID, status, ifMatchVersion(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, updatedMeeting.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewMeetingResponse(updatedMeeting, actorID))
+}
+
+// PatchMeeting godoc
+// @Summary Partially update a meeting
+// @Description Apply a JSON Merge Patch (RFC 7396) to the date and status of a meeting. Only the participants or an administrator may update a meeting. Send the meeting's ETag in If-Match to avoid overwriting concurrent changes.
+// @Tags meeting
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param uid path string true "Meeting ID"
+// @Security BearerAuth
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param patch body object true "Merge patch of the meeting"
+// @Success 200 {object} schemas.MeetingResponseSchema
+// @Header 200 {string} ETag "Version of the updated meeting"
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 412 {object} schemas.ProblemSchema
+// @Failure 415 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Router /meeting/{uid} [patch]
+func PatchMeeting(w http.ResponseWriter, r *http.Request) {
+	meetingID := mux.Vars(r)["uid"]
+
+	patch, err := readMergePatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	actorID := middleware.GetUserID(r.Context())
+	updatedMeeting, err := services.PatchMeeting(r.Context(), actorID, meetingID, patch, ifMatchVersion(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, updatedMeeting.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewMeetingResponse(updatedMeeting, actorID))
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"neighborguard/api/v1/schemas"
+	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateSeries godoc
+// @Summary Create a recurring meeting series
+// @Description Claim services of a recipient for a volunteer and meet following a recurrence rule, a subset of RFC 5545 RRULE: FREQ of DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY for weekly rules, and COUNT or UNTIL. Occurrences are created as meetings up to the series horizon ahead and every one of them must fit the volunteer's schedule. The services stay claimed by the volunteer between occurrences. Only the volunteer of the series may create it.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param series body services.NewSeries true "Series to create"
+// @Security BearerAuth
+// @Param Idempotency-Key header string false "Key that makes retries replay the first response instead of repeating the request"
+// @Success 200 {object} schemas.SeriesResponseSchema
+// @Header 200 {string} ETag "Version of the created series"
+// @Header 200 {string} Idempotent-Replayed "true when the response is replayed for a retry"
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /series [post]
+func CreateSeries(w http.ResponseWriter, r *http.Request) {
+	var newSeries services.NewSeries
+	if err := decodeJSON(r, &newSeries); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	actorID := middleware.GetUserID(r.Context())
+	series, occurrences, err := services.CreateSeries(r.Context(), actorID, newSeries)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Contact details are disclosed to the signed-in user, not whoever the payload names
+	setETag(w, series.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewSeriesResponse(series, occurrences, actorID))
+}
+
+// GetSeries godoc
+// @Summary Get a meeting series
+// @Description Get a meeting series and its upcoming occurrences. Only the participants or an administrator may read a series.
+// @Tags series
+// @Produce json
+// @Param id path string true "Series ID"
+// @Security BearerAuth
+// @Success 200 {object} schemas.SeriesResponseSchema
+// @Header 200 {string} ETag "Version of the series"
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /series/{id} [get]
+func GetSeries(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	series, occurrences, err := services.GetSeries(r.Context(), actorID, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, series.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewSeriesResponse(series, occurrences, actorID))
+}
+
+// PatchSeries godoc
+// @Summary Change a whole meeting series
+// @Description Apply a JSON Merge Patch (RFC 7396) to the start and rule of a series. Upcoming occurrences are replaced by those of the new rule, including occurrences cancelled or rescheduled on their own. To change a single occurrence, patch or cancel its meeting instead. Only the participants or an administrator may change a series.
+// @Tags series
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Series ID"
+// @Security BearerAuth
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param patch body object true "Merge patch of the series"
+// @Success 200 {object} schemas.SeriesResponseSchema
+// @Header 200 {string} ETag "Version of the updated series"
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 412 {object} schemas.ProblemSchema
+// @Failure 415 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /series/{id} [patch]
+func PatchSeries(w http.ResponseWriter, r *http.Request) {
+	patch, err := readMergePatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	actorID := middleware.GetUserID(r.Context())
+	series, occurrences, err := services.PatchSeries(r.Context(), actorID, mux.Vars(r)["id"], patch, ifMatchVersion(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, series.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewSeriesResponse(series, occurrences, actorID))
+}
+
+// CancelSeries godoc
+// @Summary Cancel a meeting series
+// @Description Stop a series and cancel its upcoming occurrences. The recipient's services are offered to other volunteers again, whoever cancels. Only the participants or an administrator may cancel a series.
+// @Tags series
+// @Param id path string true "Series ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /series/{id} [delete]
+func CancelSeries(w http.ResponseWriter, r *http.Request) {
+	if err := services.CancelSeries(r.Context(), middleware.GetUserID(r.Context()), mux.Vars(r)["id"]); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"neighborguard/api/v1/schemas"
+	"neighborguard/pkg/services"
+	"net/http"
+)
+
+// CreateSession godoc
+// @Summary Sign in
+// @Description Exchange the email and password of a user for a session token. Send the token as "Authorization: Bearer <token>" to identify the user on the other endpoints until it expires, then sign in again.
+// @Tags session
+// @Accept json
+// @Produce json
+// @Param credentials body services.Credentials true "Email and password of the user"
+// @Success 200 {object} schemas.SessionSchema
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Failure 429 {object} schemas.ProblemSchema
+// @Router /sessions [post]
+func CreateSession(w http.ResponseWriter, r *http.Request) {
+	var credentials services.Credentials
+	if err := decodeJSON(r, &credentials); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	session, err := services.CreateSession(r.Context(), credentials)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(schemas.NewSession(session))
+}
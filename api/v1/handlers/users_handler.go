@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"neighborguard/api/v1/schemas"
+	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetNearbyRecipients godoc
+// @Summary Get nearby recipients needing assistance
+// @Description Get recipients who need assistance matching the signed-in volunteer's languages and services
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param filterByLat query float64 false "Filter by latitude"
+// @Param filterByLon query float64 false "Filter by longitude"
+// @Success 200 {object} schemas.SearchUsersResponseSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Failure 429 {object} schemas.ProblemSchema
+// @Router /users/recipients [get]
+func GetNearbyRecipients(w http.ResponseWriter, r *http.Request) {
+	// Recipients are searched for the signed-in volunteer, never for whoever a query names
+	volunteerUID := middleware.GetUserID(r.Context())
+	if volunteerUID == "" {
+		writeError(w, r, services.ErrNotAuthenticated)
+		return
+	}
+
+	var filterByLat, filterByLon *float64
+	if filterByLatStr := r.URL.Query().Get("filterByLat"); filterByLatStr != "" {
+		if val, err := strconv.ParseFloat(filterByLatStr, 64); err == nil {
+			filterByLat = &val
+		}
+	}
+	if filterByLonStr := r.URL.Query().Get("filterByLon"); filterByLonStr != "" {
+		if val, err := strconv.ParseFloat(filterByLonStr, 64); err == nil {
+			filterByLon = &val
+		}
+	}
+
+	recipients, err := services.GetNearbyRecipients(r.Context(), volunteerUID, filterByLat, filterByLon)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Only expose the privacy-preserving summary of each recipient
+	users := make([]schemas.RecipientSummarySchema, 0, len(recipients))
+	for _, recipient := range recipients {
+		users = append(users, schemas.NewRecipientSummary(recipient))
+	}
+
+	response := schemas.SearchUsersResponseSchema{Users: users}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetRecipient godoc
+// @Summary Get a recipient as seen by a volunteer
+// @Description Get a recipient's summary. The full address and phone number are included only while the signed-in volunteer has an active meeting with the recipient.
+// @Tags users
+// @Produce json
+// @Param uid path string true "Recipient's UID"
+// @Security BearerAuth
+// @Success 200 {object} schemas.RecipientDetailSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /users/recipients/{uid} [get]
+func GetRecipient(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	recipientUID := vars["uid"]
+
+	// Contact details are disclosed to the signed-in volunteer, never to whoever a query names
+	volunteerUID := middleware.GetUserID(r.Context())
+	if volunteerUID == "" {
+		writeError(w, r, services.ErrNotAuthenticated)
+		return
+	}
+
+	recipient, disclosed, err := services.GetRecipientForVolunteer(r.Context(), recipientUID, volunteerUID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewRecipientDetail(recipient, disclosed))
+}
+
+// CreateUser godoc
+// @Summary Create a new user
+// @Description Create a new user. The response is the full profile of the new user, without the password.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param user body services.NewUser true "User object that needs to be created"
+// @Param Idempotency-Key header string false "Key that makes retries replay the first response instead of repeating the request"
+// @Success 200 {object} schemas.UserSchema
+// @Header 200 {string} ETag "Version of the created user"
+// @Header 200 {string} Idempotent-Replayed "true when the response is replayed for a retry"
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Failure 429 {object} schemas.ProblemSchema
+// @Router /users [post]
+func CreateUser(w http.ResponseWriter, r *http.Request) {
+	var newUser services.NewUser
+
+	if err := decodeJSON(r, &newUser); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	user, err := services.CreateUser(r.Context(), newUser)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// The new user is the one who sent the request
+	setETag(w, user.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewUser(user, true))
+}
+
+// UpdateUser godoc
+// @Summary Update an existing user
+// @Description Replace an existing user's updatable information. Only the user themselves or an administrator may update a profile. Send the user's ETag in If-Match to avoid overwriting concurrent changes.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param uid path string true "User ID"
+// @Security BearerAuth
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param user body services.User true "Updated user information"
+// @Success 200
+// @Header 200 {string} ETag "Version of the updated user"
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 412 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Router /users/{uid} [put]
+func UpdateUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	uid := vars["uid"]
+
+	var updatedUser services.User
+	if err := decodeJSON(r, &updatedUser); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	user, err := services.UpdateUser(r.Context(), middleware.GetUserID(r.Context()), uid, updatedUser, ifMatchVersion(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	w.WriteHeader(http.StatusOK)
+}
+
+// PatchUser godoc
+// @Summary Partially update a user
+// @Description Apply a JSON Merge Patch (RFC 7396) to a user's updatable fields; fields missing from the patch are left unchanged. Only the user themselves or an administrator may update a profile. Send the user's ETag in If-Match to avoid overwriting concurrent changes.
+// @Tags user
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param uid path string true "User ID"
+// @Security BearerAuth
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param patch body object true "Merge patch of the user"
+// @Success 200 {object} schemas.UserSchema
+// @Header 200 {string} ETag "Version of the updated user"
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 403 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 409 {object} schemas.ProblemSchema
+// @Failure 412 {object} schemas.ProblemSchema
+// @Failure 415 {object} schemas.ProblemSchema
+// @Failure 422 {object} schemas.ProblemSchema
+// @Router /users/{uid} [patch]
+func PatchUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	uid := vars["uid"]
+
+	patch, err := readMergePatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	user, err := services.PatchUser(r.Context(), middleware.GetUserID(r.Context()), uid, patch, ifMatchVersion(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	view, err := newUserView(r, user)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
+
+// GetUser godoc
+// @Summary Get user by ID
+// @Description Get a single user by their ID. The full profile is only returned to the user themselves and administrators, everyone else gets the public fields.
+// @Tags user
+// @Produce json
+// @Param uid path string true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} schemas.UserSchema
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /users/{uid} [get]
+func GetUser(w http.ResponseWriter, r *http.Request) {
+	uid := mux.Vars(r)["uid"]
+
+	user, err := services.GetUserByID(r.Context(), uid)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	view, err := newUserView(r, *user)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
+
+// GetMe godoc
+// @Summary Get the authenticated user
+// @Description Get the profile of the user who sent the request
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} schemas.UserSchema
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 401 {object} schemas.ProblemSchema
+// @Failure 404 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Router /me [get]
+func GetMe(w http.ResponseWriter, r *http.Request) {
+	uid := middleware.GetUserID(r.Context())
+	if uid == "" {
+		writeError(w, r, services.ErrNotAuthenticated)
+		return
+	}
+
+	user, err := services.GetUserByID(r.Context(), uid)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas.NewUser(*user, true))
+}
+
+// FindUsers godoc
+// @Summary Look up users by email
+// @Description Get the users with the given email address. Only the user themselves and administrators get the full profile, everyone else gets the public fields.
+// @Tags users
+// @Produce json
+// @Param email query string true "User email"
+// @Security BearerAuth
+// @Success 200 {object} schemas.UsersResponseSchema
+// @Failure 400 {object} schemas.ProblemSchema
+// @Failure 500 {object} schemas.ProblemSchema
+// @Failure 429 {object} schemas.ProblemSchema
+// @Router /users [get]
+func FindUsers(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		writeError(w, r, errMissingEmail)
+		return
+	}
+
+	// An unknown email is an empty result rather than a missing resource
+	users := []schemas.UserSchema{}
+	user, err := services.GetUserByEmail(r.Context(), email)
+	if err != nil && !errors.Is(err, services.ErrNotFound) {
+		writeError(w, r, err)
+		return
+	}
+	if user != nil {
+		view, err := newUserView(r, *user)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		users = append(users, view)
+	}
+
+	response := schemas.UsersResponseSchema{Users: users}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetUserByEmail returns a single user by their email address. It only backs the
+// deprecated unversioned GET /user/{email} route, clients should use GET /v1/users?email=
+func GetUserByEmail(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	email := vars["email"]
+
+	user, err := services.GetUserByEmail(r.Context(), email)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	view, err := newUserView(r, *user)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
+
+// newUserView builds the view of a user for the signed-in user, which only
+// includes the full profile if it is their own or they are an administrator
+func newUserView(r *http.Request, user services.User) (schemas.UserSchema, error) {
+	full, err := services.CanViewProfile(r.Context(), middleware.GetUserID(r.Context()), user.ID)
+	if err != nil {
+		return schemas.UserSchema{}, err
+	}
+	return schemas.NewUser(user, full), nil
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"neighborguard/api/v1/handlers"
+	"neighborguard/pkg/middleware"
+	"net/http"
+	"time"
+)
+
+// Prefix is the path under which version 1 of the API is mounted
+const Prefix = "/v1"
+
+// Route is an endpoint of version 1 of the API, relative to its prefix
+type Route struct {
+	Method  string
+	Path    string
+	Handler http.HandlerFunc
+}
+
+// Timeout returns how long a request to the route may take, the default
+// request timeout unless the route needs longer
+func (r Route) Timeout(defaultTimeout time.Duration) time.Duration {
+	return max(defaultTimeout, Timeouts[r.Method+" "+r.Path])
+}
+
+// Timeouts of the routes that may need longer than the default request timeout,
+// by method and path
+var Timeouts = map[string]time.Duration{
+	"GET /users/{uid}/export": time.Minute, // gathers every meeting, check-in and audit entry of the user
+}
+
+// Idempotent routes replay their first response to retries sent with the same
+// Idempotency-Key, by method and path
+var Idempotent = map[string]bool{
+	"POST /users":   true,
+	"POST /meeting": true,
+	"POST /series":  true,
+}
+
+// Routes lists the version 1 endpoints. Collection endpoints come before
+// /users/{uid} so they take precedence.
+var Routes = []Route{
+	// Collection endpoints (plural)
+	{"GET", "/users/recipients", middleware.Chain(handlers.GetNearbyRecipients, middleware.Logging())},
+	{"GET", "/users/recipients/{uid}", middleware.Chain(handlers.GetRecipient, middleware.Logging())},
+	{"GET", "/users", middleware.Chain(handlers.FindUsers, middleware.Logging())},
+	{"POST", "/users", middleware.Chain(handlers.CreateUser, middleware.Logging())},
+
+	// Single user endpoints, addressed by ID
+	{"GET", "/users/{uid}", middleware.Chain(handlers.GetUser, middleware.Logging())},
+	{"PUT", "/users/{uid}", middleware.Chain(handlers.UpdateUser, middleware.Logging())},
+	{"PATCH", "/users/{uid}", middleware.Chain(handlers.PatchUser, middleware.Logging())},
+	{"DELETE", "/users/{uid}", middleware.Chain(handlers.DeleteUser, middleware.Logging())},
+	{"GET", "/users/{uid}/export", middleware.Chain(handlers.ExportUser, middleware.Logging())},
+	{"GET", "/users/{uid}/availability", middleware.Chain(handlers.GetAvailability, middleware.Logging())},
+	{"PUT", "/users/{uid}/availability", middleware.Chain(handlers.SetAvailability, middleware.Logging())},
+	{"POST", "/users/{uid}/calendar", middleware.Chain(handlers.CreateCalendarToken, middleware.Logging())},
+	{"DELETE", "/users/{uid}/calendar", middleware.Chain(handlers.RevokeCalendarToken, middleware.Logging())},
+	{"GET", "/users/{uid}/meetings.ics", middleware.Chain(handlers.GetCalendarFeed, middleware.Logging())},
+	{"GET", "/me", middleware.Chain(handlers.GetMe, middleware.Logging())},
+
+	// Session endpoints
+	{"POST", "/sessions", middleware.Chain(handlers.CreateSession, middleware.Logging())},
+
+	// Meeting endpoints
+	{"POST", "/meeting", middleware.Chain(handlers.CreateMeeting, middleware.Logging())},
+	{"GET", "/meeting/{uid}.ics", middleware.Chain(handlers.GetMeetingCalendar, middleware.Logging())},
+	{"DELETE", "/meeting/{uid}/{userID}", middleware.Chain(handlers.CancelMeeting, middleware.Logging())},
+	{"PUT", "/meeting/{uid}/status", middleware.Chain(handlers.UpdateMeetingStatus, middleware.Logging())},
+	{"PATCH", "/meeting/{uid}", middleware.Chain(handlers.PatchMeeting, middleware.Logging())},
+
+	// Recurring meeting endpoints
+	{"POST", "/series", middleware.Chain(handlers.CreateSeries, middleware.Logging())},
+	{"GET", "/series/{id}", middleware.Chain(handlers.GetSeries, middleware.Logging())},
+	{"PATCH", "/series/{id}", middleware.Chain(handlers.PatchSeries, middleware.Logging())},
+	{"DELETE", "/series/{id}", middleware.Chain(handlers.CancelSeries, middleware.Logging())},
+
+	// Collection endpoint for getting meetings
+	{"GET", "/meetings", middleware.Chain(handlers.GetMeetings, middleware.Logging())},
+
+	// Service catalogue endpoints
+	{"GET", "/services", middleware.Chain(handlers.GetServiceDefinitions, middleware.Logging())},
+	{"POST", "/service", middleware.Chain(handlers.CreateServiceDefinition, middleware.Logging())},
+	{"GET", "/service/{id}", middleware.Chain(handlers.GetServiceDefinition, middleware.Logging())},
+	{"PUT", "/service/{id}", middleware.Chain(handlers.UpdateServiceDefinition, middleware.Logging())},
+	{"DELETE", "/service/{id}", middleware.Chain(handlers.DeleteServiceDefinition, middleware.Logging())},
+
+	// Administration endpoints
+	{"GET", "/admin/log-level", middleware.Chain(handlers.GetLogLevel, middleware.Logging())},
+	{"PUT", "/admin/log-level", middleware.Chain(handlers.SetLogLevel, middleware.Logging())},
+}
@@ -0,0 +1,6 @@
+package schemas
+
+// LogLevelSchema is the level of the server's logs, one of debug, info, warn or error
+type LogLevelSchema struct {
+	Level string `json:"level" example:"info"`
+}
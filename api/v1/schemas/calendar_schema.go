@@ -0,0 +1,8 @@
+package schemas
+
+// CalendarTokenSchema is a new token of a user's calendar feed. It is only shown
+// once, a new token must be created if it is lost.
+type CalendarTokenSchema struct {
+	Token string `json:"token"`
+	Path  string `json:"path"` // path of the feed with the token, to subscribe to
+}
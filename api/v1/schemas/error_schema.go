@@ -0,0 +1,14 @@
+package schemas
+
+import "neighborguard/pkg/validation"
+
+// ProblemSchema is an RFC 7807 problem details body returned for every failure
+type ProblemSchema struct {
+	Type      string                  `json:"type"`
+	Title     string                  `json:"title"`
+	Status    int                     `json:"status"`
+	Detail    string                  `json:"detail"`
+	Code      string                  `json:"code"`
+	RequestID string                  `json:"requestId,omitempty"`
+	Errors    []validation.FieldError `json:"errors,omitempty"` // invalid fields of a rejected payload
+}
@@ -0,0 +1,30 @@
+package schemas
+
+import (
+	"neighborguard/pkg/services"
+	"time"
+)
+
+// UserExportSchema is a copy of all the data kept about a user
+type UserExportSchema struct {
+	ExportedAt   time.Time                `json:"exportedAt"`
+	Profile      UserSchema               `json:"profile"`
+	Meetings     []MeetingResponseSchema  `json:"meetings"`
+	CheckIns     []services.CheckIn       `json:"checkIns"`
+	AuditEntries []services.AuditEntry    `json:"auditEntries"`
+	Availability *services.Availability   `json:"availability,omitempty"`
+	Series       []services.MeetingSeries `json:"series"`
+}
+
+// NewUserExport builds the export of a user, showing their meetings as they see them
+func NewUserExport(export services.UserExport) UserExportSchema {
+	return UserExportSchema{
+		ExportedAt:   export.ExportedAt,
+		Profile:      NewUser(export.Profile, true),
+		Meetings:     NewMeetingResponses(export.Meetings, export.Profile.ID),
+		CheckIns:     export.CheckIns,
+		AuditEntries: export.AuditEntries,
+		Availability: export.Availability,
+		Series:       export.Series,
+	}
+}
@@ -0,0 +1,56 @@
+package schemas
+
+import (
+	"neighborguard/pkg/services"
+	"time"
+)
+
+type SearchMeetingsResponseSchema struct {
+	Meetings []MeetingResponseSchema `json:"meetings"`
+}
+
+// MeetingResponseSchema is a meeting as seen by one of its participants
+type MeetingResponseSchema struct {
+	ID            string                 `json:"uid"`
+	Recipient     RecipientDetailSchema  `json:"recipient"`
+	Volunteer     VolunteerSchema        `json:"volunteer"`
+	Date          time.Time              `json:"date"` // in the recipient's timezone, where the meeting takes place
+	End           time.Time              `json:"end"`
+	Services      []string               `json:"services"`
+	MeetingStatus services.MeetingStatus `json:"meetingStatus"`
+	CreatedAt     time.Time              `json:"createdAt"`
+	UpdatedAt     time.Time              `json:"updatedAt"`
+	Version       int64                  `json:"version"`
+}
+
+// NewMeetingResponse builds the view of a meeting for the user with the given ID.
+// Each participant's contact details are included only if the meeting discloses them to that user.
+func NewMeetingResponse(meeting services.Meeting, viewerID string) MeetingResponseSchema {
+	location := meeting.Recipient.Location()
+	recipient := services.NearbyRecipient{
+		Recipient: meeting.Recipient,
+		Origin:    meeting.Volunteer.LonLat,
+	}
+
+	return MeetingResponseSchema{
+		ID:            meeting.ID,
+		Recipient:     NewRecipientDetail(recipient, meeting.DisclosesContactTo(viewerID)),
+		Volunteer:     NewVolunteer(meeting.Volunteer, meeting.DisclosesVolunteerContactTo(viewerID)),
+		Date:          meeting.Date.In(location),
+		End:           meeting.End.In(location),
+		Services:      meeting.Services,
+		MeetingStatus: meeting.MeetingStatus,
+		CreatedAt:     meeting.CreatedAt,
+		UpdatedAt:     meeting.UpdatedAt,
+		Version:       meeting.Version,
+	}
+}
+
+// NewMeetingResponses builds the views of several meetings for the user with the given ID
+func NewMeetingResponses(meetings []services.Meeting, viewerID string) []MeetingResponseSchema {
+	responses := make([]MeetingResponseSchema, 0, len(meetings))
+	for _, meeting := range meetings {
+		responses = append(responses, NewMeetingResponse(meeting, viewerID))
+	}
+	return responses
+}
@@ -0,0 +1,30 @@
+package schemas
+
+import (
+	"neighborguard/pkg/services"
+	"time"
+)
+
+// SeriesResponseSchema is a meeting series with its upcoming occurrences, as seen
+// by one of its participants
+type SeriesResponseSchema struct {
+	services.MeetingSeries
+	Occurrences []MeetingResponseSchema `json:"occurrences"`
+}
+
+// NewSeriesResponse builds the view of a series for the user with the given ID,
+// with its times in the timezone of the series
+func NewSeriesResponse(series services.MeetingSeries, occurrences []services.Meeting, viewerID string) SeriesResponseSchema {
+	location := series.Location()
+	series.Start = series.Start.In(location)
+	exceptions := make([]time.Time, 0, len(series.Exceptions))
+	for _, exception := range series.Exceptions {
+		exceptions = append(exceptions, exception.In(location))
+	}
+	series.Exceptions = exceptions
+
+	return SeriesResponseSchema{
+		MeetingSeries: series,
+		Occurrences:   NewMeetingResponses(occurrences, viewerID),
+	}
+}
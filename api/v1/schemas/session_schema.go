@@ -0,0 +1,22 @@
+package schemas
+
+import (
+	"neighborguard/pkg/services"
+	"time"
+)
+
+// SessionSchema is a session token, sent as Authorization: Bearer <token> to
+// identify the signed-in user until it expires
+type SessionSchema struct {
+	Token     string    `json:"token"`
+	UserID    string    `json:"userId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func NewSession(session services.Session) SessionSchema {
+	return SessionSchema{
+		Token:     session.Token,
+		UserID:    session.UserID,
+		ExpiresAt: session.ExpiresAt,
+	}
+}
@@ -0,0 +1,172 @@
+package schemas
+
+import (
+	"neighborguard/pkg/services"
+	"time"
+)
+
+type SearchUsersResponseSchema struct {
+	Users []RecipientSummarySchema `json:"users"`
+}
+
+type UsersResponseSchema struct {
+	Users []UserSchema `json:"users"`
+}
+
+// UserSchema is a user as returned by the API. Everyone sees the public fields,
+// the profile fields are only filled in for the user themselves and administrators.
+// The password is never returned.
+type UserSchema struct {
+	PublicUserSchema
+	*UserProfileSchema
+}
+
+// PublicUserSchema is what anyone can see about a user
+type PublicUserSchema struct {
+	ID           string        `json:"uid"`
+	FirstName    string        `json:"firstName"`
+	Role         services.Role `json:"role"`
+	Languages    []string      `json:"languages"`
+	ProfileImage string        `json:"profileImage"`
+	Verified     bool          `json:"verified"`
+}
+
+// UserProfileSchema is the rest of a user's profile
+type UserProfileSchema struct {
+	LastName    string                                      `json:"lastName"`
+	Age         int                                         `json:"age"`
+	PhoneNumber string                                      `json:"phoneNumber"`
+	Gender      services.Gender                             `json:"gender"`
+	Email       string                                      `json:"email"`
+	Address     services.Address                            `json:"address"`
+	Services    map[string]services.MeetingAssistanceStatus `json:"services"`
+	LonLat      services.LonLat                             `json:"lonLat"`
+	Timezone    string                                      `json:"timezone"`
+	LastOK      time.Time                                   `json:"lastOK"`
+	Privacy     services.PrivacySettings                    `json:"privacy"`
+	Version     int64                                       `json:"version"`
+	CreatedAt   time.Time                                   `json:"createdAt"`
+	UpdatedAt   time.Time                                   `json:"updatedAt"`
+	DeletedAt   *time.Time                                  `json:"deletedAt,omitempty"`
+}
+
+// RecipientSummarySchema is what a volunteer sees about a recipient before any meeting exists
+type RecipientSummarySchema struct {
+	ID                  string          `json:"uid"`
+	FirstName           string          `json:"firstName"`
+	ApproximateDistance float64         `json:"approximateDistanceKm"`
+	ApproximateLocation services.LonLat `json:"approximateLocation"`
+	NeededServices      []string        `json:"neededServices"`
+	Languages           []string        `json:"languages"`
+}
+
+// RecipientDetailSchema adds the recipient's contact details, which are only
+// filled in for the volunteer of an active meeting with the recipient
+type RecipientDetailSchema struct {
+	RecipientSummarySchema
+	ContactDisclosed bool              `json:"contactDisclosed"`
+	LastName         string            `json:"lastName,omitempty"`
+	PhoneNumber      string            `json:"phoneNumber,omitempty"`
+	Address          *services.Address `json:"address,omitempty"`
+	LonLat           *services.LonLat  `json:"lonLat,omitempty"`
+}
+
+// VolunteerSchema is what is seen about the volunteer of a meeting. The contact
+// details are only filled in for the recipient of an active meeting.
+type VolunteerSchema struct {
+	ID               string   `json:"uid"`
+	FirstName        string   `json:"firstName"`
+	Languages        []string `json:"languages"`
+	ProfileImage     string   `json:"profileImage"`
+	ContactDisclosed bool     `json:"contactDisclosed"`
+	LastName         string   `json:"lastName,omitempty"`
+	PhoneNumber      string   `json:"phoneNumber,omitempty"`
+}
+
+// NewRecipientSummary builds the search view of a recipient. The distance is measured
+// to the fuzzed location, so distances from several origins cannot be combined to
+// find the exact coordinates of the recipient.
+func NewRecipientSummary(match services.NearbyRecipient) RecipientSummarySchema {
+	recipient := match.Recipient
+	location := services.FuzzLocation(recipient)
+	languages := recipient.Languages
+	if languages == nil {
+		languages = []string{}
+	}
+
+	return RecipientSummarySchema{
+		ID:                  recipient.ID,
+		FirstName:           recipient.FirstName,
+		ApproximateDistance: services.ApproximateDistance(services.Distance(match.Origin, location)),
+		ApproximateLocation: location,
+		NeededServices:      services.NeededServices(recipient),
+		Languages:           languages,
+	}
+}
+
+// NewRecipientDetail builds the view of a recipient, revealing contact details only if disclosed is true
+func NewRecipientDetail(match services.NearbyRecipient, disclosed bool) RecipientDetailSchema {
+	detail := RecipientDetailSchema{RecipientSummarySchema: NewRecipientSummary(match)}
+	if disclosed {
+		recipient := match.Recipient
+		detail.ContactDisclosed = true
+		detail.LastName = recipient.LastName
+		detail.PhoneNumber = recipient.PhoneNumber
+		detail.Address = &recipient.Address
+		detail.LonLat = &recipient.LonLat
+	}
+	return detail
+}
+
+// NewUser builds the view of a user, including the full profile only if full is true
+func NewUser(user services.User, full bool) UserSchema {
+	languages := user.Languages
+	if languages == nil {
+		languages = []string{}
+	}
+
+	view := UserSchema{PublicUserSchema: PublicUserSchema{
+		ID:           user.ID,
+		FirstName:    user.FirstName,
+		Role:         user.Role,
+		Languages:    languages,
+		ProfileImage: user.ProfileImage,
+		Verified:     user.Verified,
+	}}
+	if full {
+		view.UserProfileSchema = &UserProfileSchema{
+			LastName:    user.LastName,
+			Age:         user.Age,
+			PhoneNumber: user.PhoneNumber,
+			Gender:      user.Gender,
+			Email:       user.Email,
+			Address:     user.Address,
+			Services:    user.Services,
+			LonLat:      user.LonLat,
+			Timezone:    user.Timezone,
+			LastOK:      user.LastOK,
+			Privacy:     user.Privacy,
+			Version:     user.Version,
+			CreatedAt:   user.CreatedAt,
+			UpdatedAt:   user.UpdatedAt,
+			DeletedAt:   user.DeletedAt,
+		}
+	}
+	return view
+}
+
+// NewVolunteer builds the view of a volunteer, revealing contact details only if disclosed is true
+func NewVolunteer(volunteer services.User, disclosed bool) VolunteerSchema {
+	view := VolunteerSchema{
+		ID:           volunteer.ID,
+		FirstName:    volunteer.FirstName,
+		Languages:    volunteer.Languages,
+		ProfileImage: volunteer.ProfileImage,
+	}
+	if disclosed {
+		view.ContactDisclosed = true
+		view.LastName = volunteer.LastName
+		view.PhoneNumber = volunteer.PhoneNumber
+	}
+	return view
+}
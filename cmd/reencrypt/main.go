@@ -0,0 +1,56 @@
+// Command reencrypt encrypts the sensitive fields of every user with the
+// active key of the configured keyring. It accepts the same configuration as
+// the server. Run it once after enabling encryption and after every key rotation.
+package main
+
+import (
+	"context"
+	"log/slog"
+	"neighborguard/pkg/config"
+	"neighborguard/pkg/database"
+	"neighborguard/pkg/fieldcrypt"
+	"neighborguard/pkg/logging"
+	"neighborguard/pkg/services"
+	"os"
+)
+
+func main() {
+	// Load the same configuration as the server
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fatal("Failed to load the configuration", err)
+	}
+	level, _ := logging.ParseLevel(cfg.Logging.Level)
+	logging.Setup(cfg.Logging.Format, level, nil)
+
+	if cfg.Encryption.KeyringFile == "" {
+		fatal("A keyring file must be configured to encrypt with", nil)
+	}
+
+	keyring, err := fieldcrypt.LoadKeyring(cfg.Encryption.KeyringFile)
+	if err != nil {
+		fatal("Failed to load the keyring", err)
+	}
+	services.Keyring = keyring
+
+	// Connect to MongoDB
+	if err := database.Connect(cfg.Mongo); err != nil {
+		fatal("Failed to connect to MongoDB", err)
+	}
+
+	count, err := services.ReencryptUsers(context.Background())
+	database.Disconnect()
+	if err != nil {
+		fatal("Re-encryption stopped", err, "users", count)
+	}
+	slog.Info("Re-encrypted users", "users", count, "keyId", keyring.ActiveKeyID())
+}
+
+// fatal logs an error that stops the re-encryption and exits
+func fatal(msg string, err error, args ...any) {
+	if err != nil {
+		args = append(args, "error", err)
+	}
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -15,61 +15,44 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/meeting": {
-            "post": {
-                "description": "Create a new meeting between a volunteer and a recipient",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/log-level": {
+            "get": {
+                "description": "Get the current level of the server's logs. Only administrators can use this endpoint.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "meeting"
+                    "admin"
                 ],
-                "summary": "Create a new meeting",
-                "parameters": [
+                "summary": "Get the log level",
+                "security": [
                     {
-                        "description": "Meeting to create",
-                        "name": "meeting",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/services.NewMeeting"
-                        }
+                        "BearerAuth": []
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.Meeting"
+                            "$ref": "#/definitions/schemas.LogLevelSchema"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     }
                 }
-            }
-        },
-        "/meeting/{uid}/status": {
+            },
             "put": {
-                "description": "Update the status of an existing meeting",
+                "description": "Change the level of the server's logs while it runs, such as to debug an issue. The level is reset to the configured one on restart. Only administrators can use this endpoint.",
                 "consumes": [
                     "application/json"
                 ],
@@ -77,24 +60,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "meeting"
+                    "admin"
                 ],
-                "summary": "Update meeting status",
-                "parameters": [
+                "summary": "Change the log level",
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Meeting ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
                     {
-                        "description": "New meeting status (IS_PICKED or DONE)",
-                        "name": "status",
+                        "description": "New log level",
+                        "name": "level",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/schemas.LogLevelSchema"
                         }
                     }
                 ],
@@ -102,175 +83,215 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.Meeting"
+                            "$ref": "#/definitions/schemas.LogLevelSchema"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     }
                 }
             }
         },
-        "/meeting/{uid}/{userID}": {
-            "delete": {
-                "description": "Cancel a meeting by its ID and the user cancelling",
+        "/me": {
+            "get": {
+                "description": "Get the profile of the user who sent the request",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "meeting"
+                    "user"
                 ],
-                "summary": "Cancel an existing meeting",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Meeting ID to cancel",
-                        "name": "uid",
-                        "in": "path",
-                        "required": true
-                    },
+                "summary": "Get the authenticated user",
+                "security": [
                     {
-                        "type": "string",
-                        "description": "ID of the user cancelling the meeting",
-                        "name": "userID",
-                        "in": "path",
-                        "required": true
+                        "BearerAuth": []
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "$ref": "#/definitions/schemas.UserSchema"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the user"
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     }
                 }
             }
         },
-        "/meetings": {
-            "get": {
-                "description": "Get meetings filtered by user ID (recipient or volunteer) and meeting status",
+        "/meeting": {
+            "post": {
+                "description": "Create a new meeting between a volunteer and a recipient. Only the volunteer of the meeting may create it.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "meetings"
+                    "meeting"
+                ],
+                "summary": "Create a new meeting",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "summary": "Get meetings based on filters",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID to filter meetings (can be recipient or volunteer)",
-                        "name": "userId",
-                        "in": "query"
+                        "description": "Meeting to create",
+                        "name": "meeting",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.NewMeeting"
+                        }
                     },
                     {
                         "type": "string",
-                        "description": "Meeting status to filter (IS_PICKED or DONE)",
-                        "name": "status",
-                        "in": "query"
+                        "description": "Key that makes retries replay the first response instead of repeating the request",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/services.Meeting"
+                            "$ref": "#/definitions/schemas.MeetingResponseSchema"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the created meeting"
+                            },
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the response is replayed for a retry"
                             }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     }
                 }
             }
         },
-        "/user": {
-            "post": {
-                "description": "Create a new user",
+        "/meeting/{uid}": {
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to the date and status of a meeting. Only the participants or an administrator may update a meeting. Send the meeting's ETag in If-Match to avoid overwriting concurrent changes.",
                 "consumes": [
-                    "application/json"
+                    "application/merge-patch+json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "meeting"
+                ],
+                "summary": "Partially update a meeting",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "summary": "Create a new user",
                 "parameters": [
                     {
-                        "description": "User object that needs to be created",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Meeting ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Merge patch of the meeting",
+                        "name": "patch",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/services.NewUser"
+                            "type": "object"
                         }
                     }
                 ],
@@ -278,210 +299,2522 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.User"
+                            "$ref": "#/definitions/schemas.MeetingResponseSchema"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated meeting"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     }
                 }
             }
         },
-        "/user/{email}": {
+        "/meeting/{uid}.ics": {
             "get": {
-                "description": "Get a single user by their email address",
+                "description": "Get a single meeting as an iCalendar (RFC 5545) file to add to a calendar. Only the participants or an administrator may download it.",
                 "produces": [
-                    "application/json"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "user"
+                    "meeting"
+                ],
+                "summary": "Download a meeting as iCalendar",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "summary": "Get user by email",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User email",
-                        "name": "email",
+                        "description": "Meeting ID",
+                        "name": "uid",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "iCalendar file",
                         "schema": {
-                            "$ref": "#/definitions/services.User"
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     }
                 }
             }
         },
-        "/users/recipients": {
-            "get": {
-                "description": "Get recipients who need assistance matching volunteer's languages and services",
+        "/meeting/{uid}/status": {
+            "put": {
+                "description": "Update the status of an existing meeting. Only the participants or an administrator may update a meeting.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "meeting"
+                ],
+                "summary": "Update meeting status",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "summary": "Get nearby recipients needing assistance",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Volunteer's UID",
-                        "name": "volunteerUID",
-                        "in": "query",
+                        "description": "Meeting ID",
+                        "name": "uid",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "number",
-                        "description": "Filter by latitude",
-                        "name": "filterByLat",
-                        "in": "query"
+                        "type": "string",
+                        "description": "New meeting status (IS_PICKED or DONE)",
+                        "name": "status",
+                        "in": "query",
+                        "required": true
                     },
                     {
-                        "type": "number",
-                        "description": "Filter by longitude",
-                        "name": "filterByLon",
-                        "in": "query"
+                        "type": "string",
+                        "description": "ETag of the version being updated",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/schemas.SearchUsersResponseSchema"
+                            "$ref": "#/definitions/schemas.MeetingResponseSchema"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated meeting"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/schemas.ProblemSchema"
                         }
                     }
                 }
             }
         },
-        "/users/{uid}": {
-            "put": {
-                "description": "Update an existing user's information",
-                "consumes": [
-                    "application/json"
-                ],
+        "/meeting/{uid}/{userID}": {
+            "delete": {
+                "description": "Cancel a meeting by its ID as the signed-in user. Only the participants or an administrator may cancel a meeting.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "meeting"
+                ],
+                "summary": "Cancel an existing meeting",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "summary": "Update an existing user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Meeting ID to cancel",
                         "name": "uid",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated user information",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Kept for existing clients, the signed-in user is the one cancelling",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/meetings": {
+            "get": {
+                "description": "Get the meetings of the signed-in user, as recipient or volunteer, filtered by meeting status. Administrators may list the meetings of any user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meetings"
+                ],
+                "summary": "Get meetings based on filters",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user whose meetings to list, the signed-in user by default",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Meeting status to filter (IS_PICKED, DONE or CANCELLED)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.SearchMeetingsResponseSchema"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/series": {
+            "post": {
+                "description": "Claim services of a recipient for a volunteer and meet following a recurrence rule, a subset of RFC 5545 RRULE: FREQ of DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY for weekly rules, and COUNT or UNTIL. Occurrences are created as meetings up to the series horizon ahead and every one of them must fit the volunteer's schedule. The services stay claimed by the volunteer between occurrences. Only the volunteer of the series may create it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Create a recurring meeting series",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "description": "Series to create",
+                        "name": "series",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/services.User"
+                            "$ref": "#/definitions/services.NewSeries"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries replay the first response instead of repeating the request",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.SeriesResponseSchema"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the created series"
+                            },
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the response is replayed for a retry"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}": {
+            "get": {
+                "description": "Get a meeting series and its upcoming occurrences. Only the participants or an administrator may read a series.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Get a meeting series",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.SeriesResponseSchema"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the series"
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stop a series and cancel its upcoming occurrences. The recipient's services are offered to other volunteers again, whoever cancels. Only the participants or an administrator may cancel a series.",
+                "tags": [
+                    "series"
+                ],
+                "summary": "Cancel a meeting series",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to the start and rule of a series. Upcoming occurrences are replaced by those of the new rule, including occurrences cancelled or rescheduled on their own. To change a single occurrence, patch or cancel its meeting instead. Only the participants or an administrator may change a series.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Change a whole meeting series",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Merge patch of the series",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.SeriesResponseSchema"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated series"
                             }
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/service": {
+            "post": {
+                "description": "Add a service that users and meetings can reference by its ID. Its display names must not name another service. Only administrators can change the catalogue.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service"
+                ],
+                "summary": "Add a service to the catalogue",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "description": "Service to create",
+                        "name": "service",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.NewServiceDefinition"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.ServiceDefinition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/service/{id}": {
+            "get": {
+                "description": "Get a single service of the catalogue by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service"
+                ],
+                "summary": "Get a catalogue service",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.ServiceDefinition"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the display names and settings of a service. Its ID cannot change. Only administrators can change the catalogue.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service"
+                ],
+                "summary": "Update a catalogue service",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated service",
+                        "name": "service",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.NewServiceDefinition"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.ServiceDefinition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a service that no user or meeting references. Only administrators can change the catalogue.",
+                "tags": [
+                    "service"
+                ],
+                "summary": "Remove a service from the catalogue",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/services": {
+            "get": {
+                "description": "Get every service that users and meetings can reference",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Get the service catalogue",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.ServiceDefinition"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "post": {
+                "description": "Exchange the email and password of a user for a session token. Send the token as \"Authorization: Bearer <token>\" to identify the user on the other endpoints until it expires, then sign in again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Sign in",
+                "parameters": [
+                    {
+                        "description": "Email and password of the user",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.SessionSchema"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Get the users with the given email address. Only the user themselves and administrators get the full profile, everyone else gets the public fields.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Look up users by email",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User email",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.UsersResponseSchema"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new user. The response is the full profile of the new user, without the password.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "User object that needs to be created",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.NewUser"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries replay the first response instead of repeating the request",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.UserSchema"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the created user"
+                            },
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the response is replayed for a retry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/recipients": {
+            "get": {
+                "description": "Get recipients who need assistance matching the signed-in volunteer's languages and services",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get nearby recipients needing assistance",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Filter by latitude",
+                        "name": "filterByLat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Filter by longitude",
+                        "name": "filterByLon",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.SearchUsersResponseSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/recipients/{uid}": {
+            "get": {
+                "description": "Get a recipient's summary. The full address and phone number are included only while the signed-in volunteer has an active meeting with the recipient.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a recipient as seen by a volunteer",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipient's UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.RecipientDetailSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{uid}": {
+            "get": {
+                "description": "Get a single user by their ID. The full profile is only returned to the user themselves and administrators, everyone else gets the public fields.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get user by ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.UserSchema"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the user"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace an existing user's updatable information. Only the user themselves or an administrator may update a profile. Send the user's ETag in If-Match to avoid overwriting concurrent changes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update an existing user",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Updated user information",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated user"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancel the user's active meetings and anonymize their personal data. Completed meetings are kept for statistics. Only the user themselves or an administrator may delete an account.",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete a user",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to a user's updatable fields; fields missing from the patch are left unchanged. Only the user themselves or an administrator may update a profile. Send the user's ETag in If-Match to avoid overwriting concurrent changes.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Partially update a user",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Merge patch of the user",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.UserSchema"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated user"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{uid}/availability": {
+            "get": {
+                "description": "Get the weekly windows and exceptions a volunteer published, and the periods between from and to in which they can be booked: covered by their schedule and not taken by one of their meetings. Volunteers without a schedule are free whenever they have no meeting. Windows are wall clock times in the timezone of the schedule, so they follow daylight saving time, and times are RFC 3339 in that timezone.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get the free slots of a volunteer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Volunteer ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range as an RFC 3339 time, now by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range as an RFC 3339 time, at most 31 days after from; 7 days after from by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.FreeSlots"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the weekly windows in which a volunteer is available, as wall clock times in the timezone of the schedule (the volunteer's unless given), and the exceptions that make them unavailable or available in addition between two times. Once published, meetings can only be created in the schedule. Only the volunteer themselves or an administrator may change it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Publish the schedule of a volunteer",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Volunteer ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule of the volunteer",
+                        "name": "availability",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.NewAvailability"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Availability"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{uid}/calendar": {
+            "post": {
+                "description": "Create the token of the user's calendar feed, which calendar clients can subscribe to without signing in. A new token revokes the previous one. Only the user themselves or an administrator may create or revoke the subscription.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Create a calendar subscription",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.CalendarTokenSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Revoke the token of the user's calendar feed, so clients subscribed to it no longer get meetings. Only the user themselves or an administrator may create or revoke the subscription.",
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Revoke a calendar subscription",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{uid}/export": {
+            "get": {
+                "description": "Get a copy of the user's profile, meetings, check-ins, schedule and the audit entries about them, as JSON or as a ZIP archive of JSON files. Only the user themselves or an administrator may export an account.",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Export a user's data",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or zip",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.UserExportSchema"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{uid}/meetings.ics": {
+            "get": {
+                "description": "Get the user's meetings from the last 90 days on as an iCalendar (RFC 5545) feed. Events name the services and show the recipient's city and approximate location; cancelled meetings stay in the feed as cancelled events.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Get a user's calendar feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of the calendar subscription",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ProblemSchema"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "schemas.CalendarTokenSchema": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "description": "path of the feed with the token, to subscribe to",
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "schemas.LogLevelSchema": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "example": "info"
+                }
+            }
+        },
+        "schemas.MeetingResponseSchema": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "date": {
+                    "description": "in the recipient's timezone, where the meeting takes place",
+                    "type": "string"
+                },
+                "end": {
+                    "type": "string"
+                },
+                "meetingStatus": {
+                    "$ref": "#/definitions/services.MeetingStatus"
+                },
+                "recipient": {
+                    "$ref": "#/definitions/schemas.RecipientDetailSchema"
+                },
+                "services": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "volunteer": {
+                    "$ref": "#/definitions/schemas.VolunteerSchema"
+                }
+            }
+        },
+        "schemas.ProblemSchema": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "invalid fields of a rejected payload",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validation.FieldError"
+                    }
+                },
+                "requestId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "schemas.RecipientDetailSchema": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/services.Address"
+                },
+                "approximateDistanceKm": {
+                    "type": "number"
+                },
+                "approximateLocation": {
+                    "$ref": "#/definitions/services.LonLat"
+                },
+                "contactDisclosed": {
+                    "type": "boolean"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "languages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "lonLat": {
+                    "$ref": "#/definitions/services.LonLat"
+                },
+                "neededServices": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "phoneNumber": {
+                    "type": "string"
+                },
+                "uid": {
+                    "type": "string"
+                }
+            }
+        },
+        "schemas.RecipientSummarySchema": {
+            "type": "object",
+            "properties": {
+                "approximateDistanceKm": {
+                    "type": "number"
+                },
+                "approximateLocation": {
+                    "$ref": "#/definitions/services.LonLat"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "languages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "neededServices": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "uid": {
+                    "type": "string"
+                }
+            }
+        },
+        "schemas.SearchMeetingsResponseSchema": {
+            "type": "object",
+            "properties": {
+                "meetings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schemas.MeetingResponseSchema"
+                    }
+                }
+            }
+        },
+        "schemas.SearchUsersResponseSchema": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schemas.RecipientSummarySchema"
+                    }
+                }
+            }
+        },
+        "schemas.SeriesResponseSchema": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "of each occurrence, in seconds",
+                    "type": "integer"
+                },
+                "exceptions": {
+                    "description": "occurrences cancelled on their own",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "occurrences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schemas.MeetingResponseSchema"
+                    }
+                },
+                "recipientId": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "services": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "start": {
+                    "description": "first occurrence",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/services.SeriesStatus"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "incremented on every update",
+                    "type": "integer"
+                },
+                "volunteerId": {
+                    "type": "string"
+                }
+            }
+        },
+        "schemas.SessionSchema": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "schemas.UserExportSchema": {
+            "type": "object",
+            "properties": {
+                "auditEntries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AuditEntry"
+                    }
+                },
+                "availability": {
+                    "$ref": "#/definitions/services.Availability"
+                },
+                "checkIns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.CheckIn"
+                    }
+                },
+                "exportedAt": {
+                    "type": "string"
+                },
+                "meetings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schemas.MeetingResponseSchema"
+                    }
+                },
+                "profile": {
+                    "$ref": "#/definitions/schemas.UserSchema"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.MeetingSeries"
+                    }
+                }
+            }
+        },
+        "schemas.UserSchema": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/services.Address"
+                },
+                "age": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "gender": {
+                    "$ref": "#/definitions/services.Gender"
+                },
+                "languages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "lastOK": {
+                    "type": "string"
+                },
+                "lonLat": {
+                    "$ref": "#/definitions/services.LonLat"
+                },
+                "phoneNumber": {
+                    "type": "string"
+                },
+                "privacy": {
+                    "$ref": "#/definitions/services.PrivacySettings"
+                },
+                "profileImage": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/services.Role"
+                },
+                "services": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/services.MeetingAssistanceStatus"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schemas.UsersResponseSchema": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schemas.UserSchema"
+                    }
+                }
+            }
+        },
+        "schemas.VolunteerSchema": {
+            "type": "object",
+            "properties": {
+                "contactDisclosed": {
+                    "type": "boolean"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "languages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "phoneNumber": {
+                    "type": "string"
+                },
+                "profileImage": {
+                    "type": "string"
+                },
+                "uid": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Address": {
+            "type": "object",
+            "properties": {
+                "apartmentNumber": {
+                    "type": "integer"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "houseNumber": {
+                    "type": "integer"
+                },
+                "street": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.AuditAction": {
+            "type": "string",
+            "enum": [
+                "USER_EXPORTED",
+                "USER_DELETED",
+                "CALENDAR_TOKEN_CREATED",
+                "CALENDAR_TOKEN_REVOKED"
+            ],
+            "x-enum-varnames": [
+                "AuditUserExported",
+                "AuditUserDeleted",
+                "AuditCalendarTokenCreated",
+                "AuditCalendarTokenRevoked"
+            ]
+        },
+        "services.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/services.AuditAction"
+                },
+                "actorId": {
+                    "description": "user who took the action, the subject or an administrator",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "subjectId": {
+                    "description": "user the action was about",
+                    "type": "string"
+                },
+                "uid": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Availability": {
+            "type": "object",
+            "properties": {
+                "exceptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AvailabilityException"
+                    }
+                },
+                "timezone": {
+                    "description": "of the weekly windows, which follow its daylight saving time",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "weekly": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AvailabilityWindow"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "schemas.SearchUsersResponseSchema": {
+        },
+        "services.AvailabilityException": {
             "type": "object",
             "properties": {
-                "users": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/services.User"
-                    }
+                "available": {
+                    "type": "boolean"
+                },
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
                 }
             }
         },
-        "services.Address": {
+        "services.AvailabilityWindow": {
             "type": "object",
             "properties": {
-                "apartmentNumber": {
-                    "type": "integer"
+                "day": {
+                    "description": "MONDAY to SUNDAY",
+                    "type": "string"
                 },
-                "city": {
+                "end": {
+                    "description": "time of day after Start, up to 24:00",
                     "type": "string"
                 },
-                "houseNumber": {
-                    "type": "integer"
+                "start": {
+                    "description": "time of day, such as 09:00",
+                    "type": "string"
+                }
+            }
+        },
+        "services.CheckIn": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Credentials": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
                 },
-                "street": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.FreeSlots": {
+            "type": "object",
+            "properties": {
+                "exceptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AvailabilityException"
+                    }
+                },
+                "from": {
                     "type": "string"
+                },
+                "slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.TimeSlot"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "weekly": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AvailabilityWindow"
+                    }
                 }
             }
         },
@@ -496,6 +2829,19 @@ const docTemplate = `{
                 "Female"
             ]
         },
+        "services.LocationPrecision": {
+            "type": "string",
+            "enum": [
+                "EXACT",
+                "STREET",
+                "NEIGHBOURHOOD"
+            ],
+            "x-enum-varnames": [
+                "ExactPrecision",
+                "StreetPrecision",
+                "NeighbourhoodPrecision"
+            ]
+        },
         "services.LonLat": {
             "type": "object",
             "properties": {
@@ -507,25 +2853,48 @@ const docTemplate = `{
                 }
             }
         },
-        "services.Meeting": {
+        "services.MeetingAssistanceStatus": {
+            "type": "string",
+            "enum": [
+                "DO_NOT_NEED_ASSISTANCE",
+                "NEED_ASSISTANCE",
+                "IN_PROGRESS",
+                "PROVIDE",
+                "DO_NOT_PROVIDE"
+            ],
+            "x-enum-varnames": [
+                "DoNotNeedAssistance",
+                "NeedAssistance",
+                "InProgress",
+                "Provide",
+                "DoNotProvide"
+            ]
+        },
+        "services.MeetingSeries": {
             "type": "object",
             "properties": {
                 "createdAt": {
                     "type": "string"
                 },
-                "date": {
+                "duration": {
+                    "description": "of each occurrence, in seconds",
                     "type": "integer"
                 },
-                "meetingStatus": {
-                    "$ref": "#/definitions/services.MeetingStatus"
+                "exceptions": {
+                    "description": "occurrences cancelled on their own",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "recipient": {
-                    "description": "Not stored directly in MongoDB",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/services.User"
-                        }
-                    ]
+                "id": {
+                    "type": "string"
+                },
+                "recipientId": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
                 },
                 "services": {
                     "type": "array",
@@ -533,55 +2902,71 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
-                "uid": {
+                "start": {
+                    "description": "first occurrence",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/services.SeriesStatus"
+                },
+                "timezone": {
                     "type": "string"
                 },
                 "updatedAt": {
                     "type": "string"
                 },
-                "volunteer": {
-                    "description": "Not stored directly in MongoDB",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/services.User"
-                        }
-                    ]
+                "version": {
+                    "description": "incremented on every update",
+                    "type": "integer"
+                },
+                "volunteerId": {
+                    "type": "string"
                 }
             }
         },
-        "services.MeetingAssistanceStatus": {
-            "type": "string",
-            "enum": [
-                "DO_NOT_NEED_ASSISTANCE",
-                "NEED_ASSISTANCE",
-                "IN_PROGRESS",
-                "PROVIDE",
-                "DO_NOT_PROVIDE"
-            ],
-            "x-enum-varnames": [
-                "DoNotNeedAssistance",
-                "NeedAssistance",
-                "InProgress",
-                "Provide",
-                "DoNotProvide"
-            ]
-        },
         "services.MeetingStatus": {
             "type": "string",
             "enum": [
                 "IS_PICKED",
-                "DONE"
+                "DONE",
+                "CANCELLED"
             ],
+            "x-enum-comments": {
+                "Cancelled": "kept so calendars and participants learn about it"
+            },
             "x-enum-varnames": [
                 "IsPicked",
-                "Done"
+                "Done",
+                "Cancelled"
             ]
         },
+        "services.NewAvailability": {
+            "type": "object",
+            "properties": {
+                "exceptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AvailabilityException"
+                    }
+                },
+                "timezone": {
+                    "description": "IANA timezone of the weekly windows, the volunteer's if empty",
+                    "type": "string"
+                },
+                "weekly": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AvailabilityWindow"
+                    }
+                }
+            }
+        },
         "services.NewMeeting": {
             "type": "object",
             "properties": {
                 "date": {
-                    "type": "integer"
+                    "description": "start; the end follows from the duration of the services",
+                    "type": "string"
                 },
                 "meetingStatus": {
                     "$ref": "#/definitions/services.MeetingStatus"
@@ -590,17 +2975,69 @@ const docTemplate = `{
                     "$ref": "#/definitions/services.User"
                 },
                 "services": {
-                    "description": "list of services that will be provided on this meeting",
+                    "description": "list of service catalogue IDs that will be provided on this meeting",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "volunteer": {
+                    "$ref": "#/definitions/services.User"
+                }
+            }
+        },
+        "services.NewSeries": {
+            "type": "object",
+            "properties": {
+                "recipient": {
+                    "$ref": "#/definitions/services.User"
+                },
+                "rule": {
+                    "description": "RRULE such as FREQ=WEEKLY;BYDAY=TU",
+                    "type": "string"
+                },
+                "services": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
+                "start": {
+                    "description": "first occurrence",
+                    "type": "string"
+                },
                 "volunteer": {
                     "$ref": "#/definitions/services.User"
                 }
             }
         },
+        "services.NewServiceDefinition": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "defaultDurationMinutes": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "names": {
+                    "description": "map[LanguageCode]DisplayName",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "recurring": {
+                    "type": "boolean"
+                },
+                "requiresVerifiedVolunteer": {
+                    "type": "boolean"
+                }
+            }
+        },
         "services.NewUser": {
             "type": "object",
             "properties": {
@@ -629,7 +3066,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "lastOK": {
-                    "type": "integer"
+                    "type": "string"
                 },
                 "lonLat": {
                     "$ref": "#/definitions/services.LonLat"
@@ -640,6 +3077,9 @@ const docTemplate = `{
                 "phoneNumber": {
                     "type": "string"
                 },
+                "privacy": {
+                    "$ref": "#/definitions/services.PrivacySettings"
+                },
                 "profileImage": {
                     "type": "string"
                 },
@@ -647,11 +3087,32 @@ const docTemplate = `{
                     "$ref": "#/definitions/services.Role"
                 },
                 "services": {
-                    "description": "map[ServiceName]MeetingAssistanceStatus",
+                    "description": "map[ServiceCatalogueID]MeetingAssistanceStatus",
                     "type": "object",
                     "additionalProperties": {
                         "$ref": "#/definitions/services.MeetingAssistanceStatus"
                     }
+                },
+                "timezone": {
+                    "description": "IANA timezone such as Europe/Paris, DefaultTimezone if empty",
+                    "type": "string"
+                }
+            }
+        },
+        "services.PrivacySettings": {
+            "type": "object",
+            "properties": {
+                "hideFromSearch": {
+                    "description": "opt out of proximity search",
+                    "type": "boolean"
+                },
+                "locationPrecision": {
+                    "description": "defaults to NEIGHBOURHOOD",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.LocationPrecision"
+                        }
+                    ]
                 }
             }
         },
@@ -659,13 +3120,77 @@ const docTemplate = `{
             "type": "string",
             "enum": [
                 "VOLUNTEER",
-                "RECIPIENT"
+                "RECIPIENT",
+                "ADMIN"
             ],
+            "x-enum-comments": {
+                "Admin": "granted in the database only, never through the API"
+            },
             "x-enum-varnames": [
                 "Volunteer",
-                "Recipient"
+                "Recipient",
+                "Admin"
+            ]
+        },
+        "services.SeriesStatus": {
+            "type": "string",
+            "enum": [
+                "ACTIVE",
+                "ENDED",
+                "CANCELLED"
+            ],
+            "x-enum-comments": {
+                "SeriesEnded": "every occurrence was created"
+            },
+            "x-enum-varnames": [
+                "SeriesActive",
+                "SeriesEnded",
+                "SeriesCancelled"
             ]
         },
+        "services.ServiceDefinition": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "defaultDurationMinutes": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "names": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "recurring": {
+                    "type": "boolean"
+                },
+                "requiresVerifiedVolunteer": {
+                    "type": "boolean"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.TimeSlot": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
         "services.User": {
             "type": "object",
             "properties": {
@@ -678,6 +3203,10 @@ const docTemplate = `{
                 "createdAt": {
                     "type": "string"
                 },
+                "deletedAt": {
+                    "description": "set once the account is deleted and anonymized",
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
@@ -697,7 +3226,8 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "lastOK": {
-                    "type": "integer"
+                    "description": "last check-in",
+                    "type": "string"
                 },
                 "lonLat": {
                     "$ref": "#/definitions/services.LonLat"
@@ -708,6 +3238,9 @@ const docTemplate = `{
                 "phoneNumber": {
                     "type": "string"
                 },
+                "privacy": {
+                    "$ref": "#/definitions/services.PrivacySettings"
+                },
                 "profileImage": {
                     "type": "string"
                 },
@@ -720,14 +3253,45 @@ const docTemplate = `{
                         "$ref": "#/definitions/services.MeetingAssistanceStatus"
                     }
                 },
+                "timezone": {
+                    "description": "IANA timezone that schedules and check-ins follow",
+                    "type": "string"
+                },
                 "uid": {
                     "type": "string"
                 },
                 "updatedAt": {
                     "type": "string"
+                },
+                "verified": {
+                    "description": "set by administrators only",
+                    "type": "boolean"
+                },
+                "version": {
+                    "description": "incremented on every update",
+                    "type": "integer"
+                }
+            }
+        },
+        "validation.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Session token from POST /sessions, sent as \"Bearer <token>\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -735,7 +3299,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "",
-	BasePath:         "/",
+	BasePath:         "/v1",
 	Schemes:          []string{},
 	Title:            "NeighborGuard API",
 	Description:      "This is the NeighborGuard API documentation.",
@@ -1,67 +1,293 @@
 package main
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
 	"neighborguard/api"
+	v1 "neighborguard/api/v1"
+	"neighborguard/api/v1/handlers"
+	"neighborguard/pkg/config"
 	"neighborguard/pkg/database"
+	"neighborguard/pkg/fieldcrypt"
+	"neighborguard/pkg/health"
+	"neighborguard/pkg/idempotency"
+	"neighborguard/pkg/lifecycle"
+	"neighborguard/pkg/logging"
 	"neighborguard/pkg/middleware"
+	"neighborguard/pkg/ratelimit"
+	"neighborguard/pkg/services"
+	"neighborguard/pkg/session"
+	"neighborguard/pkg/tlscert"
+	"neighborguard/pkg/tracing"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"time"
+	_ "time/tzdata" // Resolve IANA timezones even where the system has no timezone database
 
 	_ "neighborguard/docs" // Import generated docs
 
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // @title NeighborGuard API
 // @version 1.0
 // @description This is the NeighborGuard API documentation.
-// @BasePath /
+// @BasePath /v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Session token from POST /sessions, sent as "Bearer <token>"
 func main() {
-	const PORT string = "8080"
-	
+	// Load the configuration from the file, environment and flags
+	config.RouteTimeouts = v1.Timeouts
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fatal("Failed to load the configuration", err)
+	}
+
+	// Log structured records, tagged with the request they belong to and redacted of personal data
+	level, _ := logging.ParseLevel(cfg.Logging.Level)
+	logging.Setup(cfg.Logging.Format, level, requestAttrs)
+	slog.Info("Starting", "environment", cfg.Environment, "configuration", cfg.Redacted())
+
+	// Apply the settings of the service layer
+	services.DatabaseTimeout = cfg.Mongo.Timeout
+	services.SearchRadiusKm = cfg.Matching.SearchRadiusKm
+	services.CheckInThreshold = cfg.Matching.CheckInThreshold
+	services.DefaultTimezone = cfg.Users.DefaultTimezone
+	services.LocationFuzzing = services.FuzzingMethod(cfg.Privacy.LocationFuzzing)
+	services.LocationSecret = []byte(cfg.Privacy.LocationSecret)
+
+	// Sign session tokens, with a key of the moment in development if none is configured
+	sessionSecret := []byte(cfg.Auth.SessionSecret)
+	if len(sessionSecret) == 0 {
+		sessionSecret = make([]byte, 32)
+		if _, err := rand.Read(sessionSecret); err != nil {
+			fatal("Failed to generate the session key", err)
+		}
+		slog.Warn("No session secret is configured, users are signed out when the server restarts")
+	}
+	services.Sessions = session.NewSigner(sessionSecret, cfg.Auth.SessionTTL)
+
+	// Load the keyring that encrypts sensitive user fields at rest
+	if cfg.Encryption.KeyringFile != "" {
+		keyring, err := fieldcrypt.LoadKeyring(cfg.Encryption.KeyringFile)
+		if err != nil {
+			fatal("Failed to load the keyring", err)
+		}
+		services.Keyring = keyring
+	} else {
+		slog.Warn("No keyring file is configured, sensitive user fields are stored in clear text")
+	}
+
+	// Create a server, with a manager draining it on shutdown. The timeouts keep slow
+	// or idle clients from holding connections open.
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	manager := lifecycle.New(server, cfg.Server.ShutdownTimeout, cfg.Server.ShutdownDelay)
+
+	// Serve HTTPS when a certificate is configured, reloading it on SIGHUP once renewed
+	if cfg.Server.TLSCertFile != "" {
+		certificate, err := tlscert.NewReloader(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		if err != nil {
+			fatal("Failed to load the TLS certificate", err)
+		}
+		server.TLSConfig = certificate.TLSConfig()
+		manager.Go("tls-reload", certificate.ReloadOnSignal)
+	}
+
+	// Trace requests through the services down to MongoDB. Spans are flushed last on
+	// shutdown, once the requests and workers that record them are done.
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	manager.OnShutdown("tracing", shutdownTracing)
+
 	// Connect to MongoDB
-	if err := database.Connect(); err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	if err := database.Connect(cfg.Mongo); err != nil {
+		fatal("Failed to connect to MongoDB", err)
+	}
+	// Close the MongoDB connection only once requests and workers are done with it
+	manager.OnShutdown("MongoDB", func(ctx context.Context) error {
+		database.Disconnect()
+		return nil
+	})
+
+	// Liveness only fails when the process itself is stuck, such as a worker that stopped
+	// beating; readiness also fails while a critical dependency is down or during shutdown
+	liveness := health.NewRegistry()
+	readiness := health.NewRegistry()
+	readiness.Register(health.Checker{Name: "shutdown", Critical: true, Check: func(ctx context.Context) error {
+		if manager.ShuttingDown() {
+			return errors.New("shutting down")
+		}
+		return nil
+	}})
+	readiness.Register(health.Checker{Name: "mongodb", Critical: true, Timeout: cfg.Health.CheckTimeout, Check: database.Ping})
+	readiness.Register(health.Checker{Name: "config", Critical: true, Check: func(ctx context.Context) error {
+		return cfg.Validate()
+	}})
+
+	// Convert times stored as Unix seconds, which the other migrations read as dates
+	if err := services.EnsureTimestamps(context.Background()); err != nil {
+		fatal("Failed to migrate stored times", err)
+	}
+
+	// Seed the service catalogue and migrate legacy service names to catalogue IDs
+	if err := services.EnsureServiceCatalogue(context.Background()); err != nil {
+		fatal("Failed to prepare the service catalogue", err)
+	}
+
+	// Index meetings for overlap checks and set the end of meetings that have none
+	if err := services.EnsureMeetingSchedule(context.Background()); err != nil {
+		fatal("Failed to prepare the meeting schedule", err)
 	}
-	// Ensure MongoDB connection is closed when the application is terminated
-	defer database.Disconnect()
-	
+
+	// Refresh the gauges computed from the stored data in the background
+	metricsHeartbeat := health.NewHeartbeat(3 * cfg.Metrics.RefreshInterval)
+	liveness.Register(health.Checker{Name: "metrics-refresh", Critical: true, Check: metricsHeartbeat.Check})
+	manager.Go("metrics-refresh", func(ctx context.Context) {
+		ticker := time.NewTicker(cfg.Metrics.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			if err := services.RefreshMetrics(ctx); err != nil {
+				slog.ErrorContext(ctx, "Failed to refresh metrics", "error", err)
+			}
+			metricsHeartbeat.Beat()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+
+	// Create the upcoming occurrences of meeting series as their horizon moves
+	services.SeriesHorizon = cfg.Series.Horizon
+	seriesHeartbeat := health.NewHeartbeat(3 * cfg.Series.RefreshInterval)
+	liveness.Register(health.Checker{Name: "series-materialize", Critical: true, Check: seriesHeartbeat.Check})
+	manager.Go("series-materialize", func(ctx context.Context) {
+		ticker := time.NewTicker(cfg.Series.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			if err := services.MaterializeSeries(ctx); err != nil {
+				slog.ErrorContext(ctx, "Failed to create occurrences of meeting series", "error", err)
+			}
+			seriesHeartbeat.Beat()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+
 	// Create router
 	router := mux.NewRouter()
 
-	// Apply CORS middleware to all routes
-	router.Use(middleware.CorsHandler)
+	// Trace every request, continuing the caller's trace if any
+	router.Use(middleware.Tracing)
+
+	// Count requests and observe their duration by route
+	router.Use(middleware.Metrics)
+
+	// Reject clients sending too many requests, across all routes by IP address here
+	// and then by route and user
+	var limiter *middleware.RateLimiter
+	if cfg.RateLimit.Enabled {
+		limiter = middleware.NewRateLimiter(ratelimit.NewMemoryStore(), middleware.RateLimits{
+			Default:        cfg.RateLimit.Default,
+			Routes:         cfg.RateLimit.Routes,
+			PerIP:          cfg.RateLimit.PerIP,
+			TrustedProxies: cfg.RateLimit.TrustedProxies,
+		}, handlers.TooManyRequests)
+	}
+	router.Use(limiter.PerIP)
+
+	// Identify the signed-in user of every request by their session token
+	router.Use(middleware.Authenticate(middleware.BearerIdentity(services.Sessions.Verify)))
+
+	// Store the responses of idempotent routes so retries with the same Idempotency-Key
+	// replay them. A key stays claimed for as long as its request may take to answer.
+	idempotencyStore, err := idempotency.NewMongoStore(context.Background(), database.IdempotencyCollection, services.Keyring)
+	if err != nil {
+		fatal("Failed to prepare the idempotency store", err)
+	}
+	idempotent := middleware.NewIdempotency(idempotencyStore, cfg.Idempotency.TTL, cfg.Server.WriteTimeout, cfg.RateLimit.TrustedProxies, handlers.IdempotencyFailed)
 
 	// Setup API routes
-	router = api.SetupRoutes(router)
+	router = api.SetupRoutes(router, api.Options{
+		Liveness:       liveness,
+		Readiness:      readiness,
+		RequestTimeout: cfg.Server.RequestTimeout,
+		Limiter:        limiter,
+		Idempotency:    idempotent,
+	})
 
-	// Setup Swagger documentation
-	router.PathPrefix("/swagger").Handler(httpSwagger.Handler())
+	// Setup Swagger documentation, a page that needs its own scripts and styles
+	swaggerPolicy := middleware.ContentSecurityPolicy("default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:")
+	router.PathPrefix("/swagger").Handler(swaggerPolicy(httpSwagger.Handler()))
+
+	// Apply the CORS policy around the router, so preflight requests are answered
+	// even for routes that do not handle OPTIONS
+	var handler http.Handler = router
+	handler = middleware.Cors(middleware.CorsPolicy{
+		AllowedOrigins:   cfg.Cors.AllowedOrigins,
+		AllowedMethods:   cfg.Cors.AllowedMethods,
+		AllowedHeaders:   cfg.Cors.AllowedHeaders,
+		ExposedHeaders:   cfg.Cors.ExposedHeaders,
+		AllowCredentials: cfg.Cors.AllowCredentials,
+		MaxAge:           cfg.Cors.MaxAge,
+	}, handlers.CorsRejected)(handler)
+
+	// Assign an ID to every request so failures can be traced, including the
+	// preflights rejected by the CORS policy and requests matching no route
+	handler = middleware.RequestID(handler)
+
+	// Limit the size of request bodies, and send the security headers with every response
+	handler = middleware.MaxBytes(cfg.Server.MaxBodyBytes)(handler)
+	handler = middleware.SecurityHeaders(cfg.Server.HSTSMaxAge)(handler)
+	server.Handler = handler
 
 	// Start HTTP server
-	log.Printf("Server running on port %s", PORT)
-	
-	// Create a server
-	server := &http.Server{
-		Addr:    ":" + PORT,
-		Handler: router,
-	}
-	
-	// Start server in a goroutine
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
-		}
-	}()
-	
-	// Setup graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	
-	log.Println("Server shutting down...")
-}
\ No newline at end of file
+	slog.Info("Server running", "port", cfg.Server.Port, "tls", server.TLSConfig != nil)
+
+	// Serve until a shutdown signal, then drain requests, stop workers and disconnect
+	if err := manager.Run(); err != nil {
+		fatal("Server stopped with errors", err)
+	}
+}
+
+// requestAttrs tags log records with the request, signed-in user and trace they belong to
+func requestAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+	if requestID := middleware.GetRequestID(ctx); requestID != "" {
+		attrs = append(attrs, slog.String("requestId", requestID))
+	}
+	if userID := middleware.GetUserID(ctx); userID != "" {
+		attrs = append(attrs, slog.String("userId", userID))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		attrs = append(attrs, slog.String("traceId", span.TraceID().String()), slog.String("spanId", span.SpanID().String()))
+	}
+	return attrs
+}
+
+// fatal logs an error that prevents the server from running and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,451 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"neighborguard/pkg/ratelimit"
+	"neighborguard/pkg/validation"
+)
+
+type Environment string
+
+const (
+	Development Environment = "development"
+	Production  Environment = "production"
+)
+
+// MongoDB server used in development when no URI is configured
+const defaultDevelopmentMongoURI = "mongodb://localhost:27017"
+
+// Shortest secret accepted, so it cannot be guessed
+const minSecretLength = 32
+
+// RouteTimeouts are the timeouts of the routes that may need longer than
+// server.requestTimeout, by method and path. Set before loading the configuration,
+// so server.writeTimeout is checked against them too.
+var RouteTimeouts map[string]time.Duration
+
+// Config is the effective configuration of the server
+type Config struct {
+	Environment Environment
+	ConfigFile  string // file the configuration was read from, if any
+
+	Server      ServerConfig
+	Mongo       MongoConfig
+	Matching    MatchingConfig
+	Users       UsersConfig
+	Privacy     PrivacyConfig
+	Auth        AuthConfig
+	Encryption  EncryptionConfig
+	Health      HealthConfig
+	Metrics     MetricsConfig
+	Logging     LoggingConfig
+	Tracing     TracingConfig
+	RateLimit   RateLimitConfig
+	Cors        CorsConfig
+	Idempotency IdempotencyConfig
+	Series      SeriesConfig
+}
+
+type ServerConfig struct {
+	Port              int
+	RequestTimeout    time.Duration // how long a request may take before its context is cancelled
+	ReadHeaderTimeout time.Duration // how long clients get to send the request headers
+	ReadTimeout       time.Duration // how long clients get to send the whole request
+	WriteTimeout      time.Duration // how long the response may take, from the end of the request headers
+	IdleTimeout       time.Duration // how long idle keep-alive connections are kept open
+	MaxBodyBytes      int64         // largest request body accepted
+	TLSCertFile       string        // PEM certificate served over HTTPS, reloaded on SIGHUP; plain HTTP if empty
+	TLSKeyFile        string
+	HSTSMaxAge        time.Duration // how long browsers should only use HTTPS; not sent if zero
+	ShutdownTimeout   time.Duration // how long in-flight requests and workers get to finish on shutdown
+	ShutdownDelay     time.Duration // how long readiness fails before the server stops accepting connections
+}
+
+type MongoConfig struct {
+	URI         string
+	Database    string
+	Timeout     time.Duration // applied to every database operation
+	Collections CollectionsConfig
+}
+
+type CollectionsConfig struct {
+	Users        string
+	Meetings     string
+	Services     string
+	CheckIns     string
+	Audit        string
+	Idempotency  string
+	Availability string
+	Series       string
+}
+
+type MatchingConfig struct {
+	SearchRadiusKm   float64       // how far from the filter location recipients are searched
+	CheckInThreshold time.Duration // how long after their last check-in recipients need a General Check
+}
+
+type UsersConfig struct {
+	DefaultTimezone string // IANA timezone of users who register without one
+}
+
+type PrivacyConfig struct {
+	LocationFuzzing string // GRID_SNAPPING or RANDOM_OFFSET
+	LocationSecret  string // keys the random offset of each user, required with RANDOM_OFFSET
+}
+
+type AuthConfig struct {
+	SessionSecret string        // signs the session tokens identifying users, random on each start in development if empty
+	SessionTTL    time.Duration // how long a session token is valid
+}
+
+type EncryptionConfig struct {
+	KeyringFile string
+}
+
+type MetricsConfig struct {
+	RefreshInterval time.Duration // how often the gauges computed from the stored data are refreshed
+}
+
+type LoggingConfig struct {
+	Level  string // initial level: debug, info, warn or error
+	Format string // json or text
+}
+
+type TracingConfig struct {
+	Exporter    string  // none, stdout or otlp
+	Endpoint    string  // URL of the OTLP/HTTP collector, OTEL_EXPORTER_OTLP_ENDPOINT or https://localhost:4318 if empty
+	SampleRatio float64 // share of new traces that are recorded, from 0 to 1
+}
+
+type RateLimitConfig struct {
+	Enabled        bool
+	Default        ratelimit.Limit            // of each client on each route without a limit of its own
+	Routes         map[string]ratelimit.Limit // by method and path relative to the API version, such as "POST /users"
+	PerIP          ratelimit.Limit            // of each IP address across all routes
+	TrustedProxies int                        // proxies in front of the server that append to X-Forwarded-For
+}
+
+type CorsConfig struct {
+	AllowedOrigins   []string // such as https://app.example.com, https://*.example.com for any subdomain, or *; none if empty
+	AllowedMethods   []string
+	AllowedHeaders   []string      // request headers browser clients may send, or *
+	ExposedHeaders   []string      // response headers browser clients may read
+	AllowCredentials bool          // whether browsers send cookies and authorization headers along
+	MaxAge           time.Duration // how long browsers may cache a preflight response
+}
+
+type SeriesConfig struct {
+	Horizon         time.Duration // how far ahead the occurrences of meeting series are created
+	RefreshInterval time.Duration // how often occurrences are created as the horizon moves
+}
+
+type IdempotencyConfig struct {
+	TTL time.Duration // how long the response to an Idempotency-Key is replayed
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration // how long each dependency check of a probe may take
+}
+
+// Default returns the configuration used for settings that are not given
+func Default() Config {
+	return Config{
+		Environment: Development,
+		Server: ServerConfig{
+			Port:              8080,
+			RequestTimeout:    15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      90 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxBodyBytes:      1 << 20,
+			HSTSMaxAge:        365 * 24 * time.Hour,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Mongo: MongoConfig{
+			Database: "neighborguard",
+			Timeout:  10 * time.Second,
+			Collections: CollectionsConfig{
+				Users:        "users",
+				Meetings:     "meetings",
+				Services:     "services",
+				CheckIns:     "checkins",
+				Audit:        "audit",
+				Idempotency:  "idempotency",
+				Availability: "availability",
+				Series:       "series",
+			},
+		},
+		Users: UsersConfig{DefaultTimezone: "UTC"},
+		Matching: MatchingConfig{
+			SearchRadiusKm:   1,
+			CheckInThreshold: time.Minute,
+		},
+		Privacy: PrivacyConfig{LocationFuzzing: "GRID_SNAPPING"},
+		Auth:    AuthConfig{SessionTTL: 24 * time.Hour},
+		Cors: CorsConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "If-Match", "Idempotency-Key", "X-Request-ID"},
+			ExposedHeaders: []string{
+				"ETag", "X-Request-ID", "Deprecation", "Link", "Sunset", "Retry-After",
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+				"Idempotent-Replayed",
+			},
+			MaxAge: 10 * time.Minute,
+		},
+		Health:  HealthConfig{CheckTimeout: 2 * time.Second},
+		Metrics: MetricsConfig{RefreshInterval: 30 * time.Second},
+		Logging: LoggingConfig{Level: "info", Format: "json"},
+		Tracing: TracingConfig{Exporter: "none", SampleRatio: 1},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Default: ratelimit.Limit{Requests: 120, Period: time.Minute},
+			Routes: map[string]ratelimit.Limit{
+				"POST /users":                 {Requests: 20, Period: time.Hour},
+				"POST /sessions":              {Requests: 10, Period: time.Minute},
+				"GET /users":                  {Requests: 20, Period: time.Minute},
+				"GET /users/recipients":       {Requests: 30, Period: time.Minute},
+				"GET /users/recipients/{uid}": {Requests: 60, Period: time.Minute},
+			},
+			PerIP: ratelimit.Limit{Requests: 600, Period: time.Minute},
+		},
+		Idempotency: IdempotencyConfig{TTL: 24 * time.Hour},
+		Series:      SeriesConfig{Horizon: 28 * 24 * time.Hour, RefreshInterval: time.Hour},
+	}
+}
+
+// Load builds the configuration from the defaults, then a JSON file, then
+// environment variables, then command line flags, each overriding the
+// previous ones. The file is given with -config or CONFIG_FILE.
+func Load(args []string) (Config, error) {
+	cfg := Default()
+
+	// Every setting has a flag, only the flags actually passed override the other sources
+	flags := flag.NewFlagSet("neighborguard", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "JSON configuration file")
+	flagValues := map[string]*string{}
+	for _, s := range settings {
+		flagValues[s.name] = flags.String(s.flag(), "", s.usage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	// Configuration file
+	if *configFile != "" {
+		if err := loadFile(&cfg, *configFile); err != nil {
+			return Config{}, err
+		}
+		cfg.ConfigFile = *configFile
+	}
+
+	// Environment variables
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(&cfg, value); err != nil {
+				return Config{}, fmt.Errorf("environment variable %s: %w", s.env, err)
+			}
+		}
+	}
+
+	// Command line flags
+	var flagErr error
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if f.Name == s.flag() && flagErr == nil {
+				if err := s.set(&cfg, *flagValues[s.name]); err != nil {
+					flagErr = fmt.Errorf("flag -%s: %w", f.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return Config{}, flagErr
+	}
+
+	// Development falls back to a local server, production must say where its data is
+	if cfg.Mongo.URI == "" && cfg.Environment != Production {
+		cfg.Mongo.URI = defaultDevelopmentMongoURI
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// loadFile applies the settings of a JSON file, a flat object keyed by setting name
+// such as {"server.port": 8080, "mongo.uri": "mongodb://..."}
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// Numbers keep their text, so large integers are not turned into floats such as 2.097152e+06
+	var values map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+
+	for name, value := range values {
+		s, ok := settingsByName()[name]
+		if !ok {
+			return fmt.Errorf("configuration file %s: unknown setting %q", path, name)
+		}
+		text, err := fileValue(value)
+		if err != nil {
+			return fmt.Errorf("configuration file %s: %s: %w", path, name, err)
+		}
+		if err := s.set(cfg, text); err != nil {
+			return fmt.Errorf("configuration file %s: %s: %w", path, name, err)
+		}
+	}
+	return nil
+}
+
+// fileValue converts a value of the configuration file to the text of the same
+// setting in an environment variable. Arrays become comma separated lists.
+func fileValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case []interface{}:
+		entries := make([]string, 0, len(value))
+		for _, entry := range value {
+			text, err := fileValue(entry)
+			if err != nil {
+				return "", err
+			}
+			if _, nested := entry.([]interface{}); nested || strings.Contains(text, ",") {
+				return "", errors.New("array entries must be strings, numbers or booleans without commas")
+			}
+			entries = append(entries, text)
+		}
+		return strings.Join(entries, ", "), nil
+	}
+	return "", errors.New("must be a string, number, boolean or array")
+}
+
+// Validate checks that the configuration can be used to start the server
+func (c Config) Validate() error {
+	v := &validation.Validator{}
+
+	validation.OneOf(v, "environment", c.Environment, Development, Production)
+	v.IntRange("server.port", c.Server.Port, 1, 65535)
+	v.Check(c.Server.RequestTimeout > 0, "server.requestTimeout", "must be positive")
+	v.Check(c.Server.ReadHeaderTimeout > 0, "server.readHeaderTimeout", "must be positive")
+	v.Check(c.Server.ReadTimeout >= c.Server.ReadHeaderTimeout, "server.readTimeout", "must not be shorter than server.readHeaderTimeout")
+	v.Check(c.Server.WriteTimeout > c.Server.RequestTimeout, "server.writeTimeout", "must be longer than server.requestTimeout, so timed out requests are still answered")
+	for _, route := range slices.Sorted(maps.Keys(RouteTimeouts)) {
+		v.Check(c.Server.WriteTimeout > RouteTimeouts[route], "server.writeTimeout", fmt.Sprintf("must be longer than the %s timeout of %s, so timed out requests are still answered", RouteTimeouts[route], route))
+	}
+	v.Check(c.Server.IdleTimeout > 0, "server.idleTimeout", "must be positive")
+	v.Check(c.Server.MaxBodyBytes > 0, "server.maxBodyBytes", "must be positive")
+	v.Check((c.Server.TLSCertFile == "") == (c.Server.TLSKeyFile == ""), "server.tlsKeyFile", "must be given together with server.tlsCertFile")
+	v.Check(c.Server.HSTSMaxAge >= 0, "server.hstsMaxAge", "must not be negative")
+	v.Check(c.Server.ShutdownTimeout > 0, "server.shutdownTimeout", "must be positive")
+	v.Check(c.Server.ShutdownDelay >= 0, "server.shutdownDelay", "must not be negative")
+	v.Check(c.Mongo.URI != "", "mongo.uri", fmt.Sprintf("is required in %s", Production))
+	v.Check(c.Mongo.URI == "" || strings.HasPrefix(c.Mongo.URI, "mongodb://") || strings.HasPrefix(c.Mongo.URI, "mongodb+srv://"),
+		"mongo.uri", "must start with mongodb:// or mongodb+srv://")
+	v.Required("mongo.database", c.Mongo.Database)
+	v.Check(c.Mongo.Timeout > 0, "mongo.timeout", "must be positive")
+	v.Required("mongo.collections.users", c.Mongo.Collections.Users)
+	v.Required("mongo.collections.meetings", c.Mongo.Collections.Meetings)
+	v.Required("mongo.collections.services", c.Mongo.Collections.Services)
+	v.Required("mongo.collections.checkins", c.Mongo.Collections.CheckIns)
+	v.Required("mongo.collections.audit", c.Mongo.Collections.Audit)
+	v.Required("mongo.collections.idempotency", c.Mongo.Collections.Idempotency)
+	v.Required("mongo.collections.availability", c.Mongo.Collections.Availability)
+	v.Required("mongo.collections.series", c.Mongo.Collections.Series)
+	v.Check(c.Matching.SearchRadiusKm > 0, "matching.searchRadiusKm", "must be positive")
+	v.Check(c.Matching.CheckInThreshold > 0, "matching.checkInThreshold", "must be positive")
+	_, err := time.LoadLocation(c.Users.DefaultTimezone)
+	v.Check(err == nil && c.Users.DefaultTimezone != "" && c.Users.DefaultTimezone != "Local", "users.defaultTimezone", "must be an IANA timezone such as Europe/Paris")
+	v.Check(c.Health.CheckTimeout > 0, "health.checkTimeout", "must be positive")
+	v.Check(c.Metrics.RefreshInterval > 0, "metrics.refreshInterval", "must be positive")
+	validation.OneOf(v, "privacy.locationFuzzing", c.Privacy.LocationFuzzing, "GRID_SNAPPING", "RANDOM_OFFSET")
+	v.Check(c.Privacy.LocationFuzzing != "RANDOM_OFFSET" || len(c.Privacy.LocationSecret) >= minSecretLength,
+		"privacy.locationSecret", fmt.Sprintf("must be at least %d characters with RANDOM_OFFSET", minSecretLength))
+	v.Check(len(c.Auth.SessionSecret) >= minSecretLength || (c.Auth.SessionSecret == "" && c.Environment != Production),
+		"auth.sessionSecret", fmt.Sprintf("must be at least %d characters, and is required in %s", minSecretLength, Production))
+	v.Check(c.Auth.SessionTTL > 0, "auth.sessionTTL", "must be positive")
+	validation.OneOf(v, "logging.level", strings.ToLower(c.Logging.Level), "debug", "info", "warn", "error")
+	validation.OneOf(v, "logging.format", c.Logging.Format, "json", "text")
+	validation.OneOf(v, "tracing.exporter", c.Tracing.Exporter, "none", "stdout", "otlp")
+	for _, origin := range c.Cors.AllowedOrigins {
+		v.Check(validOrigin(origin), "cors.allowedOrigins", fmt.Sprintf("%q must be * or a scheme and host such as https://app.example.com or https://*.example.com", origin))
+		v.Check(origin != "*" || !c.Cors.AllowCredentials, "cors.allowedOrigins", "cannot allow any origin with credentials")
+	}
+	v.Check(c.Cors.MaxAge >= 0, "cors.maxAge", "must not be negative")
+	v.Check(c.Idempotency.TTL > 0, "idempotency.ttl", "must be positive")
+	v.Check(c.Series.Horizon > 0, "series.horizon", "must be positive")
+	v.Check(c.Series.RefreshInterval > 0, "series.refreshInterval", "must be positive")
+	v.Check(c.RateLimit.TrustedProxies >= 0, "rateLimit.trustedProxies", "must not be negative")
+	v.Check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio", "must be between 0 and 1")
+
+	if err := v.Err(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+// Redacted describes the effective configuration with its secrets hidden, one setting per line
+func (c Config) Redacted() string {
+	var b strings.Builder
+	if c.ConfigFile != "" {
+		fmt.Fprintf(&b, "config file = %s\n", c.ConfigFile)
+	}
+	for _, s := range settings {
+		value := s.get(c)
+		if s.redact != nil && value != "" {
+			value = s.redact(value)
+		}
+		fmt.Fprintf(&b, "%s = %s\n", s.name, value)
+	}
+	return b.String()
+}
+
+// validOrigin reports whether an allowed CORS origin is *, or a scheme and host
+// with an optional port, where the host may start with *. for any subdomain
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	scheme, host, found := strings.Cut(origin, "://")
+	if !found || (scheme != "http" && scheme != "https") {
+		return false
+	}
+	host = strings.TrimPrefix(host, "*.")
+	return host != "" && !strings.ContainsAny(host, "/*@?#")
+}
+
+// parseList reads a comma separated list, ignoring empty entries
+func parseList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+func parseInt(value string) (int, error) {
+	return strconv.Atoi(strings.TrimSpace(value))
+}
@@ -0,0 +1,381 @@
+package config
+
+import (
+	"neighborguard/pkg/ratelimit"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting is one configuration value, named the same way in every source:
+// "mongo.uri" is the key in the file, MONGO_URI in the environment and
+// -mongo-uri on the command line
+type setting struct {
+	name   string
+	env    string
+	usage  string
+	set    func(c *Config, value string) error
+	get    func(c Config) string
+	redact func(value string) string // hides secrets when the configuration is printed
+}
+
+// flag returns the command line flag of the setting
+func (s setting) flag() string {
+	return strings.ReplaceAll(kebab(s.name), ".", "-")
+}
+
+var settings = []setting{
+	{
+		name: "environment", env: "APP_ENV", usage: "development or production",
+		set: func(c *Config, v string) error { c.Environment = Environment(v); return nil },
+		get: func(c Config) string { return string(c.Environment) },
+	},
+	{
+		name: "server.port", env: "PORT", usage: "HTTP port to listen on",
+		set: func(c *Config, v string) (err error) { c.Server.Port, err = parseInt(v); return err },
+		get: func(c Config) string { return strconv.Itoa(c.Server.Port) },
+	},
+	{
+		name: "server.requestTimeout", env: "REQUEST_TIMEOUT", usage: "how long a request may take before it is cancelled, such as 15s",
+		set: func(c *Config, v string) (err error) {
+			c.Server.RequestTimeout, err = time.ParseDuration(v)
+			return err
+		},
+		get: func(c Config) string { return c.Server.RequestTimeout.String() },
+	},
+	{
+		name: "server.readHeaderTimeout", env: "READ_HEADER_TIMEOUT", usage: "how long clients get to send the request headers, such as 5s",
+		set: func(c *Config, v string) (err error) {
+			c.Server.ReadHeaderTimeout, err = time.ParseDuration(v)
+			return err
+		},
+		get: func(c Config) string { return c.Server.ReadHeaderTimeout.String() },
+	},
+	{
+		name: "server.readTimeout", env: "READ_TIMEOUT", usage: "how long clients get to send the whole request",
+		set: func(c *Config, v string) (err error) { c.Server.ReadTimeout, err = time.ParseDuration(v); return err },
+		get: func(c Config) string { return c.Server.ReadTimeout.String() },
+	},
+	{
+		name: "server.writeTimeout", env: "WRITE_TIMEOUT", usage: "how long a response may take, longer than the request timeout",
+		set: func(c *Config, v string) (err error) { c.Server.WriteTimeout, err = time.ParseDuration(v); return err },
+		get: func(c Config) string { return c.Server.WriteTimeout.String() },
+	},
+	{
+		name: "server.idleTimeout", env: "IDLE_TIMEOUT", usage: "how long idle keep-alive connections are kept open",
+		set: func(c *Config, v string) (err error) { c.Server.IdleTimeout, err = time.ParseDuration(v); return err },
+		get: func(c Config) string { return c.Server.IdleTimeout.String() },
+	},
+	{
+		name: "server.maxBodyBytes", env: "MAX_BODY_BYTES", usage: "largest request body accepted, in bytes",
+		set: func(c *Config, v string) (err error) {
+			c.Server.MaxBodyBytes, err = strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			return err
+		},
+		get: func(c Config) string { return strconv.FormatInt(c.Server.MaxBodyBytes, 10) },
+	},
+	{
+		name: "server.tlsCertFile", env: "TLS_CERT_FILE", usage: "PEM certificate to serve HTTPS with, reloaded on SIGHUP",
+		set: func(c *Config, v string) error { c.Server.TLSCertFile = v; return nil },
+		get: func(c Config) string { return c.Server.TLSCertFile },
+	},
+	{
+		name: "server.tlsKeyFile", env: "TLS_KEY_FILE", usage: "PEM private key of the TLS certificate",
+		set: func(c *Config, v string) error { c.Server.TLSKeyFile = v; return nil },
+		get: func(c Config) string { return c.Server.TLSKeyFile },
+	},
+	{
+		name: "server.hstsMaxAge", env: "HSTS_MAX_AGE", usage: "how long browsers should only use HTTPS, 0 to not send HSTS",
+		set: func(c *Config, v string) (err error) { c.Server.HSTSMaxAge, err = time.ParseDuration(v); return err },
+		get: func(c Config) string { return c.Server.HSTSMaxAge.String() },
+	},
+	{
+		name: "server.shutdownTimeout", env: "SHUTDOWN_TIMEOUT", usage: "how long in-flight requests get to finish on shutdown, such as 30s",
+		set: func(c *Config, v string) (err error) {
+			c.Server.ShutdownTimeout, err = time.ParseDuration(v)
+			return err
+		},
+		get: func(c Config) string { return c.Server.ShutdownTimeout.String() },
+	},
+	{
+		name: "server.shutdownDelay", env: "SHUTDOWN_DELAY", usage: "how long readiness fails before the server stops accepting connections",
+		set: func(c *Config, v string) (err error) {
+			c.Server.ShutdownDelay, err = time.ParseDuration(v)
+			return err
+		},
+		get: func(c Config) string { return c.Server.ShutdownDelay.String() },
+	},
+	{
+		name: "mongo.uri", env: "MONGO_URI", usage: "MongoDB connection string, required in production",
+		set:    func(c *Config, v string) error { c.Mongo.URI = v; return nil },
+		get:    func(c Config) string { return c.Mongo.URI },
+		redact: redactURI,
+	},
+	{
+		name: "mongo.database", env: "MONGO_DATABASE", usage: "MongoDB database name",
+		set: func(c *Config, v string) error { c.Mongo.Database = v; return nil },
+		get: func(c Config) string { return c.Mongo.Database },
+	},
+	{
+		name: "mongo.timeout", env: "MONGO_TIMEOUT", usage: "timeout of database operations, such as 10s",
+		set: func(c *Config, v string) (err error) { c.Mongo.Timeout, err = time.ParseDuration(v); return err },
+		get: func(c Config) string { return c.Mongo.Timeout.String() },
+	},
+	{
+		name: "mongo.collections.users", env: "MONGO_USERS_COLLECTION", usage: "collection of users",
+		set: func(c *Config, v string) error { c.Mongo.Collections.Users = v; return nil },
+		get: func(c Config) string { return c.Mongo.Collections.Users },
+	},
+	{
+		name: "mongo.collections.meetings", env: "MONGO_MEETINGS_COLLECTION", usage: "collection of meetings",
+		set: func(c *Config, v string) error { c.Mongo.Collections.Meetings = v; return nil },
+		get: func(c Config) string { return c.Mongo.Collections.Meetings },
+	},
+	{
+		name: "mongo.collections.services", env: "MONGO_SERVICES_COLLECTION", usage: "collection of the service catalogue",
+		set: func(c *Config, v string) error { c.Mongo.Collections.Services = v; return nil },
+		get: func(c Config) string { return c.Mongo.Collections.Services },
+	},
+	{
+		name: "mongo.collections.checkins", env: "MONGO_CHECKINS_COLLECTION", usage: "collection of check-ins",
+		set: func(c *Config, v string) error { c.Mongo.Collections.CheckIns = v; return nil },
+		get: func(c Config) string { return c.Mongo.Collections.CheckIns },
+	},
+	{
+		name: "mongo.collections.audit", env: "MONGO_AUDIT_COLLECTION", usage: "collection of audit entries",
+		set: func(c *Config, v string) error { c.Mongo.Collections.Audit = v; return nil },
+		get: func(c Config) string { return c.Mongo.Collections.Audit },
+	},
+	{
+		name: "mongo.collections.idempotency", env: "MONGO_IDEMPOTENCY_COLLECTION", usage: "collection of the responses stored for idempotency keys",
+		set: func(c *Config, v string) error { c.Mongo.Collections.Idempotency = v; return nil },
+		get: func(c Config) string { return c.Mongo.Collections.Idempotency },
+	},
+	{
+		name: "mongo.collections.availability", env: "MONGO_AVAILABILITY_COLLECTION", usage: "collection of volunteer schedules",
+		set: func(c *Config, v string) error { c.Mongo.Collections.Availability = v; return nil },
+		get: func(c Config) string { return c.Mongo.Collections.Availability },
+	},
+	{
+		name: "mongo.collections.series", env: "MONGO_SERIES_COLLECTION", usage: "collection of recurring meeting series",
+		set: func(c *Config, v string) error { c.Mongo.Collections.Series = v; return nil },
+		get: func(c Config) string { return c.Mongo.Collections.Series },
+	},
+	{
+		name: "matching.searchRadiusKm", env: "SEARCH_RADIUS_KM", usage: "radius of recipient searches around a location, in km",
+		set: func(c *Config, v string) (err error) {
+			c.Matching.SearchRadiusKm, err = strconv.ParseFloat(strings.TrimSpace(v), 64)
+			return err
+		},
+		get: func(c Config) string { return strconv.FormatFloat(c.Matching.SearchRadiusKm, 'f', -1, 64) },
+	},
+	{
+		name: "matching.checkInThreshold", env: "CHECKIN_THRESHOLD", usage: "time without check-in after which a recipient needs a General Check, such as 24h",
+		set: func(c *Config, v string) (err error) {
+			c.Matching.CheckInThreshold, err = time.ParseDuration(v)
+			return err
+		},
+		get: func(c Config) string { return c.Matching.CheckInThreshold.String() },
+	},
+	{
+		name: "users.defaultTimezone", env: "DEFAULT_TIMEZONE", usage: "IANA timezone of users who register without one, such as Europe/Paris",
+		set: func(c *Config, v string) error { c.Users.DefaultTimezone = v; return nil },
+		get: func(c Config) string { return c.Users.DefaultTimezone },
+	},
+	{
+		name: "privacy.locationFuzzing", env: "LOCATION_FUZZING", usage: "GRID_SNAPPING or RANDOM_OFFSET",
+		set: func(c *Config, v string) error { c.Privacy.LocationFuzzing = v; return nil },
+		get: func(c Config) string { return c.Privacy.LocationFuzzing },
+	},
+	{
+		name: "privacy.locationSecret", env: "LOCATION_SECRET", usage: "secret of at least 32 characters keying the offsets of RANDOM_OFFSET",
+		set:    func(c *Config, v string) error { c.Privacy.LocationSecret = v; return nil },
+		get:    func(c Config) string { return c.Privacy.LocationSecret },
+		redact: redactSecret,
+	},
+	{
+		name: "encryption.keyringFile", env: "KEYRING_FILE", usage: "keyring encrypting sensitive user fields, stored in clear text if empty",
+		set: func(c *Config, v string) error { c.Encryption.KeyringFile = v; return nil },
+		get: func(c Config) string { return c.Encryption.KeyringFile },
+	},
+	{
+		name: "health.checkTimeout", env: "HEALTH_CHECK_TIMEOUT", usage: "how long each dependency check of a probe may take",
+		set: func(c *Config, v string) (err error) {
+			c.Health.CheckTimeout, err = time.ParseDuration(v)
+			return err
+		},
+		get: func(c Config) string { return c.Health.CheckTimeout.String() },
+	},
+	{
+		name: "metrics.refreshInterval", env: "METRICS_REFRESH_INTERVAL", usage: "how often the gauges computed from the stored data are refreshed",
+		set: func(c *Config, v string) (err error) {
+			c.Metrics.RefreshInterval, err = time.ParseDuration(v)
+			return err
+		},
+		get: func(c Config) string { return c.Metrics.RefreshInterval.String() },
+	},
+	{
+		name: "logging.level", env: "LOG_LEVEL", usage: "initial log level: debug, info, warn or error",
+		set: func(c *Config, v string) error { c.Logging.Level = v; return nil },
+		get: func(c Config) string { return c.Logging.Level },
+	},
+	{
+		name: "logging.format", env: "LOG_FORMAT", usage: "json or text",
+		set: func(c *Config, v string) error { c.Logging.Format = v; return nil },
+		get: func(c Config) string { return c.Logging.Format },
+	},
+	{
+		name: "tracing.exporter", env: "TRACING_EXPORTER", usage: "where spans are exported: none, stdout or otlp",
+		set: func(c *Config, v string) error { c.Tracing.Exporter = v; return nil },
+		get: func(c Config) string { return c.Tracing.Exporter },
+	},
+	{
+		name: "tracing.endpoint", env: "TRACING_ENDPOINT", usage: "URL of the OTLP/HTTP collector, such as http://localhost:4318",
+		set: func(c *Config, v string) error { c.Tracing.Endpoint = v; return nil },
+		get: func(c Config) string { return c.Tracing.Endpoint },
+	},
+	{
+		name: "tracing.sampleRatio", env: "TRACING_SAMPLE_RATIO", usage: "share of new traces that are recorded, from 0 to 1",
+		set: func(c *Config, v string) (err error) {
+			c.Tracing.SampleRatio, err = strconv.ParseFloat(strings.TrimSpace(v), 64)
+			return err
+		},
+		get: func(c Config) string { return strconv.FormatFloat(c.Tracing.SampleRatio, 'f', -1, 64) },
+	},
+	{
+		name: "auth.sessionSecret", env: "AUTH_SESSION_SECRET", usage: "secret of at least 32 characters signing session tokens, required in production",
+		set:    func(c *Config, v string) error { c.Auth.SessionSecret = v; return nil },
+		get:    func(c Config) string { return c.Auth.SessionSecret },
+		redact: redactSecret,
+	},
+	{
+		name: "auth.sessionTTL", env: "AUTH_SESSION_TTL", usage: "how long a session token is valid, such as 24h",
+		set: func(c *Config, v string) (err error) { c.Auth.SessionTTL, err = time.ParseDuration(v); return err },
+		get: func(c Config) string { return c.Auth.SessionTTL.String() },
+	},
+	{
+		name: "cors.allowedOrigins", env: "CORS_ALLOWED_ORIGINS", usage: "origins of browser clients, such as https://app.example.com, https://*.example.com",
+		set: func(c *Config, v string) error { c.Cors.AllowedOrigins = parseList(v); return nil },
+		get: func(c Config) string { return strings.Join(c.Cors.AllowedOrigins, ", ") },
+	},
+	{
+		name: "cors.allowedMethods", env: "CORS_ALLOWED_METHODS", usage: "methods browser clients may use",
+		set: func(c *Config, v string) error { c.Cors.AllowedMethods = parseList(v); return nil },
+		get: func(c Config) string { return strings.Join(c.Cors.AllowedMethods, ", ") },
+	},
+	{
+		name: "cors.allowedHeaders", env: "CORS_ALLOWED_HEADERS", usage: "request headers browser clients may send, or *",
+		set: func(c *Config, v string) error { c.Cors.AllowedHeaders = parseList(v); return nil },
+		get: func(c Config) string { return strings.Join(c.Cors.AllowedHeaders, ", ") },
+	},
+	{
+		name: "cors.exposedHeaders", env: "CORS_EXPOSED_HEADERS", usage: "response headers browser clients may read",
+		set: func(c *Config, v string) error { c.Cors.ExposedHeaders = parseList(v); return nil },
+		get: func(c Config) string { return strings.Join(c.Cors.ExposedHeaders, ", ") },
+	},
+	{
+		name: "cors.allowCredentials", env: "CORS_ALLOW_CREDENTIALS", usage: "whether browsers send cookies and authorization headers along",
+		set: func(c *Config, v string) (err error) {
+			c.Cors.AllowCredentials, err = strconv.ParseBool(strings.TrimSpace(v))
+			return err
+		},
+		get: func(c Config) string { return strconv.FormatBool(c.Cors.AllowCredentials) },
+	},
+	{
+		name: "cors.maxAge", env: "CORS_MAX_AGE", usage: "how long browsers may cache a preflight response, such as 10m",
+		set: func(c *Config, v string) (err error) { c.Cors.MaxAge, err = time.ParseDuration(v); return err },
+		get: func(c Config) string { return c.Cors.MaxAge.String() },
+	},
+	{
+		name: "rateLimit.enabled", env: "RATE_LIMIT_ENABLED", usage: "whether clients sending too many requests are rejected",
+		set: func(c *Config, v string) (err error) {
+			c.RateLimit.Enabled, err = strconv.ParseBool(strings.TrimSpace(v))
+			return err
+		},
+		get: func(c Config) string { return strconv.FormatBool(c.RateLimit.Enabled) },
+	},
+	{
+		name: "rateLimit.default", env: "RATE_LIMIT_DEFAULT", usage: "requests of each client to each route, such as 120/1m",
+		set: func(c *Config, v string) (err error) { c.RateLimit.Default, err = ratelimit.ParseLimit(v); return err },
+		get: func(c Config) string { return c.RateLimit.Default.String() },
+	},
+	{
+		name: "rateLimit.routes", env: "RATE_LIMIT_ROUTES", usage: "limits of routes that differ from the default, such as \"POST /users=20/1h, GET /users=20/1m\"",
+		set: func(c *Config, v string) (err error) {
+			c.RateLimit.Routes, err = ratelimit.ParseRouteLimits(v)
+			return err
+		},
+		get: func(c Config) string { return ratelimit.FormatRouteLimits(c.RateLimit.Routes) },
+	},
+	{
+		name: "rateLimit.perIP", env: "RATE_LIMIT_PER_IP", usage: "requests of each IP address to all routes, such as 600/1m",
+		set: func(c *Config, v string) (err error) { c.RateLimit.PerIP, err = ratelimit.ParseLimit(v); return err },
+		get: func(c Config) string { return c.RateLimit.PerIP.String() },
+	},
+	{
+		name: "rateLimit.trustedProxies", env: "RATE_LIMIT_TRUSTED_PROXIES", usage: "proxies in front of the server that append the client address to X-Forwarded-For",
+		set: func(c *Config, v string) (err error) { c.RateLimit.TrustedProxies, err = parseInt(v); return err },
+		get: func(c Config) string { return strconv.Itoa(c.RateLimit.TrustedProxies) },
+	},
+	{
+		name: "idempotency.ttl", env: "IDEMPOTENCY_TTL", usage: "how long the response to an Idempotency-Key is replayed, such as 24h",
+		set: func(c *Config, v string) (err error) { c.Idempotency.TTL, err = time.ParseDuration(v); return err },
+		get: func(c Config) string { return c.Idempotency.TTL.String() },
+	},
+	{
+		name: "series.horizon", env: "SERIES_HORIZON", usage: "how far ahead the occurrences of meeting series are created, such as 672h",
+		set: func(c *Config, v string) (err error) { c.Series.Horizon, err = time.ParseDuration(v); return err },
+		get: func(c Config) string { return c.Series.Horizon.String() },
+	},
+	{
+		name: "series.refreshInterval", env: "SERIES_REFRESH_INTERVAL", usage: "how often occurrences of meeting series are created as the horizon moves, such as 1h",
+		set: func(c *Config, v string) (err error) {
+			c.Series.RefreshInterval, err = time.ParseDuration(v)
+			return err
+		},
+		get: func(c Config) string { return c.Series.RefreshInterval.String() },
+	},
+}
+
+func settingsByName() map[string]setting {
+	byName := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		byName[s.name] = s
+	}
+	return byName
+}
+
+// kebab turns a name such as "matching.searchRadiusKm" into "matching.search-radius-km",
+// keeping acronyms such as the IP of "rateLimit.perIP" together
+func kebab(name string) string {
+	var b strings.Builder
+	previous := rune(0)
+	for _, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if previous >= 'a' && previous <= 'z' || previous >= '0' && previous <= '9' {
+				b.WriteByte('-')
+			}
+			previous = r
+			r += 'a' - 'A'
+		} else {
+			previous = r
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// redactSecret hides a secret entirely
+func redactSecret(value string) string {
+	return "[REDACTED]"
+}
+
+// redactURI hides the password of a connection string
+func redactURI(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return "[REDACTED]"
+	}
+	return parsed.Redacted()
+}
@@ -0,0 +1,107 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownKey is returned when a value was encrypted with a key missing from the keyring
+var ErrUnknownKey = errors.New("value was encrypted with a key that is not in the keyring")
+
+// Envelope is a value encrypted with its own data key, stored next to the data
+// key wrapped by a key of the keyring
+type Envelope struct {
+	KeyID      string `bson:"keyId"`      // keyring key that wrapped the data key
+	WrappedKey []byte `bson:"wrappedKey"` // nonce followed by the encrypted data key
+	Nonce      []byte `bson:"nonce"`
+	Ciphertext []byte `bson:"ciphertext"`
+}
+
+// Encrypt seals a value with a new data key wrapped by the active key. The
+// context, such as the record and field the value is stored in, must be given
+// again to decrypt the value, which stops encrypted values from being swapped
+// between fields or records.
+func (k *Keyring) Encrypt(plaintext []byte, context string) (Envelope, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Envelope{}, err
+	}
+
+	wrappedKey, err := seal(k.keys[k.activeKeyID], nil, dataKey, []byte(k.activeKeyID))
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return Envelope{}, err
+	}
+	ciphertext, err := seal(dataKey, nonce, plaintext, []byte(context))
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{KeyID: k.activeKeyID, WrappedKey: wrappedKey, Nonce: nonce, Ciphertext: ciphertext}, nil
+}
+
+// Decrypt opens a value sealed by Encrypt with the same context
+func (k *Keyring) Decrypt(envelope Envelope, context string) ([]byte, error) {
+	key, ok := k.keys[envelope.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, envelope.KeyID)
+	}
+
+	if len(envelope.WrappedKey) < 12 {
+		return nil, errors.New("wrapped data key is too short")
+	}
+	dataKey, err := open(key, envelope.WrappedKey[:12], envelope.WrappedKey[12:], []byte(envelope.KeyID))
+	if err != nil {
+		return nil, err
+	}
+
+	return open(dataKey, envelope.Nonce, envelope.Ciphertext, []byte(context))
+}
+
+// NeedsRotation tells whether a value was encrypted with a key other than the active one
+func (k *Keyring) NeedsRotation(envelope Envelope) bool {
+	return envelope.KeyID != k.activeKeyID
+}
+
+// seal encrypts with AES-GCM. Without a nonce, a random one is generated and
+// prepended to the result.
+func seal(key []byte, nonce []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if nonce != nil {
+		return aead.Seal(nil, nonce, plaintext, additionalData), nil
+	}
+
+	nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts with AES-GCM
+func open(key []byte, nonce []byte, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,179 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Keys of the test keyrings, 32 bytes each
+var (
+	oldKey   = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, keySize))
+	newKey   = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, keySize))
+	indexKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, keySize))
+)
+
+// writeKeyring writes a keyring file and loads it
+func writeKeyring(t *testing.T, content string) (*Keyring, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return LoadKeyring(path)
+}
+
+func mustKeyring(t *testing.T, activeKey string, keys string) *Keyring {
+	t.Helper()
+	keyring, err := writeKeyring(t, `{"activeKey":"`+activeKey+`","keys":{`+keys+`},"indexKey":"`+indexKey+`"}`)
+	if err != nil {
+		t.Fatalf("LoadKeyring() error = %v", err)
+	}
+	return keyring
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	keyring := mustKeyring(t, "old", `"old":"`+oldKey+`"`)
+	plaintext := []byte("+33 6 12 34 56 78")
+	const context = "users/6523e1f0a1b2c3d4e5f60718/phoneNumber"
+
+	envelope, err := keyring.Encrypt(plaintext, context)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if envelope.KeyID != "old" {
+		t.Errorf("KeyID = %q, want old", envelope.KeyID)
+	}
+	if bytes.Contains(envelope.Ciphertext, plaintext) {
+		t.Error("ciphertext contains the plaintext")
+	}
+
+	got, err := keyring.Decrypt(envelope, context)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("Decrypt() = %q, want %q", got, plaintext)
+	}
+
+	// Every value gets its own data key and nonce
+	again, err := keyring.Encrypt(plaintext, context)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if bytes.Equal(again.Ciphertext, envelope.Ciphertext) || bytes.Equal(again.WrappedKey, envelope.WrappedKey) {
+		t.Error("encrypting the same value twice gave the same envelope")
+	}
+}
+
+func TestDecryptRejectsOtherContextsAndTampering(t *testing.T) {
+	keyring := mustKeyring(t, "old", `"old":"`+oldKey+`"`)
+	envelope, err := keyring.Encrypt([]byte("ada@example.com"), "users/a/email")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	tamper := func(change func(*Envelope)) Envelope {
+		copied := Envelope{
+			KeyID:      envelope.KeyID,
+			WrappedKey: bytes.Clone(envelope.WrappedKey),
+			Nonce:      bytes.Clone(envelope.Nonce),
+			Ciphertext: bytes.Clone(envelope.Ciphertext),
+		}
+		change(&copied)
+		return copied
+	}
+
+	tests := []struct {
+		name     string
+		envelope Envelope
+		context  string
+	}{
+		{"other field", envelope, "users/a/phoneNumber"},
+		{"other user", envelope, "users/b/email"},
+		{"no context", envelope, ""},
+		{"tampered ciphertext", tamper(func(e *Envelope) { e.Ciphertext[0] ^= 1 }), "users/a/email"},
+		{"tampered nonce", tamper(func(e *Envelope) { e.Nonce[0] ^= 1 }), "users/a/email"},
+		{"tampered data key", tamper(func(e *Envelope) { e.WrappedKey[len(e.WrappedKey)-1] ^= 1 }), "users/a/email"},
+		{"truncated data key", tamper(func(e *Envelope) { e.WrappedKey = e.WrappedKey[:8] }), "users/a/email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := keyring.Decrypt(tt.envelope, tt.context); err == nil {
+				t.Error("Decrypt() succeeded")
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	before := mustKeyring(t, "old", `"old":"`+oldKey+`"`)
+	envelope, err := before.Encrypt([]byte("Lyon"), "users/a/address")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	// Values of the retired key still open while it stays in the keyring
+	after := mustKeyring(t, "new", `"old":"`+oldKey+`","new":"`+newKey+`"`)
+	if !after.NeedsRotation(envelope) {
+		t.Error("NeedsRotation() = false for a value of the retired key")
+	}
+	if got, err := after.Decrypt(envelope, "users/a/address"); err != nil || string(got) != "Lyon" {
+		t.Errorf("Decrypt() = %q, %v, want Lyon", got, err)
+	}
+
+	rotated, err := after.Encrypt([]byte("Lyon"), "users/a/address")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if rotated.KeyID != "new" || after.NeedsRotation(rotated) {
+		t.Errorf("KeyID = %q, NeedsRotation() = %v, want the active key", rotated.KeyID, after.NeedsRotation(rotated))
+	}
+
+	// Once the retired key is removed, its values are reported as such
+	removed := mustKeyring(t, "new", `"new":"`+newKey+`"`)
+	if _, err := removed.Decrypt(envelope, "users/a/address"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt() error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	keyring := mustKeyring(t, "old", `"old":"`+oldKey+`"`)
+
+	index := keyring.BlindIndex("ada@example.com")
+	if index != keyring.BlindIndex("ada@example.com") {
+		t.Error("BlindIndex() differs for the same value")
+	}
+	if index == keyring.BlindIndex("bob@example.com") {
+		t.Error("BlindIndex() is the same for different values")
+	}
+	if strings.Contains(index, "ada") || len(index) != 64 {
+		t.Errorf("BlindIndex() = %q, want a hex SHA-256 HMAC", index)
+	}
+}
+
+func TestLoadKeyringErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"malformed", `{"activeKey":`},
+		{"active key missing", `{"activeKey":"new","keys":{"old":"` + oldKey + `"},"indexKey":"` + indexKey + `"}`},
+		{"short key", `{"activeKey":"old","keys":{"old":"` + base64.StdEncoding.EncodeToString([]byte("short")) + `"},"indexKey":"` + indexKey + `"}`},
+		{"key not base64", `{"activeKey":"old","keys":{"old":"not base64!"},"indexKey":"` + indexKey + `"}`},
+		{"index key missing", `{"activeKey":"old","keys":{"old":"` + oldKey + `"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := writeKeyring(t, tt.content); err == nil {
+				t.Error("LoadKeyring() accepted the keyring")
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package fieldcrypt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Size in bytes of every key of the keyring (AES-256)
+const keySize = 32
+
+// Keyring holds the key encryption keys used to wrap the per-value data keys,
+// and the key used to compute blind indexes. It stands in for a KMS: the keys
+// are read from a local file that must be kept out of the database backups.
+type Keyring struct {
+	activeKeyID string
+	keys        map[string][]byte
+	indexKey    []byte
+}
+
+// keyringFile is the JSON layout of a keyring file. Keys are base64 encoded.
+//
+//	{
+//	  "activeKey": "2026-10",
+//	  "keys": {"2026-10": "...", "2026-04": "..."},
+//	  "indexKey": "..."
+//	}
+//
+// To rotate, add a new key, make it the active one and run the re-encryption
+// job; retired keys can be removed once the job has finished. The index key
+// cannot be rotated without recomputing every blind index.
+type keyringFile struct {
+	ActiveKey string            `json:"activeKey"`
+	Keys      map[string]string `json:"keys"`
+	IndexKey  string            `json:"indexKey"`
+}
+
+// LoadKeyring reads a keyring file
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid keyring file: %w", err)
+	}
+
+	keyring := &Keyring{activeKeyID: file.ActiveKey, keys: map[string][]byte{}}
+	for id, encoded := range file.Keys {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		keyring.keys[id] = key
+	}
+	if _, ok := keyring.keys[file.ActiveKey]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the keyring", file.ActiveKey)
+	}
+
+	keyring.indexKey, err = decodeKey(file.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index key: %w", err)
+	}
+
+	return keyring, nil
+}
+
+// ActiveKeyID returns the ID of the key new values are encrypted with
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeKeyID
+}
+
+// BlindIndex returns a keyed hash of a value, so that equal values can be
+// looked up without storing them in clear text
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, errors.New("key must be 32 bytes")
+	}
+	return key, nil
+}
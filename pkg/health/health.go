@@ -0,0 +1,130 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status of a check or of a whole probe
+type Status string
+
+const (
+	Up       Status = "up"
+	Degraded Status = "degraded" // a non-critical check is down
+	Down     Status = "down"     // a critical check is down
+)
+
+// Check returns an error if the dependency it checks is unusable
+type Check func(ctx context.Context) error
+
+// Checker is a named check. A critical checker being down makes the whole probe fail.
+type Checker struct {
+	Name     string
+	Critical bool
+	Timeout  time.Duration
+	Check    Check
+}
+
+// Result is the outcome of one checker
+type Result struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of all the checkers of a probe
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Registry holds the checkers of a probe, such as liveness or readiness
+type Registry struct {
+	mu       sync.RWMutex
+	checkers []Checker
+}
+
+// NewRegistry creates an empty registry, which always reports up
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a checker to the probe
+func (r *Registry) Register(checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, checker)
+}
+
+// Run runs every checker concurrently, each within its own timeout
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := append([]Checker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = run(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	report := Report{Status: Up, Checks: results}
+	for _, result := range results {
+		if result.Status == Down {
+			if result.Critical {
+				report.Status = Down
+			} else if report.Status == Up {
+				report.Status = Degraded
+			}
+		}
+	}
+	return report
+}
+
+// run runs a single checker, treating a check that outlives its timeout as down
+func run(ctx context.Context, checker Checker) Result {
+	if checker.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, checker.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("check panicked: %v", recovered)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{
+		Name:      checker.Name,
+		Status:    Up,
+		Critical:  checker.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = Down
+		result.Error = err.Error()
+	}
+	return result
+}
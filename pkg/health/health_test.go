@@ -0,0 +1,160 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func up(ctx context.Context) error { return nil }
+
+func down(ctx context.Context) error { return errors.New("unreachable") }
+
+func TestRunStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		checkers []Checker
+		want     Status
+	}{
+		{"no checkers", nil, Up},
+		{"all up", []Checker{
+			{Name: "mongo", Critical: true, Check: up},
+			{Name: "metrics", Check: up},
+		}, Up},
+		{"non-critical down", []Checker{
+			{Name: "mongo", Critical: true, Check: up},
+			{Name: "metrics", Check: down},
+		}, Degraded},
+		{"critical down", []Checker{
+			{Name: "mongo", Critical: true, Check: down},
+			{Name: "metrics", Check: up},
+		}, Down},
+		{"critical and non-critical down", []Checker{
+			{Name: "metrics", Check: down},
+			{Name: "mongo", Critical: true, Check: down},
+		}, Down},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			for _, checker := range tt.checkers {
+				registry.Register(checker)
+			}
+
+			report := registry.Run(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("status = %s, want %s", report.Status, tt.want)
+			}
+			if len(report.Checks) != len(tt.checkers) {
+				t.Fatalf("got %d results, want %d", len(report.Checks), len(tt.checkers))
+			}
+			// Results keep the order the checkers were registered in
+			for i, result := range report.Checks {
+				if result.Name != tt.checkers[i].Name || result.Critical != tt.checkers[i].Critical {
+					t.Errorf("result %d = %+v, want checker %s", i, result, tt.checkers[i].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestRunResult(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(Checker{Name: "mongo", Critical: true, Check: down})
+
+	result := registry.Run(context.Background()).Checks[0]
+	if result.Status != Down || result.Error != "unreachable" {
+		t.Errorf("result = %+v, want down with the error of the check", result)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	// One check gives up when its context does, the other ignores it
+	release := make(chan struct{})
+	defer close(release)
+
+	registry := NewRegistry()
+	registry.Register(Checker{Name: "cancellable", Timeout: 20 * time.Millisecond, Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	registry.Register(Checker{Name: "stuck", Critical: true, Timeout: 20 * time.Millisecond, Check: func(ctx context.Context) error {
+		<-release
+		return nil
+	}})
+	registry.Register(Checker{Name: "fast", Timeout: time.Second, Check: up})
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Run() took %v, want it bounded by the timeouts", elapsed)
+	}
+
+	if report.Status != Down {
+		t.Errorf("status = %s, want %s", report.Status, Down)
+	}
+	for _, result := range report.Checks[:2] {
+		if result.Status != Down || !strings.Contains(result.Error, context.DeadlineExceeded.Error()) {
+			t.Errorf("%s = %+v, want down past its deadline", result.Name, result)
+		}
+	}
+	if result := report.Checks[2]; result.Status != Up {
+		t.Errorf("%s = %+v, want up within its timeout", result.Name, result)
+	}
+}
+
+func TestRunPanic(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(Checker{Name: "metrics", Check: func(ctx context.Context) error {
+		panic("nil collector")
+	}})
+	registry.Register(Checker{Name: "mongo", Critical: true, Check: up})
+
+	report := registry.Run(context.Background())
+	if report.Status != Degraded {
+		t.Errorf("status = %s, want %s", report.Status, Degraded)
+	}
+	if result := report.Checks[0]; result.Status != Down || !strings.Contains(result.Error, "nil collector") {
+		t.Errorf("result = %+v, want down with the panic", result)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	tests := []struct {
+		name    string
+		age     time.Duration
+		wantErr bool
+	}{
+		{"fresh", 0, false},
+		{"within the maximum age", 30 * time.Second, false},
+		{"stale", 2 * time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heartbeat := NewHeartbeat(time.Minute)
+			heartbeat.last.Store(time.Now().Add(-tt.age).UnixNano())
+
+			err := heartbeat.Check(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHeartbeatBeat(t *testing.T) {
+	heartbeat := NewHeartbeat(time.Minute)
+	if err := heartbeat.Check(context.Background()); err != nil {
+		t.Errorf("Check() of a new heartbeat = %v, want nil", err)
+	}
+
+	heartbeat.last.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	heartbeat.Beat()
+	if err := heartbeat.Check(context.Background()); err != nil {
+		t.Errorf("Check() after a beat = %v, want nil", err)
+	}
+}
@@ -0,0 +1,37 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat tracks that a background worker is still making progress. The
+// worker calls Beat on every iteration; the check fails once no beat was seen
+// for longer than the maximum age.
+type Heartbeat struct {
+	maxAge time.Duration
+	last   atomic.Int64 // Unix nanoseconds of the last beat
+}
+
+// NewHeartbeat creates a heartbeat that counts as fresh until maxAge has passed
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	heartbeat := &Heartbeat{maxAge: maxAge}
+	heartbeat.Beat()
+	return heartbeat
+}
+
+// Beat records that the worker made progress
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// Check fails if the worker did not beat recently enough
+func (h *Heartbeat) Check(ctx context.Context) error {
+	age := time.Since(time.Unix(0, h.last.Load()))
+	if age > h.maxAge {
+		return fmt.Errorf("no heartbeat for %s", age.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Package ical writes calendars in the iCalendar format of RFC 5545, with the
+// event properties that meetings need.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType of iCalendar documents
+const ContentType = "text/calendar; charset=utf-8"
+
+// Format of times in UTC
+const utcFormat = "20060102T150405Z"
+
+// Longest content line, in octets, before it is folded
+const maxLineOctets = 75
+
+// Status of an event
+type Status string
+
+const (
+	Confirmed Status = "CONFIRMED"
+	Cancelled Status = "CANCELLED"
+)
+
+// Geo is a position in degrees
+type Geo struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Event is a VEVENT. Calendar clients recognize an updated event by its UID
+// and a higher Sequence.
+type Event struct {
+	UID      string
+	Start    time.Time
+	End      time.Time
+	Modified time.Time // last change of the event
+	Sequence int64     // revision of the event, incremented on every change
+	Summary  string
+	Location string // written only if not empty
+	Geo      *Geo   // written only if not nil
+	Status   Status
+}
+
+// Calendar is a VCALENDAR of events
+type Calendar struct {
+	ProdID          string        // identifies the product that created the calendar
+	Name            string        // shown by clients that subscribe to the calendar, if not empty
+	RefreshInterval time.Duration // how often subscribed clients should fetch the calendar again, if positive
+	Events          []Event
+}
+
+// Encode writes the calendar to w, with CRLF line endings and long lines folded
+func (c Calendar) Encode(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	write := func(name string, value string) {
+		writeLine(out, name+":"+value)
+	}
+
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", escapeText(c.ProdID))
+	write("CALSCALE", "GREGORIAN")
+	if c.Name != "" {
+		write("NAME", escapeText(c.Name))
+		write("X-WR-CALNAME", escapeText(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		write("REFRESH-INTERVAL;VALUE=DURATION", formatDuration(c.RefreshInterval))
+		write("X-PUBLISHED-TTL", formatDuration(c.RefreshInterval))
+	}
+
+	for _, event := range c.Events {
+		write("BEGIN", "VEVENT")
+		write("UID", escapeText(event.UID))
+		write("DTSTAMP", formatTime(event.Modified))
+		write("LAST-MODIFIED", formatTime(event.Modified))
+		write("SEQUENCE", strconv.FormatInt(event.Sequence, 10))
+		write("DTSTART", formatTime(event.Start))
+		write("DTEND", formatTime(event.End))
+		write("SUMMARY", escapeText(event.Summary))
+		if event.Location != "" {
+			write("LOCATION", escapeText(event.Location))
+		}
+		if event.Geo != nil {
+			write("GEO", strconv.FormatFloat(event.Geo.Latitude, 'f', 6, 64)+";"+strconv.FormatFloat(event.Geo.Longitude, 'f', 6, 64))
+		}
+		write("STATUS", string(event.Status))
+		write("TRANSP", "OPAQUE")
+		write("END", "VEVENT")
+	}
+
+	write("END", "VCALENDAR")
+	return out.Flush()
+}
+
+// writeLine writes a content line, folding it into lines of at most 75 octets that
+// continue with a space. Multi-byte characters are never split.
+func writeLine(out *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		out.WriteString(line[:cut])
+		out.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of continuation lines counts towards their length
+		limit = maxLineOctets - 1
+	}
+	out.WriteString(line)
+	out.WriteString("\r\n")
+}
+
+// Characters with a special meaning in TEXT values, and line breaks
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeText escapes a TEXT value
+func escapeText(value string) string {
+	return textEscaper.Replace(value)
+}
+
+// formatTime writes a time in UTC, which every client understands without a VTIMEZONE
+func formatTime(t time.Time) string {
+	return t.UTC().Format(utcFormat)
+}
+
+// formatDuration writes a duration such as PT1H30M, to the second
+func formatDuration(d time.Duration) string {
+	seconds := int64(d / time.Second)
+	hours, minutes, seconds := seconds/3600, seconds/60%60, seconds%60
+
+	value := "PT"
+	if hours > 0 {
+		value += fmt.Sprintf("%dH", hours)
+	}
+	if minutes > 0 {
+		value += fmt.Sprintf("%dM", minutes)
+	}
+	if seconds > 0 || value == "PT" {
+		value += fmt.Sprintf("%dS", seconds)
+	}
+	return value
+}
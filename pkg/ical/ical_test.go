@@ -0,0 +1,176 @@
+package ical
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Tea with Ada", "Tea with Ada"},
+		{`C:\Users`, `C:\\Users`},
+		{"Shopping; pharmacy", `Shopping\; pharmacy`},
+		{"12 rue de la Paix, Paris", `12 rue de la Paix\, Paris`},
+		{"first\r\nsecond\nthird\rfourth", `first\nsecond\nthird\nfourth`},
+		{`\n is not a line break`, `\\n is not a line break`},
+		{"Café à 10h", "Café à 10h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := escapeText(tt.value); got != tt.want {
+				t.Errorf("escapeText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteLineFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		lines int
+	}{
+		{"short", "SUMMARY:Tea", 1},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67), 1},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68), 2},
+		{"continuation lines hold 74 octets", "SUMMARY:" + strings.Repeat("a", 67+74), 2},
+		{"one more octet", "SUMMARY:" + strings.Repeat("a", 67+75), 3},
+		{"two byte characters", "SUMMARY:" + strings.Repeat("é", 100), 3},
+		{"three byte characters", "SUMMARY:" + strings.Repeat("€", 60), 3},
+		{"four byte characters on the limit", "SUMMARY:" + strings.Repeat("a", 66) + strings.Repeat("😀", 10), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			out := bufio.NewWriter(&b)
+			writeLine(out, tt.line)
+			out.Flush()
+
+			written := b.String()
+			if !strings.HasSuffix(written, "\r\n") {
+				t.Fatalf("line %q does not end with CRLF", written)
+			}
+			lines := strings.Split(strings.TrimSuffix(written, "\r\n"), "\r\n")
+			if len(lines) != tt.lines {
+				t.Errorf("folded into %d lines, want %d: %q", len(lines), tt.lines, lines)
+			}
+			for i, line := range lines {
+				if len(line) > maxLineOctets {
+					t.Errorf("line %d has %d octets", i, len(line))
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space", i)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a character: %q", i, line)
+				}
+			}
+
+			// Unfolding gives back the content line
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(written, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded line = %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{0, "PT0S"},
+		{45 * time.Second, "PT45S"},
+		{time.Hour, "PT1H"},
+		{90 * time.Minute, "PT1H30M"},
+		{26*time.Hour + 5*time.Second, "PT26H5S"},
+		{1500 * time.Millisecond, "PT1S"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatDuration(tt.duration); got != tt.want {
+				t.Errorf("formatDuration(%v) = %q, want %q", tt.duration, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	paris := time.FixedZone("CEST", 2*60*60)
+	calendar := Calendar{
+		ProdID:          "-//NeighborGuard//Meetings//EN",
+		Name:            "Visits, Ada",
+		RefreshInterval: time.Hour,
+		Events: []Event{{
+			UID:      "6523e1f0a1b2c3d4e5f60718@neighborguard",
+			Start:    time.Date(2026, time.October, 20, 10, 0, 0, 0, paris),
+			End:      time.Date(2026, time.October, 20, 11, 0, 0, 0, paris),
+			Modified: time.Date(2026, time.October, 18, 9, 30, 0, 0, time.UTC),
+			Sequence: 2,
+			Summary:  "Groceries; pharmacy",
+			Location: "12 rue de la Paix, Paris",
+			Geo:      &Geo{Latitude: 48.8686, Longitude: 2.3317},
+			Status:   Cancelled,
+		}},
+	}
+
+	var b strings.Builder
+	if err := calendar.Encode(&b); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//NeighborGuard//Meetings//EN",
+		"CALSCALE:GREGORIAN",
+		`NAME:Visits\, Ada`,
+		`X-WR-CALNAME:Visits\, Ada`,
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H",
+		"X-PUBLISHED-TTL:PT1H",
+		"BEGIN:VEVENT",
+		"UID:6523e1f0a1b2c3d4e5f60718@neighborguard",
+		"DTSTAMP:20261018T093000Z",
+		"LAST-MODIFIED:20261018T093000Z",
+		"SEQUENCE:2",
+		"DTSTART:20261020T080000Z",
+		"DTEND:20261020T090000Z",
+		`SUMMARY:Groceries\; pharmacy`,
+		`LOCATION:12 rue de la Paix\, Paris`,
+		"GEO:48.868600;2.331700",
+		"STATUS:CANCELLED",
+		"TRANSP:OPAQUE",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n") + "\r\n"
+	if b.String() != want {
+		t.Errorf("Encode() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestEncodeOptionalProperties(t *testing.T) {
+	calendar := Calendar{
+		ProdID: "-//NeighborGuard//Meetings//EN",
+		Events: []Event{{UID: "a", Summary: "Tea", Status: Confirmed}},
+	}
+
+	var b strings.Builder
+	if err := calendar.Encode(&b); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	for _, name := range []string{"NAME:", "X-WR-CALNAME:", "REFRESH-INTERVAL", "X-PUBLISHED-TTL:", "LOCATION:", "GEO:"} {
+		if strings.Contains(b.String(), "\r\n"+name) {
+			t.Errorf("Encode() wrote %s although it is not set", name)
+		}
+	}
+}
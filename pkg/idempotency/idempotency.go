@@ -0,0 +1,40 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Failures of requests sent with an idempotency key
+var (
+	ErrInvalidKey = errors.New("idempotency key must be 1 to 255 visible ASCII characters")
+	ErrKeyReused  = errors.New("idempotency key was used for a different request")
+	ErrInProgress = errors.New("request with this idempotency key is still in progress")
+)
+
+// Record is the outcome of the first request sent with an idempotency key
+type Record struct {
+	Scope       string // route and user the key belongs to
+	Key         string
+	RequestHash string // of the route and body of the first request
+	Completed   bool   // false while the first request is being served
+	Status      int
+	Header      http.Header // response headers that are replayed
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// Store keeps the records of idempotency keys until they expire
+type Store interface {
+	// Reserve claims the key of a record for a new request. If the key is already
+	// claimed, and its record did not expire, that record is returned instead.
+	Reserve(ctx context.Context, record Record) (*Record, error)
+
+	// Complete stores the response of the request that claimed the key
+	Complete(ctx context.Context, record Record) error
+
+	// Release frees the key of a request that failed, so it can be retried
+	Release(ctx context.Context, scope string, key string) error
+}
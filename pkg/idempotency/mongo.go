@@ -0,0 +1,146 @@
+package idempotency
+
+import (
+	"context"
+	"neighborguard/pkg/fieldcrypt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Context the response bodies are encrypted with
+const bodyContext = "idempotency.body"
+
+// MongoStore keeps the records in a MongoDB collection, shared by every instance.
+// Records are removed by a TTL index once they expire.
+type MongoStore struct {
+	collection *mongo.Collection
+	keyring    *fieldcrypt.Keyring
+}
+
+// document is the stored form of a record. Responses may hold personal data, such
+// as a created user, so their body is encrypted when a keyring is configured.
+type document struct {
+	ID          string               `bson:"_id"`
+	RequestHash string               `bson:"requestHash"`
+	Completed   bool                 `bson:"completed"`
+	Status      int                  `bson:"status,omitempty"`
+	Header      http.Header          `bson:"header,omitempty"`
+	Body        []byte               `bson:"body,omitempty"`
+	SealedBody  *fieldcrypt.Envelope `bson:"sealedBody,omitempty"`
+	ExpiresAt   time.Time            `bson:"expiresAt"`
+}
+
+// NewMongoStore creates a store in the collection, making sure its TTL index exists.
+// Response bodies are encrypted with the keyring unless it is nil.
+func NewMongoStore(ctx context.Context, collection *mongo.Collection, keyring *fieldcrypt.Keyring) (*MongoStore, error) {
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoStore{collection: collection, keyring: keyring}, nil
+}
+
+func documentID(scope string, key string) string {
+	return scope + " " + key
+}
+
+// Reserve inserts the record, or returns the one already stored under its key
+func (s *MongoStore) Reserve(ctx context.Context, record Record) (*Record, error) {
+	doc := document{ID: documentID(record.Scope, record.Key), RequestHash: record.RequestHash, ExpiresAt: record.ExpiresAt}
+
+	_, err := s.collection.InsertOne(ctx, doc)
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	var existing document
+	err = s.collection.FindOne(ctx, bson.M{"_id": doc.ID}).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		// Expired and removed in the meantime
+		return s.Reserve(ctx, record)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The TTL monitor only runs every minute, so expired records may still be there.
+	// Taking over one of them must not race with another request doing the same.
+	if !existing.ExpiresAt.After(time.Now()) {
+		result, err := s.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID, "expiresAt": existing.ExpiresAt}, doc)
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 1 {
+			return nil, nil
+		}
+		return nil, ErrInProgress
+	}
+
+	return s.open(record.Scope, record.Key, existing)
+}
+
+// Complete stores the response of the request that reserved the key
+func (s *MongoStore) Complete(ctx context.Context, record Record) error {
+	update := bson.M{
+		"completed": true,
+		"status":    record.Status,
+		"header":    record.Header,
+		"expiresAt": record.ExpiresAt,
+	}
+	if s.keyring != nil {
+		sealed, err := s.keyring.Encrypt(record.Body, bodyContext)
+		if err != nil {
+			return err
+		}
+		update["sealedBody"] = sealed
+	} else {
+		update["body"] = record.Body
+	}
+
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": documentID(record.Scope, record.Key), "requestHash": record.RequestHash},
+		bson.M{"$set": update},
+	)
+	return err
+}
+
+// Release removes the record of a key that has not completed
+func (s *MongoStore) Release(ctx context.Context, scope string, key string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": documentID(scope, key), "completed": false})
+	return err
+}
+
+// open turns a stored document back into a record, decrypting its response body
+func (s *MongoStore) open(scope string, key string, doc document) (*Record, error) {
+	record := &Record{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: doc.RequestHash,
+		Completed:   doc.Completed,
+		Status:      doc.Status,
+		Header:      doc.Header,
+		Body:        doc.Body,
+		ExpiresAt:   doc.ExpiresAt,
+	}
+	if doc.SealedBody != nil {
+		if s.keyring == nil {
+			return nil, fieldcrypt.ErrUnknownKey
+		}
+		body, err := s.keyring.Decrypt(*doc.SealedBody, bodyContext)
+		if err != nil {
+			return nil, err
+		}
+		record.Body = body
+	}
+	return record, nil
+}
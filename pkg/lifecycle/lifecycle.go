@@ -0,0 +1,148 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Manager runs the HTTP server and the background workers, and shuts them down
+// in order: readiness fails first, then the server stops accepting connections
+// and drains in-flight requests, then the workers are stopped, and only then
+// are shared resources such as the database connection closed.
+type Manager struct {
+	server        *http.Server
+	drainTimeout  time.Duration // how long in-flight requests and workers get to finish
+	shutdownDelay time.Duration // how long readiness fails before the server stops accepting connections
+
+	shuttingDown  atomic.Bool
+	workers       sync.WaitGroup
+	workerContext context.Context
+	stopWorkers   context.CancelFunc
+	closers       []closer
+}
+
+type closer struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// New creates a manager for the given server
+func New(server *http.Server, drainTimeout time.Duration, shutdownDelay time.Duration) *Manager {
+	workerContext, stopWorkers := context.WithCancel(context.Background())
+	return &Manager{
+		server:        server,
+		drainTimeout:  drainTimeout,
+		shutdownDelay: shutdownDelay,
+		workerContext: workerContext,
+		stopWorkers:   stopWorkers,
+	}
+}
+
+// Go starts a background worker. Its context is cancelled once the server has
+// drained, and the worker must return promptly after that.
+func (m *Manager) Go(name string, run func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		run(m.workerContext)
+		slog.Info("Worker stopped", "worker", name)
+	}()
+}
+
+// OnShutdown registers a resource to close after the server and the workers
+// have stopped. Resources are closed in the reverse order of registration.
+func (m *Manager) OnShutdown(name string, close func(ctx context.Context) error) {
+	m.closers = append(m.closers, closer{name: name, close: close})
+}
+
+// ShuttingDown tells whether shutdown has started, in which case the instance
+// should no longer receive traffic
+func (m *Manager) ShuttingDown() bool {
+	return m.shuttingDown.Load()
+}
+
+// Run serves HTTP until SIGINT or SIGTERM is received or the server fails,
+// then shuts everything down
+func (m *Manager) Run() error {
+	serverErr := make(chan error, 1)
+	go func() {
+		if m.server.TLSConfig != nil {
+			serverErr <- m.server.ListenAndServeTLS("", "")
+		} else {
+			serverErr <- m.server.ListenAndServe()
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	var runErr error
+	select {
+	case sig := <-quit:
+		slog.Info("Shutting down", "signal", sig.String())
+	case err := <-serverErr:
+		runErr = err
+		slog.Error("Server failed, shutting down", "error", err)
+	}
+
+	return errors.Join(runErr, m.Shutdown())
+}
+
+// Shutdown stops the server, the workers and the registered resources in order
+func (m *Manager) Shutdown() error {
+	m.shuttingDown.Store(true)
+
+	// Give load balancers time to notice the failing readiness
+	if m.shutdownDelay > 0 {
+		time.Sleep(m.shutdownDelay)
+	}
+
+	var errs []error
+
+	// Stop accepting connections and wait for in-flight requests
+	ctx, cancel := context.WithTimeout(context.Background(), m.drainTimeout)
+	defer cancel()
+	if err := m.server.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+		slog.Warn("Requests still in flight were cut off", "drainTimeout", m.drainTimeout, "error", err)
+	}
+
+	// Stop the workers, which may still use the resources below
+	m.stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(stopped)
+	}()
+	workersCtx, cancelWorkers := context.WithTimeout(context.Background(), m.drainTimeout)
+	defer cancelWorkers()
+	select {
+	case <-stopped:
+	case <-workersCtx.Done():
+		errs = append(errs, errors.New("workers did not stop in time"))
+		slog.Warn("Workers did not stop in time", "drainTimeout", m.drainTimeout)
+	}
+
+	// Close shared resources last
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		closer := m.closers[i]
+		closeCtx, cancelClose := context.WithTimeout(context.Background(), m.drainTimeout)
+		if err := closer.close(closeCtx); err != nil {
+			errs = append(errs, err)
+			slog.Error("Failed to close", "resource", closer.name, "error", err)
+		}
+		cancelClose()
+	}
+
+	slog.Info("Shutdown complete")
+	return errors.Join(errs...)
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level of the default logger, which can be changed while the server runs
+var level = new(slog.LevelVar)
+
+// ContextAttrs returns the attributes carried by a context that every log
+// line written with it should include, such as the request ID
+type ContextAttrs func(ctx context.Context) []slog.Attr
+
+// Setup installs the default logger. Records are written as JSON or text,
+// enriched with the attributes of their context, and redacted of personal data.
+// The standard log package is routed through the same logger.
+func Setup(format string, initial slog.Level, contextAttrs ContextAttrs) {
+	slog.SetDefault(slog.New(newHandler(os.Stderr, format, contextAttrs)))
+	level.Set(initial)
+}
+
+func newHandler(w io.Writer, format string, contextAttrs ContextAttrs) slog.Handler {
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if strings.EqualFold(format, "text") {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+	return &redactingHandler{next: handler, contextAttrs: contextAttrs}
+}
+
+// Level returns the current level of the default logger
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the level of the default logger
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// ParseLevel reads a level name such as debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	var l slog.Level
+	err := l.UnmarshalText([]byte(name))
+	return l, err
+}
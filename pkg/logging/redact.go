@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Replacement of values that must never be logged
+const redacted = "[REDACTED]"
+
+// Attributes whose value is always personal data, compared case-insensitively
+var sensitiveKeys = map[string]bool{
+	"email":         true,
+	"phone":         true,
+	"phonenumber":   true,
+	"address":       true,
+	"lonlat":        true,
+	"latitude":      true,
+	"longitude":     true,
+	"lat":           true,
+	"lon":           true,
+	"password":      true,
+	"authorization": true,
+}
+
+// Personal data recognized inside free text, such as error messages and paths
+var (
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+-]+(@|%40)[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phonePattern      = regexp.MustCompile(`(?:\+\d|\b0)(?:[\s-]?\d){7,14}\b`)
+	coordinatePattern = regexp.MustCompile(`-?\b\d{1,3}\.\d{4,}\b`)
+)
+
+// Redact replaces the emails, phone numbers and coordinates found in a text
+func Redact(text string) string {
+	text = emailPattern.ReplaceAllString(text, "[EMAIL]")
+	text = coordinatePattern.ReplaceAllString(text, "[COORDINATE]")
+	text = phonePattern.ReplaceAllString(text, "[PHONE]")
+	return text
+}
+
+// redactingHandler adds the attributes of the context to every record and
+// removes personal data from messages and attributes before they are written
+type redactingHandler struct {
+	next         slog.Handler
+	contextAttrs ContextAttrs
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	clean := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	if h.contextAttrs != nil && ctx != nil {
+		clean.AddAttrs(h.contextAttrs(ctx)...)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		clean.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		clean = append(clean, redactAttr(attr))
+	}
+	return &redactingHandler{next: h.next.WithAttrs(clean), contextAttrs: h.contextAttrs}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name), contextAttrs: h.contextAttrs}
+}
+
+// redactAttr hides sensitive attributes entirely and redacts the text of the others
+func redactAttr(attr slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, redacted)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		clean := make([]any, 0, len(group))
+		for _, member := range group {
+			clean = append(clean, redactAttr(member))
+		}
+		return slog.Group(attr.Key, clean...)
+	case slog.KindAny:
+		// Structures and errors may embed personal data, only their redacted text is kept
+		return slog.String(attr.Key, Redact(fmt.Sprintf("%+v", value.Any())))
+	default:
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
+}
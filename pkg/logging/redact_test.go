@@ -0,0 +1,174 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+// contextRequestID adds the request ID stored in the context to every record
+func contextRequestID(ctx context.Context) []slog.Attr {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return []slog.Attr{slog.String("requestId", id)}
+	}
+	return nil
+}
+
+// logLine writes a record through the redacting handler and returns the JSON it produced
+func logLine(t *testing.T, log func(logger *slog.Logger)) map[string]any {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	log(slog.New(newHandler(&buffer, "json", contextRequestID)))
+
+	var line map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &line); err != nil {
+		t.Fatalf("log line %q is not JSON: %v", buffer.String(), err)
+	}
+	return line
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"email", "user dana@example.com signed in", "user [EMAIL] signed in"},
+		{"url encoded email", "GET /v1/users?email=dana%40example.com", "GET /v1/users?email=[EMAIL]"},
+		{"international phone number", "call +972 50-123-4567 now", "call [PHONE] now"},
+		{"local phone number", "call 050-1234567 now", "call [PHONE] now"},
+		{"coordinates", "at 32.0853,34.7818", "at [COORDINATE],[COORDINATE]"},
+		{"negative coordinates", "at -33.86882, 151.20929", "at [COORDINATE], [COORDINATE]"},
+		{"object ID and duration", "meeting 65f1c2a3b4d5e6f708192a3b took 12.5ms", "meeting 65f1c2a3b4d5e6f708192a3b took 12.5ms"},
+		{"timestamp", "at 2026-10-18T17:55:58Z with version 3", "at 2026-10-18T17:55:58Z with version 3"},
+		{"port", "listening on port 8080", "listening on port 8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.text); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactingHandler(t *testing.T) {
+	tests := []struct {
+		name  string
+		log   func(logger *slog.Logger)
+		field []string // path of the field to check in the JSON line
+		want  any
+	}{
+		{
+			"message",
+			func(logger *slog.Logger) { logger.Info("Created user dana@example.com") },
+			[]string{"msg"},
+			"Created user [EMAIL]",
+		},
+		{
+			"string attribute",
+			func(logger *slog.Logger) { logger.Info("Lookup", "query", "phone 050-1234567") },
+			[]string{"query"},
+			"phone [PHONE]",
+		},
+		{
+			"error attribute",
+			func(logger *slog.Logger) {
+				logger.Info("Failed", "error", errors.New("duplicate key dana@example.com"))
+			},
+			[]string{"error"},
+			"duplicate key [EMAIL]",
+		},
+		{
+			"nested group",
+			func(logger *slog.Logger) {
+				logger.Info("Request", slog.Group("http", slog.Group("url", slog.String("path", "/v1/users?lat=32.0853"))))
+			},
+			[]string{"http", "url", "path"},
+			"/v1/users?lat=[COORDINATE]",
+		},
+		{
+			"sensitive key",
+			func(logger *slog.Logger) { logger.Info("Created", "email", "dana@example.com") },
+			[]string{"email"},
+			redacted,
+		},
+		{
+			"sensitive key in another case",
+			func(logger *slog.Logger) { logger.Info("Created", "phoneNumber", "+972501234567") },
+			[]string{"phoneNumber"},
+			redacted,
+		},
+		{
+			"sensitive key with a structured value",
+			func(logger *slog.Logger) { logger.Info("Moved", "lonLat", []float64{34.7818, 32.0853}) },
+			[]string{"lonLat"},
+			redacted,
+		},
+		{
+			"sensitive key in a group",
+			func(logger *slog.Logger) {
+				logger.Info("Updated", slog.Group("user", slog.String("id", "u1"), slog.String("address", "Herzl 1")))
+			},
+			[]string{"user", "address"},
+			redacted,
+		},
+		{
+			"other attributes in a group",
+			func(logger *slog.Logger) {
+				logger.Info("Updated", slog.Group("user", slog.String("id", "u1"), slog.Int("version", 3)))
+			},
+			[]string{"user", "version"},
+			float64(3),
+		},
+		{
+			"attributes of a derived logger",
+			func(logger *slog.Logger) { logger.With("password", "secret").Info("Signed in") },
+			[]string{"password"},
+			redacted,
+		},
+		{
+			"attributes of a derived logger in a group",
+			func(logger *slog.Logger) {
+				logger.WithGroup("user").With("contact", "dana@example.com").Info("Signed in")
+			},
+			[]string{"user", "contact"},
+			"[EMAIL]",
+		},
+		{
+			"context attributes",
+			func(logger *slog.Logger) {
+				ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+				logger.InfoContext(ctx, "Served")
+			},
+			[]string{"requestId"},
+			"req-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := logLine(t, tt.log)
+
+			var got any = line
+			for _, key := range tt.field {
+				group, ok := got.(map[string]any)
+				if !ok {
+					t.Fatalf("%s is not a group in %v", key, line)
+				}
+				got = group[key]
+			}
+			if got != tt.want {
+				t.Errorf("%s = %v, want %v", strings.Join(tt.field, "."), got, tt.want)
+			}
+		})
+	}
+}
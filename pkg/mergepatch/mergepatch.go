@@ -0,0 +1,61 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrNotObject is returned when a patch is not a JSON object
+var ErrNotObject = errors.New("merge patch must be a JSON object")
+
+// Apply applies an RFC 7396 JSON Merge Patch to a JSON document and returns the patched document
+func Apply(original []byte, patch []byte) ([]byte, error) {
+	var document interface{}
+	if err := json.Unmarshal(original, &document); err != nil {
+		return nil, err
+	}
+
+	patchObject, err := Keys(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(merge(document, patchObject))
+}
+
+// Keys decodes a patch and returns its top-level members
+func Keys(patch []byte) (map[string]interface{}, error) {
+	var patchObject map[string]interface{}
+	if err := json.Unmarshal(patch, &patchObject); err != nil {
+		return nil, err
+	}
+	if patchObject == nil {
+		return nil, ErrNotObject
+	}
+	return patchObject, nil
+}
+
+// merge applies a patch object to a target value as described in RFC 7396 section 2
+func merge(target interface{}, patch map[string]interface{}) interface{} {
+	targetObject, ok := target.(map[string]interface{})
+	if !ok || targetObject == nil {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patch {
+		// A null member removes the key from the target
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+
+		// Objects are merged recursively, anything else replaces the target value
+		if patchValue, isObject := value.(map[string]interface{}); isObject {
+			targetObject[key] = merge(targetObject[key], patchValue)
+		} else {
+			targetObject[key] = value
+		}
+	}
+
+	return targetObject
+}
@@ -0,0 +1,99 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Examples of RFC 7396 appendix A whose patch is an object
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		patch    string
+		want     string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null removes member", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"null keeps other members", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"array replaces value", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"value replaces array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"nested object merged", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"arrays are not merged", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"object replaces array", `["a","b"]`, `{"a":"c"}`, `{"a":"c"}`},
+		{"object replaces scalar", `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{"object replaces string", `"string"`, `{"a":"b"}`, `{"a":"b"}`},
+		{"null in new nested object is dropped", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{"nested object replaces scalar", `{"a":"b"}`, `{"a":{"c":"d"}}`, `{"a":{"c":"d"}}`},
+		{"nested null on missing member", `{"a":{"b":"c"}}`, `{"x":{"y":null}}`, `{"a":{"b":"c"},"x":{}}`},
+		{"empty patch", `{"a":1}`, `{}`, `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.original), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if !equalJSON(t, got, []byte(tt.want)) {
+				t.Errorf("Apply() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRejectsPatchesThatAreNotObjects(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{"null", `null`},
+		{"array", `["c"]`},
+		{"string", `"c"`},
+		{"malformed", `{"a":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Apply([]byte(`{"a":"b"}`), []byte(tt.patch)); err == nil {
+				t.Errorf("Apply() accepted patch %s", tt.patch)
+			}
+		})
+	}
+
+	if _, err := Keys([]byte(`null`)); !errors.Is(err, ErrNotObject) {
+		t.Errorf("Keys(null) error = %v, want %v", err, ErrNotObject)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	members, err := Keys([]byte(`{"firstName":"Ada","address":{"city":null},"lastOK":null}`))
+	if err != nil {
+		t.Fatalf("Keys() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"firstName": "Ada",
+		"address":   map[string]interface{}{"city": nil},
+		"lastOK":    nil,
+	}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("Keys() = %v, want %v", members, want)
+	}
+}
+
+// equalJSON compares two JSON documents regardless of member order
+func equalJSON(t *testing.T, a []byte, b []byte) bool {
+	t.Helper()
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatalf("invalid JSON %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatalf("invalid JSON %s: %v", b, err)
+	}
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Namespace of every metric of the server
+const namespace = "neighborguard"
+
+// HTTP metrics, labelled by route template so that IDs and emails in paths do not
+// end up in labels
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to answer HTTP requests by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// MongoDB metrics
+var MongoCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "mongo_command_duration_seconds",
+	Help:      "Time taken by MongoDB commands by command name and outcome.",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+}, []string{"command", "outcome"})
+
+// Domain metrics
+var (
+	OpenNeeds = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "open_needs",
+		Help:      "Recipients currently needing assistance, by catalogue service.",
+	}, []string{"service"})
+
+	ActiveMeetings = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_meetings",
+		Help:      "Meetings picked by a volunteer and not done yet.",
+	})
+
+	OverdueCheckIns = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "overdue_check_ins",
+		Help:      "Recipients whose last check-in is older than the check-in threshold.",
+	})
+
+	MeetingsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "meetings_created_total",
+		Help:      "Meetings created by volunteers.",
+	})
+
+	MeetingsCancelled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "meetings_cancelled_total",
+		Help:      "Meetings cancelled, by the role of the user who cancelled.",
+	}, []string{"role"})
+
+	MeetingsCompleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "meetings_completed_total",
+		Help:      "Meetings marked as done.",
+	})
+
+	TimeToClaim = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "time_to_claim_seconds",
+		Help:      "Time from a recipient needing a service until a volunteer picked it, by catalogue service.",
+		Buckets:   []float64{60, 300, 900, 1800, 3600, 2 * 3600, 6 * 3600, 12 * 3600, 24 * 3600, 48 * 3600, 7 * 24 * 3600},
+	}, []string{"service"})
+)
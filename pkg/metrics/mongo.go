@@ -0,0 +1,19 @@
+package metrics
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoMonitor observes the duration of every MongoDB command
+func MongoMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			MongoCommandDuration.WithLabelValues(e.CommandName, "success").Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			MongoCommandDuration.WithLabelValues(e.CommandName, "failure").Observe(e.Duration.Seconds())
+		},
+	}
+}
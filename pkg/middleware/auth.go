@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const userIDKey contextKey = "userID"
+
+// IdentityResolver returns the ID of the user who sent a request, or "" for anonymous
+// requests and requests whose credentials could not be verified
+type IdentityResolver func(r *http.Request) string
+
+// BearerIdentity identifies users by the session token they send as
+// Authorization: Bearer <token>, which verify turns into their user ID. Requests
+// with a missing, expired or forged token are anonymous.
+func BearerIdentity(verify func(token string) (string, error)) IdentityResolver {
+	return func(r *http.Request) string {
+		scheme, token, found := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+		userID, err := verify(strings.TrimSpace(token))
+		if err != nil {
+			return ""
+		}
+		return userID
+	}
+}
+
+// Authenticate resolves the user of every request and stores it in the request context.
+// Requests without credentials are let through anonymously; handlers decide what they require.
+func Authenticate(resolve IdentityResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if userID := resolve(r); userID != "" {
+				r = r.WithContext(context.WithValue(r.Context(), userIDKey, userID))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetUserID returns the ID of the authenticated user of a request, or "" if anonymous
+func GetUserID(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey).(string)
+	return userID
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBearerIdentity(t *testing.T) {
+	verify := func(token string) (string, error) {
+		if token == "valid-token" {
+			return "u1", nil
+		}
+		return "", errors.New("invalid token")
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          string
+	}{
+		{"valid token", "Bearer valid-token", "u1"},
+		{"scheme in another case", "bearer valid-token", "u1"},
+		{"surrounding spaces", "  Bearer  valid-token ", "u1"},
+		{"invalid token", "Bearer forged-token", ""},
+		{"other scheme", "Basic valid-token", ""},
+		{"token alone", "valid-token", ""},
+		{"missing", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetUserID(r.Context())
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			Authenticate(BearerIdentity(verify))(next).ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("user = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
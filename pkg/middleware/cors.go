@@ -2,35 +2,145 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// func CorsHandler() Middleware {
-
-// 	return func(f http.HandlerFunc) http.HandlerFunc {
-// 		c := cors.New(cors.Options{
-// 			AllowedOrigins:   []string{"*"}, // Allow all origins
-// 			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-// 			AllowedHeaders:   []string{"*"}, // Allow all headers
-// 			AllowCredentials: true,
-// 		})
-// 		// Call the next middleware/handler in chain
-// 		return http.HandlerFunc(c.Handler(f).ServeHTTP)
-// 	}
-// }
-
-// CorsHandler sets the CORS headers
-func CorsHandler(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		// Handle preflight requests
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
-			return
+// CorsPolicy lists what cross-origin browser clients may do
+type CorsPolicy struct {
+	AllowedOrigins   []string // such as https://app.example.com, https://*.example.com for any subdomain, or * for any origin
+	AllowedMethods   []string
+	AllowedHeaders   []string // request headers clients may send, or * for any
+	ExposedHeaders   []string // response headers clients may read
+	AllowCredentials bool     // whether cookies and authorization headers are sent along
+	MaxAge           time.Duration
+}
+
+// allowsOrigin reports whether a cross-origin client is allowed
+func (p CorsPolicy) allowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || matchOrigin(strings.ToLower(allowed), origin) {
+			return true
 		}
+	}
+	return false
+}
 
-		next.ServeHTTP(w, r)
-	})
+// matchOrigin compares an origin with an allowed one, where a * stands for one or
+// more subdomain labels, such as https://*.example.com for https://app.example.com
+func matchOrigin(allowed string, origin string) bool {
+	prefix, suffix, wildcard := strings.Cut(allowed, "*")
+	if !wildcard {
+		return allowed == origin
+	}
+	if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(subdomain, "/:@")
+}
+
+func (p CorsPolicy) allowsMethod(method string) bool {
+	for _, allowed := range p.AllowedMethods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsHeaders reports whether clients may send every header of a preflight's
+// Access-Control-Request-Headers
+func (p CorsPolicy) allowsHeaders(requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		allowed := false
+		for _, candidate := range p.AllowedHeaders {
+			if candidate == "*" || strings.EqualFold(candidate, header) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// Cors applies the CORS policy to every request, including the preflight requests
+// of routes that do not handle OPTIONS, so it wraps the router rather than being
+// one of its middlewares. Preflights that the policy does not allow are answered
+// with the rejected handler. Other requests from disallowed origins are served
+// without CORS headers, so browsers hide the response from the calling page.
+func Cors(policy CorsPolicy, rejected http.HandlerFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			requestedMethod := r.Header.Get("Access-Control-Request-Method")
+			preflight := r.Method == http.MethodOptions && requestedMethod != ""
+
+			// Requests from the same origin, or not sent by a browser
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if preflight {
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+
+				requestedHeaders := r.Header.Get("Access-Control-Request-Headers")
+				if !policy.allowsOrigin(origin) || !policy.allowsMethod(requestedMethod) || !policy.allowsHeaders(requestedHeaders) {
+					rejected(w, r)
+					return
+				}
+
+				setAllowOrigin(header, policy, origin)
+				header.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+				if requestedHeaders != "" {
+					header.Set("Access-Control-Allow-Headers", requestedHeaders)
+				}
+				if policy.MaxAge > 0 {
+					header.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if policy.allowsOrigin(origin) {
+				setAllowOrigin(header, policy, origin)
+				if len(policy.ExposedHeaders) > 0 {
+					header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setAllowOrigin allows the origin of a request. Credentials are only sent to an
+// origin named explicitly, so the origin is echoed rather than answered with *.
+func setAllowOrigin(header http.Header, policy CorsPolicy, origin string) {
+	if policy.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Credentials", "true")
+		return
+	}
+
+	for _, allowed := range policy.AllowedOrigins {
+		if allowed == "*" {
+			header.Set("Access-Control-Allow-Origin", "*")
+			return
+		}
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
 }
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testPolicy = CorsPolicy{
+	AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"},
+	AllowedMethods: []string{"GET", "POST", "PATCH"},
+	AllowedHeaders: []string{"Content-Type", "If-Match"},
+	ExposedHeaders: []string{"ETag", "X-Request-ID"},
+	MaxAge:         10 * time.Minute,
+}
+
+// serveCors sends a request through the CORS middleware and tells whether it
+// reached the next handler or the rejected one
+func serveCors(policy CorsPolicy, r *http.Request) (response *httptest.ResponseRecorder, served bool, rejected bool) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+		w.WriteHeader(http.StatusOK)
+	})
+	reject := func(w http.ResponseWriter, r *http.Request) {
+		rejected = true
+		w.WriteHeader(http.StatusForbidden)
+	}
+
+	response = httptest.NewRecorder()
+	Cors(policy, reject)(next).ServeHTTP(response, r)
+	return response, served, rejected
+}
+
+func TestCorsSimpleRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		origin      string
+		allowOrigin string
+	}{
+		{"same origin", "", ""},
+		{"allowed origin", "https://app.example.com", "https://app.example.com"},
+		{"allowed origin in another case", "https://APP.example.com", "https://APP.example.com"},
+		{"wildcard subdomain", "https://app.example.org", "https://app.example.org"},
+		{"wildcard nested subdomain", "https://eu.app.example.org", "https://eu.app.example.org"},
+		{"wildcard without subdomain", "https://example.org", ""},
+		{"wildcard on another domain", "https://app.example.org.evil.com", ""},
+		{"disallowed origin", "https://evil.com", ""},
+		{"disallowed scheme", "http://app.example.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+
+			response, served, rejected := serveCors(testPolicy, r)
+
+			// Simple requests are always served, browsers hide disallowed responses
+			if !served || rejected {
+				t.Fatalf("served = %v, rejected = %v, want the request served", served, rejected)
+			}
+			if got := response.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.allowOrigin)
+			}
+
+			exposed := response.Header().Get("Access-Control-Expose-Headers")
+			if tt.allowOrigin != "" && exposed != "ETag, X-Request-ID" {
+				t.Errorf("Access-Control-Expose-Headers = %q, want %q", exposed, "ETag, X-Request-ID")
+			}
+			if tt.allowOrigin == "" && exposed != "" {
+				t.Errorf("Access-Control-Expose-Headers = %q for a disallowed origin", exposed)
+			}
+			if response.Header().Get("Vary") != "Origin" {
+				t.Errorf("Vary = %q, want Origin", response.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestCorsPreflight(t *testing.T) {
+	tests := []struct {
+		name     string
+		origin   string
+		method   string
+		headers  string
+		rejected bool
+	}{
+		{"allowed", "https://app.example.com", "PATCH", "Content-Type, If-Match", false},
+		{"allowed without headers", "https://app.example.com", "POST", "", false},
+		{"headers in another case", "https://app.example.com", "POST", "content-type", false},
+		{"wildcard subdomain", "https://app.example.org", "GET", "", false},
+		{"disallowed origin", "https://evil.com", "GET", "", true},
+		{"disallowed method", "https://app.example.com", "DELETE", "", true},
+		{"disallowed header", "https://app.example.com", "POST", "Content-Type, X-Admin", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodOptions, "/v1/users", nil)
+			r.Header.Set("Origin", tt.origin)
+			r.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				r.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+
+			response, served, rejected := serveCors(testPolicy, r)
+
+			// Preflights are answered by the middleware, never by the routes
+			if served {
+				t.Fatal("preflight reached the next handler")
+			}
+			if rejected != tt.rejected {
+				t.Fatalf("rejected = %v, want %v", rejected, tt.rejected)
+			}
+			if tt.rejected {
+				if got := response.Header().Get("Access-Control-Allow-Origin"); got != "" {
+					t.Errorf("Access-Control-Allow-Origin = %q for a rejected preflight", got)
+				}
+				return
+			}
+
+			if response.Code != http.StatusNoContent {
+				t.Errorf("status = %d, want %d", response.Code, http.StatusNoContent)
+			}
+			want := map[string]string{
+				"Access-Control-Allow-Origin":  tt.origin,
+				"Access-Control-Allow-Methods": "GET, POST, PATCH",
+				"Access-Control-Allow-Headers": tt.headers,
+				"Access-Control-Max-Age":       "600",
+			}
+			for header, value := range want {
+				if got := response.Header().Get(header); got != value {
+					t.Errorf("%s = %q, want %q", header, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestCorsOriginHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      CorsPolicy
+		allowOrigin string
+		credentials string
+	}{
+		{
+			name:        "any origin",
+			policy:      CorsPolicy{AllowedOrigins: []string{"*"}},
+			allowOrigin: "*",
+		},
+		{
+			name:        "listed origin",
+			policy:      CorsPolicy{AllowedOrigins: []string{"https://app.example.com"}},
+			allowOrigin: "https://app.example.com",
+		},
+		{
+			name:        "credentials",
+			policy:      CorsPolicy{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+			allowOrigin: "https://app.example.com",
+			credentials: "true",
+		},
+		{
+			name:        "credentials with a wildcard subdomain",
+			policy:      CorsPolicy{AllowedOrigins: []string{"https://*.example.com"}, AllowCredentials: true},
+			allowOrigin: "https://app.example.com",
+			credentials: "true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+			r.Header.Set("Origin", "https://app.example.com")
+
+			response, _, _ := serveCors(tt.policy, r)
+
+			if got := response.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.allowOrigin)
+			}
+			if got := response.Header().Get("Access-Control-Allow-Credentials"); got != tt.credentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.credentials)
+			}
+		})
+	}
+}
+
+func TestCorsPreflightWithoutMaxAge(t *testing.T) {
+	policy := testPolicy
+	policy.MaxAge = 0
+
+	r := httptest.NewRequest(http.MethodOptions, "/v1/users", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+
+	response, _, _ := serveCors(policy, r)
+	if got := response.Header().Get("Access-Control-Max-Age"); got != "" {
+		t.Errorf("Access-Control-Max-Age = %q, want none", got)
+	}
+}
+
+func TestCorsRejectedPreflightHasRequestID(t *testing.T) {
+	var requestID string
+	reject := func(w http.ResponseWriter, r *http.Request) {
+		requestID = GetRequestID(r.Context())
+		w.WriteHeader(http.StatusForbidden)
+	}
+	handler := RequestID(Cors(testPolicy, reject)(http.NotFoundHandler()))
+
+	r := httptest.NewRequest(http.MethodOptions, "/v1/users", nil)
+	r.Header.Set("Origin", "https://evil.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, r)
+
+	if requestID == "" || response.Header().Get(RequestIDHeader) != requestID {
+		t.Errorf("request ID = %q, header = %q, want the same non-empty ID", requestID, response.Header().Get(RequestIDHeader))
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Deprecated marks a route as deprecated since the given date (RFC 9745) and
+// points clients to its successor. Path variables such as {uid} in the
+// successor are filled in from the matched request.
+func Deprecated(since time.Time, successor string) Middleware {
+
+	// Create a new Middleware
+	return func(f http.HandlerFunc) http.HandlerFunc {
+
+		// Define the http.HandlerFunc
+		return func(w http.ResponseWriter, r *http.Request) {
+			link := successor
+			for name, value := range mux.Vars(r) {
+				link = strings.ReplaceAll(link, "{"+name+"}", url.PathEscape(value))
+			}
+
+			// A route has a single successor, replacing the one of its API version if any
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", since.Unix()))
+			w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", link))
+
+			// Call the next middleware/handler in chain
+			f(w, r)
+		}
+	}
+}
+
+// DeprecatedVersion marks every route of an old API version as deprecated
+// since the given date and announces when it will be removed (RFC 8594). The
+// successor of each route is the same path under successorPrefix.
+func DeprecatedVersion(since time.Time, sunset time.Time, successorPrefix string) Middleware {
+
+	// Create a new Middleware
+	return func(f http.HandlerFunc) http.HandlerFunc {
+
+		// Define the http.HandlerFunc
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", since.Unix()))
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			w.Header().Set("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", successorPrefix, r.URL.EscapedPath()))
+
+			// Call the next middleware/handler in chain
+			f(w, r)
+		}
+	}
+}
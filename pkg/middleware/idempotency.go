@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"neighborguard/pkg/idempotency"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Headers of idempotent requests and of the responses replayed for them
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	idempotencyStoreTimeout  = 5 * time.Second
+)
+
+var validIdempotencyKey = regexp.MustCompile(`^[\x21-\x7e]{1,255}$`)
+
+// Response headers stored with a response and replayed with it
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// Idempotency lets clients retry requests with an Idempotency-Key header without
+// repeating their effects. The first response to each key is stored and replayed.
+type Idempotency struct {
+	store          idempotency.Store
+	ttl            time.Duration
+	lockTimeout    time.Duration
+	trustedProxies int
+	failed         func(http.ResponseWriter, *http.Request, error)
+}
+
+// NewIdempotency creates the middleware keeping responses in the store for ttl.
+// A key stays claimed by a request in progress for at most lockTimeout, so a key
+// whose request never finished can be retried. Anonymous clients are told apart by
+// their IP address behind the trusted proxies. Failures are answered by failed.
+func NewIdempotency(store idempotency.Store, ttl time.Duration, lockTimeout time.Duration, trustedProxies int, failed func(http.ResponseWriter, *http.Request, error)) *Idempotency {
+	return &Idempotency{store: store, ttl: ttl, lockTimeout: lockTimeout, trustedProxies: trustedProxies, failed: failed}
+}
+
+// Route makes a route idempotent. Keys belong to the route and to the signed-in user,
+// or the IP address of anonymous clients so they cannot replay each other's responses.
+// Aliases of an endpoint share keys by using the same name. Requests without a key
+// are served as before. A nil Idempotency serves every request as before.
+func (i *Idempotency) Route(name string) Middleware {
+	if i == nil {
+		return func(f http.HandlerFunc) http.HandlerFunc { return f }
+	}
+
+	return func(f http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				f(w, r)
+				return
+			}
+			if !validIdempotencyKey.MatchString(key) {
+				i.failed(w, r, idempotency.ErrInvalidKey)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				i.failed(w, r, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			client := "ip:" + clientIP(r, i.trustedProxies)
+			if userID := GetUserID(r.Context()); userID != "" {
+				client = "user:" + userID
+			}
+			hash := sha256.Sum256(append([]byte(name+"\n"), body...))
+			record := idempotency.Record{
+				Scope:       name + " " + client,
+				Key:         key,
+				RequestHash: hex.EncodeToString(hash[:]),
+				ExpiresAt:   time.Now().Add(i.lockTimeout),
+			}
+
+			existing, err := i.store.Reserve(r.Context(), record)
+			if err != nil {
+				i.failed(w, r, err)
+				return
+			}
+			if existing != nil {
+				i.replay(w, r, record, existing)
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			f(recorder, r)
+			i.finish(r, record, recorder)
+		}
+	}
+}
+
+// replay answers a retry with the stored response of the first request
+func (i *Idempotency) replay(w http.ResponseWriter, r *http.Request, record idempotency.Record, existing *idempotency.Record) {
+	if existing.RequestHash != record.RequestHash {
+		i.failed(w, r, idempotency.ErrKeyReused)
+		return
+	}
+	if !existing.Completed {
+		i.failed(w, r, idempotency.ErrInProgress)
+		return
+	}
+
+	for name, values := range existing.Header {
+		w.Header()[http.CanonicalHeaderKey(name)] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(existing.Status)
+	w.Write(existing.Body)
+}
+
+// finish stores the response, or frees the key if the request failed and may be
+// retried. This happens even if the client went away, as it is about to retry.
+func (i *Idempotency) finish(r *http.Request, record idempotency.Record, recorder *responseRecorder) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), idempotencyStoreTimeout)
+	defer cancel()
+
+	var err error
+	if recorder.status >= http.StatusInternalServerError || recorder.status == 499 { // client closed request
+		err = i.store.Release(ctx, record.Scope, record.Key)
+	} else {
+		record.Completed = true
+		record.Status = recorder.status
+		record.Header = http.Header{}
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Values(name); len(value) > 0 {
+				record.Header[name] = value
+			}
+		}
+		record.Body = recorder.body.Bytes()
+		record.ExpiresAt = time.Now().Add(i.ttl)
+		err = i.store.Complete(ctx, record)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to store idempotent response", "route", routeTemplate(r), "error", err)
+	}
+}
+
+// responseRecorder remembers the status code and body written by a handler
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the original writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"neighborguard/pkg/idempotency"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryStore keeps idempotency records in memory
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: map[string]idempotency.Record{}}
+}
+
+func (s *memoryStore) Reserve(ctx context.Context, record idempotency.Record) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := record.Scope + " " + record.Key
+	if existing, ok := s.records[id]; ok && existing.ExpiresAt.After(time.Now()) {
+		return &existing, nil
+	}
+	s.records[id] = record
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, record idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.Scope+" "+record.Key] = record
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, scope string, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, scope+" "+key)
+	return nil
+}
+
+// idempotencyServer serves a route through the idempotency middleware. Its handler
+// answers 201 with the request body, or the status set in the X-Status header.
+type idempotencyServer struct {
+	handler http.Handler
+	calls   atomic.Int32
+	failure error // last error answered by the failed callback
+	proceed chan struct{}
+}
+
+func newIdempotencyServer(t *testing.T) *idempotencyServer {
+	t.Helper()
+	s := &idempotencyServer{}
+	failed := func(w http.ResponseWriter, r *http.Request, err error) {
+		s.failure = err
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	route := func(w http.ResponseWriter, r *http.Request) {
+		s.calls.Add(1)
+		if s.proceed != nil {
+			<-s.proceed
+		}
+		body, _ := io.ReadAll(r.Body)
+		status := http.StatusCreated
+		if r.Header.Get("X-Status") == "500" {
+			status = http.StatusInternalServerError
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"1"`)
+		w.Header().Set("X-Not-Replayed", "true")
+		w.WriteHeader(status)
+		w.Write(body)
+	}
+
+	idempotent := NewIdempotency(newMemoryStore(), time.Hour, time.Minute, 1, failed)
+	s.handler = Authenticate(BearerIdentity(func(token string) (string, error) { return token, nil }))(Chain(route, idempotent.Route("POST /meeting")))
+	return s
+}
+
+func (s *idempotencyServer) post(key string, user string, body string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/meeting", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(IdempotencyKeyHeader, key)
+	}
+	if user != "" {
+		r.Header.Set("Authorization", "Bearer "+user)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	response := httptest.NewRecorder()
+	s.handler.ServeHTTP(response, r)
+	return response
+}
+
+func TestIdempotencyReplaysTheFirstResponse(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	first := s.post("key-1", "volunteer", `{"date":1}`)
+	retry := s.post("key-1", "volunteer", `{"date":1}`)
+
+	if s.calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want once", s.calls.Load())
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("first response is marked as replayed")
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != `{"date":1}` {
+		t.Errorf("replay = %d %s, want 201 with the first body", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("%s = %q, want true", IdempotentReplayedHeader, retry.Header().Get(IdempotentReplayedHeader))
+	}
+	if retry.Header().Get("ETag") != `"1"` || retry.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("replayed headers = %v, want the stored ETag and Content-Type", retry.Header())
+	}
+	if retry.Header().Get("X-Not-Replayed") != "" {
+		t.Error("replay includes a header that is not stored")
+	}
+}
+
+func TestIdempotencyRejectsAKeyReusedForAnotherBody(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	s.post("key-1", "volunteer", `{"date":1}`)
+	response := s.post("key-1", "volunteer", `{"date":2}`)
+
+	if s.calls.Load() != 1 {
+		t.Errorf("handler called %d times, want once", s.calls.Load())
+	}
+	if response.Code != http.StatusUnprocessableEntity || !errors.Is(s.failure, idempotency.ErrKeyReused) {
+		t.Errorf("response = %d with %v, want %v", response.Code, s.failure, idempotency.ErrKeyReused)
+	}
+}
+
+func TestIdempotencyRejectsAConcurrentRetry(t *testing.T) {
+	s := newIdempotencyServer(t)
+	s.proceed = make(chan struct{})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- s.post("key-1", "volunteer", `{"date":1}`) }()
+	for s.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The retry arrives while the first request is still being served
+	retry := s.post("key-1", "volunteer", `{"date":1}`)
+	if retry.Code != http.StatusUnprocessableEntity || !errors.Is(s.failure, idempotency.ErrInProgress) {
+		t.Errorf("retry = %d with %v, want %v", retry.Code, s.failure, idempotency.ErrInProgress)
+	}
+
+	close(s.proceed)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first response = %d, want 201", first.Code)
+	}
+	if again := s.post("key-1", "volunteer", `{"date":1}`); again.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("retry after the first request finished was not replayed")
+	}
+	if s.calls.Load() != 1 {
+		t.Errorf("handler called %d times, want once", s.calls.Load())
+	}
+}
+
+func TestIdempotencyFreesTheKeyOfAFailedRequest(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	if failed := s.post("key-1", "volunteer", `{"date":1}`, "X-Status", "500"); failed.Code != http.StatusInternalServerError {
+		t.Fatalf("first response = %d, want 500", failed.Code)
+	}
+	retry := s.post("key-1", "volunteer", `{"date":1}`)
+
+	if s.calls.Load() != 2 || retry.Code != http.StatusCreated || retry.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("retry = %d after %d calls, want the request served again", retry.Code, s.calls.Load())
+	}
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	// Requests without a key are always served
+	s.post("", "volunteer", `{"date":1}`)
+	s.post("", "volunteer", `{"date":1}`)
+	if s.calls.Load() != 2 {
+		t.Errorf("handler called %d times without keys, want twice", s.calls.Load())
+	}
+
+	// Keys belong to the user who sent them
+	s.post("key-1", "volunteer", `{"date":1}`)
+	if other := s.post("key-1", "other-volunteer", `{"date":1}`); other.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("response of one user was replayed to another")
+	}
+	if s.calls.Load() != 4 {
+		t.Errorf("handler called %d times, want 4", s.calls.Load())
+	}
+
+	// Anonymous keys belong to the IP address of the client
+	s.post("key-2", "", `{"date":1}`, "X-Forwarded-For", "198.51.100.1")
+	if other := s.post("key-2", "", `{"date":1}`, "X-Forwarded-For", "198.51.100.2"); other.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("response to one anonymous client was replayed to another")
+	}
+	if same := s.post("key-2", "", `{"date":1}`, "X-Forwarded-For", "198.51.100.1"); same.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("retry of an anonymous client was not replayed")
+	}
+	if s.calls.Load() != 6 {
+		t.Errorf("handler called %d times, want 6", s.calls.Load())
+	}
+
+	if invalid := s.post("key with spaces", "volunteer", `{}`); invalid.Code != http.StatusUnprocessableEntity || !errors.Is(s.failure, idempotency.ErrInvalidKey) {
+		t.Errorf("invalid key answered %d with %v, want %v", invalid.Code, s.failure, idempotency.ErrInvalidKey)
+	}
+}
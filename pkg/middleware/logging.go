@@ -1,12 +1,13 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 )
 
-// Logging logs all requests with its path and the time it took to process
+// Logging logs all requests with their route, status and the time it took to process.
+// The request ID and user are added by the logger from the request's context.
 func Logging() Middleware {
 
 	// Create a new Middleware
@@ -17,10 +18,22 @@ func Logging() Middleware {
 
 			// Do middleware things
 			start := time.Now()
-			defer func() { log.Println(r.URL.Path, time.Since(start)) }()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				level := slog.LevelInfo
+				if recorder.status >= http.StatusInternalServerError {
+					level = slog.LevelError
+				}
+				slog.Log(r.Context(), level, "request",
+					"method", r.Method,
+					"route", routeTemplate(r),
+					"status", recorder.status,
+					"duration", time.Since(start),
+				)
+			}()
 
 			// Call the next middleware/handler in chain
-			f(w, r)
+			f(recorder, r)
 		}
 	}
-}
\ No newline at end of file
+}
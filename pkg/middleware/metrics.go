@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"neighborguard/pkg/metrics"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Metrics counts requests and observes their duration, labelled by the
+// template of the matched route, such as /v1/users/{uid}
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		status := strconv.Itoa(recorder.status)
+		metrics.HTTPRequests.WithLabelValues(r.Method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(r.Method, route, status).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"neighborguard/pkg/ratelimit"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimits configures how often clients may send requests
+type RateLimits struct {
+	Default        ratelimit.Limit            // of each client on each route without a limit of its own
+	Routes         map[string]ratelimit.Limit // by route name, such as "POST /users"
+	PerIP          ratelimit.Limit            // of each IP address across all routes
+	TrustedProxies int                        // proxies in front of the server that append to X-Forwarded-For
+}
+
+// RateLimiter rejects the requests of clients that exceed their limits, using
+// token buckets kept in a store
+type RateLimiter struct {
+	store    ratelimit.Store
+	limits   RateLimits
+	rejected http.HandlerFunc
+}
+
+// NewRateLimiter creates a rate limiter answering rejected requests with the given handler
+func NewRateLimiter(store ratelimit.Store, limits RateLimits, rejected http.HandlerFunc) *RateLimiter {
+	return &RateLimiter{store: store, limits: limits, rejected: rejected}
+}
+
+// Route limits the requests of each client to a route. Clients are the signed-in
+// user, or the IP address of anonymous requests. Routes serving the same endpoint,
+// such as a deprecated alias, share their buckets by using the same name.
+// A nil rate limiter lets every request through.
+func (l *RateLimiter) Route(name string) Middleware {
+	if l == nil {
+		return func(f http.HandlerFunc) http.HandlerFunc { return f }
+	}
+
+	limit, ok := l.limits.Routes[name]
+	if !ok {
+		limit = l.limits.Default
+	}
+
+	return func(f http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			client := "ip:" + clientIP(r, l.limits.TrustedProxies)
+			if userID := GetUserID(r.Context()); userID != "" {
+				client = "user:" + userID
+			}
+
+			if l.allow(w, r, "route:"+name+":"+client, limit, true) {
+				f(w, r)
+			}
+		}
+	}
+}
+
+// PerIP limits all the requests of each IP address. User IDs are sent by the client,
+// so this keeps a client from evading the route limits by changing its user ID.
+func (l *RateLimiter) PerIP(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.allow(w, r, "ip:"+clientIP(r, l.limits.TrustedProxies), l.limits.PerIP, false) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// allow counts the request against the bucket of key and rejects it if the bucket is
+// empty. The limit is advertised in RateLimit headers, or only once exceeded.
+// Requests are let through when the store fails, so it cannot take the API down.
+func (l *RateLimiter) allow(w http.ResponseWriter, r *http.Request, key string, limit ratelimit.Limit, advertise bool) bool {
+	result, err := l.store.Take(r.Context(), key, limit)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Rate limit store failed, letting the request through", "error", err)
+		return true
+	}
+
+	if advertise || !result.Allowed {
+		header := w.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+		header.Set("RateLimit-Policy", strconv.Itoa(limit.Requests)+";w="+ceilSeconds(limit.Period))
+	}
+
+	if !result.Allowed {
+		w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+		l.rejected(w, r)
+		return false
+	}
+	return true
+}
+
+// clientIP returns the address of the client, skipping the trusted proxies in front
+// of the server. Entries of X-Forwarded-For that no trusted proxy added are ignored
+// since the client can forge them.
+func clientIP(r *http.Request, trustedProxies int) string {
+	if trustedProxies > 0 {
+		var hops []string
+		for _, value := range r.Header.Values("X-Forwarded-For") {
+			hops = append(hops, strings.Split(value, ",")...)
+		}
+		if client := len(hops) - trustedProxies; client >= 0 {
+			return strings.TrimSpace(hops[client])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ceilSeconds formats a duration as whole seconds, rounded up
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
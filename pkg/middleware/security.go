@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIContentSecurityPolicy forbids browsers from running or embedding anything
+// from API responses, which are data rather than pages
+const APIContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// SecurityHeaders sets the headers that keep browsers from misusing responses:
+// sniffing their content type, framing them, leaking the URL in Referer and
+// running them as pages. With a positive hstsMaxAge, browsers are also told to
+// only use HTTPS; they ignore it on plain HTTP connections.
+func SecurityHeaders(hstsMaxAge time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Set("X-Content-Type-Options", "nosniff")
+			header.Set("X-Frame-Options", "DENY")
+			header.Set("Referrer-Policy", "no-referrer")
+			header.Set("Content-Security-Policy", APIContentSecurityPolicy)
+			if hstsMaxAge > 0 {
+				header.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(int(hstsMaxAge.Seconds()))+"; includeSubDomains")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ContentSecurityPolicy replaces the policy of a handler serving web pages, such as the Swagger UI
+func ContentSecurityPolicy(policy string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Security-Policy", policy)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MaxBytes limits the size of request bodies. Reading beyond the limit fails with an
+// *http.MaxBytesError, which handlers answer with 413.
+func MaxBytes(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Timeout bounds how long a request may take. Its context, and with it every database
+// operation started for the request, is cancelled once the timeout elapses, as it
+// already is when the client disconnects.
+func Timeout(timeout time.Duration) Middleware {
+	return func(f http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			f(w, r.WithContext(ctx))
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// UnixTimes keeps routes working for clients that send and expect the given top
+// level JSON fields as Unix seconds, from before they became RFC 3339 timestamps.
+// Numbers in the request body are converted to timestamps before the handler sees
+// them, and timestamps in JSON responses are converted back to Unix seconds. The
+// zero time and 0 stand for each other.
+func UnixTimes(fields ...string) Middleware {
+
+	// Create a new Middleware
+	return func(f http.HandlerFunc) http.HandlerFunc {
+
+		// Define the http.HandlerFunc
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Body != nil && r.Body != http.NoBody {
+				body, err := io.ReadAll(r.Body)
+				r.Body.Close()
+				if err != nil {
+					// Let the handler report the failure, such as a body too large
+					r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errorReader{err}))
+				} else {
+					body = convertJSONFields(body, fields, unixToTimestamp)
+					r.Body = io.NopCloser(bytes.NewReader(body))
+					r.ContentLength = int64(len(body))
+				}
+			}
+
+			// Hold the response back until its timestamps are converted
+			recorder := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+			f(recorder, r)
+
+			body := recorder.body.Bytes()
+			if mediaType, _, _ := mime.ParseMediaType(recorder.header.Get("Content-Type")); mediaType == "application/json" {
+				body = convertJSONFields(body, fields, timestampToUnix)
+			}
+
+			for name, values := range recorder.header {
+				w.Header()[name] = values
+			}
+			w.Header().Del("Content-Length")
+			w.WriteHeader(recorder.status)
+			w.Write(body)
+		}
+	}
+}
+
+// convertJSONFields applies convert to the given fields of a JSON object, and
+// returns the body unchanged if it is not an object or no field changed
+func convertJSONFields(body []byte, fields []string, convert func(json.RawMessage) (json.RawMessage, bool)) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+
+	var object map[string]json.RawMessage
+	if err := decoder.Decode(&object); err != nil || object == nil {
+		return body
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return body
+	}
+
+	changed := false
+	for _, field := range fields {
+		value, ok := object[field]
+		if !ok {
+			continue
+		}
+		if converted, ok := convert(value); ok {
+			object[field] = converted
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+
+	converted, err := json.Marshal(object)
+	if err != nil {
+		return body
+	}
+	return append(converted, '\n')
+}
+
+// unixToTimestamp converts Unix seconds, possibly with a fraction, to an RFC 3339 timestamp
+func unixToTimestamp(value json.RawMessage) (json.RawMessage, bool) {
+	seconds, err := strconv.ParseFloat(string(value), 64)
+	if err != nil || math.IsInf(seconds, 0) || math.Abs(seconds) > 1e12 {
+		return nil, false
+	}
+
+	var t time.Time
+	if seconds != 0 {
+		whole, fraction := math.Modf(seconds)
+		t = time.Unix(int64(whole), int64(math.Round(fraction*1e9))).UTC()
+	}
+	timestamp, err := json.Marshal(t)
+	if err != nil {
+		return nil, false
+	}
+	return timestamp, true
+}
+
+// timestampToUnix converts an RFC 3339 timestamp to Unix seconds
+func timestampToUnix(value json.RawMessage) (json.RawMessage, bool) {
+	var t time.Time
+	if err := json.Unmarshal(value, &t); err != nil {
+		return nil, false
+	}
+	if t.IsZero() {
+		return json.RawMessage("0"), true
+	}
+	return json.RawMessage(strconv.FormatInt(t.Unix(), 10)), true
+}
+
+// bufferedResponse holds the headers, status code and body written by a handler
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *bufferedResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *bufferedResponse) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+}
+
+func (r *bufferedResponse) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(b)
+}
+
+// errorReader fails every read with err
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
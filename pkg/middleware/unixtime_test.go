@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveUnixTimes sends a body through the UnixTimes middleware to a handler that
+// records the body it got and answers with response as contentType
+func serveUnixTimes(body string, contentType string, response string) (received string, recorder *httptest.ResponseRecorder) {
+	next := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received = string(b)
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("ETag", `"3"`)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, response)
+	}
+
+	recorder = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPatch, "/user/u1", strings.NewReader(body))
+	UnixTimes("lastOK")(next).ServeHTTP(recorder, r)
+	return received, recorder
+}
+
+// jsonField returns a top level field of a JSON object as raw JSON
+func jsonField(t *testing.T, body string, field string) string {
+	t.Helper()
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &object); err != nil {
+		t.Fatalf("%q is not a JSON object: %v", body, err)
+	}
+	return string(object[field])
+}
+
+func TestUnixTimesRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"unix seconds", `{"lastOK":1760745600,"firstName":"Ada"}`, `"2025-10-18T00:00:00Z"`},
+		{"fraction of a second", `{"lastOK":1760745600.25}`, `"2025-10-18T00:00:00.25Z"`},
+		{"zero", `{"lastOK":0}`, `"0001-01-01T00:00:00Z"`},
+		{"timestamp", `{"lastOK":"2025-10-18T02:00:00+02:00"}`, `"2025-10-18T02:00:00+02:00"`},
+		{"null", `{"lastOK":null}`, `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received, _ := serveUnixTimes(tt.body, "application/json", `{}`)
+			if got := jsonField(t, received, "lastOK"); got != tt.want {
+				t.Errorf("lastOK = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnixTimesRequestKeepsOtherBodies(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"without the field", `{"firstName": "Ada"}`},
+		{"not an object", `[1760745600]`},
+		{"malformed", `{"lastOK": 1760745600`},
+		{"trailing data", `{"lastOK": 1760745600} {}`},
+		{"empty", ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if received, _ := serveUnixTimes(tt.body, "application/json", `{}`); received != tt.body {
+				t.Errorf("handler got %q, want the body unchanged", received)
+			}
+		})
+	}
+}
+
+func TestUnixTimesResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		response    string
+		want        string
+	}{
+		{"timestamp", "application/json", `{"id":"u1","lastOK":"2025-10-18T02:00:00+02:00"}`, `1760745600`},
+		{"zero time", "application/json", `{"lastOK":"0001-01-01T00:00:00Z"}`, `0`},
+		{"problem details", "application/problem+json", `{"lastOK":"2025-10-18T00:00:00Z"}`, `"2025-10-18T00:00:00Z"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, recorder := serveUnixTimes(`{}`, tt.contentType, tt.response)
+			if recorder.Code != http.StatusCreated {
+				t.Errorf("status = %d, want %d", recorder.Code, http.StatusCreated)
+			}
+			if etag := recorder.Header().Get("ETag"); etag != `"3"` {
+				t.Errorf("ETag = %s, want the handler's", etag)
+			}
+			if got := jsonField(t, recorder.Body.String(), "lastOK"); got != tt.want {
+				t.Errorf("lastOK = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
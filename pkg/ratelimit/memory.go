@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// How often the memory store forgets the buckets that refilled completely
+const sweepInterval = time.Minute
+
+// MemoryStore keeps the buckets in the memory of the instance
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryBucket struct {
+	bucket
+	full time.Time // when the bucket will be full again, and so can be forgotten
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*memoryBucket{}, lastSweep: time.Now(), now: time.Now}
+}
+
+// Take counts a request against the bucket of key
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{bucket: bucket{tokens: float64(limit.Requests), updated: now}}
+		s.buckets[key] = b
+	}
+
+	result := b.take(now, limit)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// sweep forgets the buckets that are full, since a missing bucket is created full,
+// so memory only grows with the clients that sent requests recently
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows a burst of Requests, refilled evenly over Period. For example
+// 10/1m lets a client send 10 requests at once, then one every 6 seconds.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// rate is the number of requests refilled per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// String formats the limit as it is configured, such as 10/1m0s
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// ParseLimit reads a limit written as requests/period, such as 10/1m
+func ParseLimit(value string) (Limit, error) {
+	requests, period, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return Limit{}, fmt.Errorf("limit %q must be written as requests/period, such as 10/1m", value)
+	}
+
+	var limit Limit
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests <= 0 {
+		return Limit{}, fmt.Errorf("limit %q must allow a positive number of requests", value)
+	}
+	if limit.Period, err = time.ParseDuration(period); err != nil || limit.Period <= 0 {
+		return Limit{}, fmt.Errorf("limit %q must have a positive period", value)
+	}
+	return limit, nil
+}
+
+// ParseRouteLimits reads limits by route written as a comma separated list of
+// route=limit, such as "POST /users=10/1h, GET /users=30/1m"
+func ParseRouteLimits(value string) (map[string]Limit, error) {
+	limits := map[string]Limit{}
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		route, limitValue, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("route limit %q must be written as route=limit", strings.TrimSpace(entry))
+		}
+		limit, err := ParseLimit(limitValue)
+		if err != nil {
+			return nil, err
+		}
+		limits[strings.Join(strings.Fields(route), " ")] = limit
+	}
+	return limits, nil
+}
+
+// FormatRouteLimits writes limits by route the way ParseRouteLimits reads them
+func FormatRouteLimits(limits map[string]Limit) string {
+	entries := make([]string, 0, len(limits))
+	for route, limit := range limits {
+		entries = append(entries, route+"="+limit.String())
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ", ")
+}
+
+// Result is the state of a bucket after a request was counted against it
+type Result struct {
+	Allowed    bool
+	Remaining  int           // requests that can still be sent right away
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed, when this one was not
+}
+
+// Store keeps the token buckets of the clients. The in-memory store limits each
+// instance separately; a store backed by Redis or a compatible server can share
+// buckets between instances by running Take as a script, so it stays atomic.
+type Store interface {
+	// Take counts a request against the bucket of key, created full if missing
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket is the state of a token bucket at a point in time
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills the bucket for the time elapsed since it was last updated, then
+// takes a token from it if one is left
+func (b *bucket) take(now time.Time, limit Limit) Result {
+	burst := float64(limit.Requests)
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.updated).Seconds()*limit.rate())
+	b.updated = now
+
+	result := Result{Allowed: b.tokens >= 1}
+	if result.Allowed {
+		b.tokens--
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / limit.rate())
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((burst - b.tokens) / limit.rate())
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
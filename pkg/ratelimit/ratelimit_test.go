@@ -0,0 +1,180 @@
+package ratelimit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testStore returns a memory store whose clock only moves when advance is called
+func testStore() (store *MemoryStore, advance func(time.Duration)) {
+	now := time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC)
+	store = NewMemoryStore()
+	store.now = func() time.Time { return now }
+	store.lastSweep = now
+	return store, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	// A burst of 3, refilled at one request every 20 seconds
+	limit := Limit{Requests: 3, Period: time.Minute}
+
+	type step struct {
+		after      time.Duration // since the previous request
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+		reset      time.Duration
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "burst then rejected",
+			steps: []step{
+				{0, true, 2, 0, 20 * time.Second},
+				{0, true, 1, 0, 40 * time.Second},
+				{0, true, 0, 0, time.Minute},
+				{0, false, 0, 20 * time.Second, time.Minute},
+			},
+		},
+		{
+			name: "partial refill",
+			steps: []step{
+				{0, true, 2, 0, 20 * time.Second},
+				{0, true, 1, 0, 40 * time.Second},
+				{0, true, 0, 0, time.Minute},
+				{10 * time.Second, false, 0, 10 * time.Second, 50 * time.Second},
+				{10 * time.Second, true, 0, 0, time.Minute},
+			},
+		},
+		{
+			name: "refill never exceeds the burst",
+			steps: []step{
+				{0, true, 2, 0, 20 * time.Second},
+				{time.Hour, true, 2, 0, 20 * time.Second},
+				{0, true, 1, 0, 40 * time.Second},
+			},
+		},
+		{
+			name: "steady rate is always allowed",
+			steps: []step{
+				{0, true, 2, 0, 20 * time.Second},
+				{20 * time.Second, true, 2, 0, 20 * time.Second},
+				{20 * time.Second, true, 2, 0, 20 * time.Second},
+				{20 * time.Second, true, 2, 0, 20 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, advance := testStore()
+			for i, s := range tt.steps {
+				advance(s.after)
+				result, err := store.Take(context.Background(), "client", limit)
+				if err != nil {
+					t.Fatalf("step %d: Take() error = %v", i, err)
+				}
+				want := Result{Allowed: s.allowed, Remaining: s.remaining, RetryAfter: s.retryAfter, Reset: s.reset}
+				if result != want {
+					t.Errorf("step %d: Take() = %+v, want %+v", i, result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBucketsAreSeparate(t *testing.T) {
+	store, _ := testStore()
+	limit := Limit{Requests: 1, Period: time.Minute}
+
+	if result, _ := store.Take(context.Background(), "a", limit); !result.Allowed {
+		t.Fatal("first request of a rejected")
+	}
+	if result, _ := store.Take(context.Background(), "a", limit); result.Allowed {
+		t.Error("second request of a allowed")
+	}
+	if result, _ := store.Take(context.Background(), "b", limit); !result.Allowed {
+		t.Error("first request of b rejected")
+	}
+}
+
+func TestSweepForgetsFullBuckets(t *testing.T) {
+	store, advance := testStore()
+	limit := Limit{Requests: 2, Period: 10 * time.Minute}
+
+	store.Take(context.Background(), "idle", limit)
+	store.Take(context.Background(), "busy", limit)
+	store.Take(context.Background(), "busy", limit)
+
+	// The idle bucket is full again after 5 minutes, the busy one after 10
+	advance(6 * time.Minute)
+	store.Take(context.Background(), "other", limit)
+
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("full bucket was kept")
+	}
+	if _, ok := store.buckets["busy"]; !ok {
+		t.Error("bucket that is not full was forgotten")
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Limit
+		wantErr bool
+	}{
+		{"10/1m", Limit{Requests: 10, Period: time.Minute}, false},
+		{" 20/1h ", Limit{Requests: 20, Period: time.Hour}, false},
+		{"600/30s", Limit{Requests: 600, Period: 30 * time.Second}, false},
+		{"10", Limit{}, true},
+		{"0/1m", Limit{}, true},
+		{"-1/1m", Limit{}, true},
+		{"ten/1m", Limit{}, true},
+		{"10/0s", Limit{}, true},
+		{"10/minute", Limit{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseLimit(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLimit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteLimits(t *testing.T) {
+	limits, err := ParseRouteLimits("POST  /users=20/1h, GET /users=20/1m,")
+	if err != nil {
+		t.Fatalf("ParseRouteLimits() error = %v", err)
+	}
+
+	want := map[string]Limit{
+		"POST /users": {Requests: 20, Period: time.Hour},
+		"GET /users":  {Requests: 20, Period: time.Minute},
+	}
+	if !reflect.DeepEqual(limits, want) {
+		t.Errorf("ParseRouteLimits() = %v, want %v", limits, want)
+	}
+
+	formatted := FormatRouteLimits(limits)
+	if formatted != "GET /users=20/1m0s, POST /users=20/1h0m0s" {
+		t.Errorf("FormatRouteLimits() = %q", formatted)
+	}
+	if again, err := ParseRouteLimits(formatted); err != nil || !reflect.DeepEqual(again, limits) {
+		t.Errorf("ParseRouteLimits(FormatRouteLimits()) = %v, %v, want %v", again, err, limits)
+	}
+
+	if _, err := ParseRouteLimits("POST /users"); err == nil {
+		t.Error("ParseRouteLimits() accepted a route without a limit")
+	}
+}
@@ -0,0 +1,237 @@
+// Package rrule implements the subset of RFC 5545 recurrence rules used by
+// meeting series: FREQ of DAILY, WEEKLY or MONTHLY, with INTERVAL, BYDAY for
+// weekly rules, and COUNT or UNTIL.
+package rrule
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is how often a rule repeats
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// Format of UNTIL, always in UTC
+const untilFormat = "20060102T150405Z"
+
+// Most periods walked when listing occurrences, so a rule can never loop forever
+const maxPeriods = 10000
+
+// Day codes of BYDAY
+var dayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule repeats the start of a series. For example FREQ=WEEKLY;BYDAY=TU,TH;COUNT=8
+// repeats it on Tuesdays and Thursdays for four weeks.
+type Rule struct {
+	Frequency Frequency
+	Interval  int            // periods between occurrences, 1 if not given
+	ByDay     []time.Weekday // days of the week of weekly rules, the day of the start if empty
+	Count     int            // number of occurrences, unlimited if zero
+	Until     time.Time      // last possible occurrence, unlimited if zero
+}
+
+// Parse reads a rule such as FREQ=WEEKLY;INTERVAL=2;BYDAY=TU. A leading RRULE: is accepted.
+func Parse(value string) (Rule, error) {
+	rule := Rule{Interval: 1}
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(value), "RRULE:"), ";") {
+		name, partValue, found := strings.Cut(part, "=")
+		if !found {
+			return Rule{}, fmt.Errorf("rule part %q must be written as NAME=value", part)
+		}
+		name = strings.ToUpper(name)
+		if seen[name] {
+			return Rule{}, fmt.Errorf("rule part %s is given twice", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Frequency = Frequency(strings.ToUpper(partValue))
+			if rule.Frequency != Daily && rule.Frequency != Weekly && rule.Frequency != Monthly {
+				return Rule{}, fmt.Errorf("FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+		case "INTERVAL":
+			if rule.Interval, err = strconv.Atoi(partValue); err != nil || rule.Interval < 1 {
+				return Rule{}, fmt.Errorf("INTERVAL must be a positive number")
+			}
+		case "COUNT":
+			if rule.Count, err = strconv.Atoi(partValue); err != nil || rule.Count < 1 {
+				return Rule{}, fmt.Errorf("COUNT must be a positive number")
+			}
+		case "UNTIL":
+			if rule.Until, err = time.Parse(untilFormat, strings.ToUpper(partValue)); err != nil {
+				return Rule{}, fmt.Errorf("UNTIL must be a UTC time such as 20261231T235959Z")
+			}
+		case "BYDAY":
+			for _, code := range strings.Split(partValue, ",") {
+				day, ok := dayCodes[strings.ToUpper(code)]
+				if !ok {
+					return Rule{}, fmt.Errorf("BYDAY must list days such as MO,TU")
+				}
+				// Listed twice, the day would produce every occurrence twice
+				if slices.Contains(rule.ByDay, day) {
+					return Rule{}, fmt.Errorf("BYDAY lists %s twice", strings.ToUpper(code))
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		default:
+			return Rule{}, fmt.Errorf("rule part %s is not supported", name)
+		}
+	}
+
+	if rule.Frequency == "" {
+		return Rule{}, fmt.Errorf("FREQ is required")
+	}
+	if len(rule.ByDay) > 0 && rule.Frequency != Weekly {
+		return Rule{}, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return Rule{}, fmt.Errorf("COUNT and UNTIL cannot be combined")
+	}
+
+	// Walk the days of a week in order
+	sort.Slice(rule.ByDay, func(i, j int) bool { return weekdayIndex(rule.ByDay[i]) < weekdayIndex(rule.ByDay[j]) })
+	return rule, nil
+}
+
+// String writes the rule the way Parse reads it
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			codes = append(codes, strings.ToUpper(day.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilFormat))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Matches tells whether start, the first occurrence of a series, is one the rule produces
+func (r Rule) Matches(start time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, day := range r.ByDay {
+		if day == start.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// Between returns the occurrences of a series starting at start that fall between
+// from, included, and to, excluded. Occurrences keep the time of day of start in
+// its location, so they follow daylight saving time changes.
+func (r Rule) Between(start time.Time, from time.Time, to time.Time) []time.Time {
+	var occurrences []time.Time
+	r.each(start, func(occurrence time.Time) bool {
+		if !occurrence.Before(to) {
+			return false
+		}
+		if !occurrence.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+		return true
+	})
+	return occurrences
+}
+
+// Last returns the last occurrence of a series starting at start, or false if
+// the rule repeats forever
+func (r Rule) Last(start time.Time) (time.Time, bool) {
+	if r.Count == 0 && r.Until.IsZero() {
+		return time.Time{}, false
+	}
+	last := start
+	r.each(start, func(occurrence time.Time) bool {
+		last = occurrence
+		return true
+	})
+	return last, true
+}
+
+// each calls fn with the occurrences of a series starting at start, in order,
+// until fn returns false or the rule ends
+func (r Rule) each(start time.Time, fn func(time.Time) bool) {
+	interval := max(r.Interval, 1)
+	count := 0
+	for period := 0; period < maxPeriods; period++ {
+		for _, occurrence := range r.period(start, period*interval) {
+			if occurrence.Before(start) {
+				continue
+			}
+			if !r.Until.IsZero() && occurrence.After(r.Until) {
+				return
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return
+			}
+			if !fn(occurrence) {
+				return
+			}
+		}
+	}
+}
+
+// period returns the candidate occurrences of the period offset periods after the one of start
+func (r Rule) period(start time.Time, offset int) []time.Time {
+	switch r.Frequency {
+	case Daily:
+		return []time.Time{start.AddDate(0, 0, offset)}
+	case Monthly:
+		// Months without the day of the start, such as the 31st, are skipped
+		occurrence := start.AddDate(0, offset, 0)
+		if occurrence.Day() != start.Day() {
+			return nil
+		}
+		return []time.Time{occurrence}
+	}
+
+	if len(r.ByDay) == 0 {
+		return []time.Time{start.AddDate(0, 0, 7*offset)}
+	}
+	// Weeks start on Monday
+	monday := start.AddDate(0, 0, 7*offset-weekdayIndex(start.Weekday()))
+	occurrences := make([]time.Time, 0, len(r.ByDay))
+	for _, day := range r.ByDay {
+		occurrences = append(occurrences, monday.AddDate(0, 0, weekdayIndex(day)))
+	}
+	return occurrences
+}
+
+// weekdayIndex numbers the days of a week starting on Monday
+func weekdayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
@@ -0,0 +1,284 @@
+package rrule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata" // Load Europe/Paris even where the system has no timezone database
+)
+
+// Paris moves to summer time on 2026-03-29 and back on 2026-10-25
+var paris = mustLoadLocation("Europe/Paris")
+
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return location
+}
+
+// at reads a local time in Paris such as 2026-03-24 10:00
+func at(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, paris)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Rule
+		text  string
+	}{
+		{"FREQ=DAILY", Rule{Frequency: Daily, Interval: 1}, "FREQ=DAILY"},
+		{"RRULE:freq=weekly;interval=2", Rule{Frequency: Weekly, Interval: 2}, "FREQ=WEEKLY;INTERVAL=2"},
+		{"FREQ=MONTHLY;COUNT=6", Rule{Frequency: Monthly, Interval: 1, Count: 6}, "FREQ=MONTHLY;COUNT=6"},
+		{
+			"FREQ=WEEKLY;BYDAY=SU,TH,MO",
+			Rule{Frequency: Weekly, Interval: 1, ByDay: []time.Weekday{time.Monday, time.Thursday, time.Sunday}},
+			"FREQ=WEEKLY;BYDAY=MO,TH,SU",
+		},
+		{
+			"FREQ=WEEKLY;UNTIL=20261231T235959Z",
+			Rule{Frequency: Weekly, Interval: 1, Until: time.Date(2026, time.December, 31, 23, 59, 59, 0, time.UTC)},
+			"FREQ=WEEKLY;UNTIL=20261231T235959Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+			if got.String() != tt.text {
+				t.Errorf("String() = %q, want %q", got.String(), tt.text)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"COUNT=3",
+		"FREQ=YEARLY",
+		"FREQ",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=-1",
+		"FREQ=DAILY;UNTIL=2026-12-31",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=MO,TU,mo",
+		"FREQ=WEEKLY;COUNT=3;UNTIL=20261231T235959Z",
+		"FREQ=WEEKLY;BYMONTH=1",
+	}
+
+	for _, value := range tests {
+		t.Run(value, func(t *testing.T) {
+			if _, err := Parse(value); err == nil {
+				t.Errorf("Parse(%q) accepted the rule", value)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		from  string
+		to    string
+		want  []string
+	}{
+		{
+			name:  "daily with interval",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: "2026-01-01 09:00",
+			from:  "2026-01-01 00:00",
+			to:    "2026-01-08 00:00",
+			want:  []string{"2026-01-01 09:00", "2026-01-03 09:00", "2026-01-05 09:00", "2026-01-07 09:00"},
+		},
+		{
+			name:  "from included and to excluded",
+			rule:  "FREQ=DAILY",
+			start: "2026-01-01 09:00",
+			from:  "2026-01-02 09:00",
+			to:    "2026-01-04 09:00",
+			want:  []string{"2026-01-02 09:00", "2026-01-03 09:00"},
+		},
+		{
+			name:  "weekly days in order whatever order they are listed in",
+			rule:  "FREQ=WEEKLY;BYDAY=FR,MO,WE",
+			start: "2026-10-05 18:30",
+			from:  "2026-10-12 00:00",
+			to:    "2026-10-17 00:00",
+			want:  []string{"2026-10-12 18:30", "2026-10-14 18:30", "2026-10-16 18:30"},
+		},
+		{
+			name:  "weekly days before the start are skipped",
+			rule:  "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=3",
+			start: "2026-03-25 10:00",
+			from:  "2026-03-01 00:00",
+			to:    "2026-05-01 00:00",
+			want:  []string{"2026-03-26 10:00", "2026-03-31 10:00", "2026-04-02 10:00"},
+		},
+		{
+			name:  "every other week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			start: "2026-01-05 08:00",
+			from:  "2026-01-01 00:00",
+			to:    "2026-02-01 00:00",
+			want:  []string{"2026-01-05 08:00", "2026-01-08 08:00", "2026-01-19 08:00", "2026-01-22 08:00"},
+		},
+		{
+			name:  "monthly skips months without the day",
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			start: "2026-01-31 12:00",
+			from:  "2026-01-01 00:00",
+			to:    "2027-01-01 00:00",
+			want:  []string{"2026-01-31 12:00", "2026-03-31 12:00", "2026-05-31 12:00"},
+		},
+		{
+			name:  "until is included",
+			rule:  "FREQ=WEEKLY;UNTIL=20260115T080000Z",
+			start: "2026-01-01 09:00",
+			from:  "2026-01-01 00:00",
+			to:    "2026-03-01 00:00",
+			want:  []string{"2026-01-01 09:00", "2026-01-08 09:00", "2026-01-15 09:00"},
+		},
+		{
+			name:  "keeps the time of day into summer time",
+			rule:  "FREQ=DAILY",
+			start: "2026-03-27 10:00",
+			from:  "2026-03-27 00:00",
+			to:    "2026-03-31 00:00",
+			want:  []string{"2026-03-27 10:00", "2026-03-28 10:00", "2026-03-29 10:00", "2026-03-30 10:00"},
+		},
+		{
+			name:  "keeps the time of day out of summer time",
+			rule:  "FREQ=WEEKLY;BYDAY=SA,SU,MO",
+			start: "2026-10-24 02:30",
+			from:  "2026-10-24 00:00",
+			to:    "2026-10-27 00:00",
+			want:  []string{"2026-10-24 02:30", "2026-10-25 02:30", "2026-10-26 02:30"},
+		},
+		{
+			name:  "window before the start",
+			rule:  "FREQ=DAILY",
+			start: "2026-06-01 09:00",
+			from:  "2026-05-01 00:00",
+			to:    "2026-06-01 09:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got := rule.Between(at(t, tt.start), at(t, tt.from), at(t, tt.to))
+
+			var want []time.Time
+			for _, value := range tt.want {
+				want = append(want, at(t, value))
+			}
+			if len(got) != len(want) {
+				t.Fatalf("Between() = %v, want %v", got, want)
+			}
+			for i := range want {
+				if !got[i].Equal(want[i]) || got[i].Location() != paris {
+					t.Errorf("occurrence %d = %v, want %v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBetweenFollowsDaylightSavingTime(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 10:00 in Paris is 09:00 UTC in winter and 08:00 UTC in summer
+	got := rule.Between(at(t, "2026-03-28 10:00"), at(t, "2026-03-28 00:00"), at(t, "2026-03-30 00:00"))
+	want := []time.Time{
+		time.Date(2026, time.March, 28, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 29, 8, 0, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Between() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("occurrence %d = %v, want %v", i, got[i].UTC(), want[i])
+		}
+	}
+}
+
+func TestLast(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		want  string
+		found bool
+	}{
+		{"count", "FREQ=DAILY;COUNT=3", "2026-01-01 09:00", "2026-01-03 09:00", true},
+		{"count with days", "FREQ=WEEKLY;BYDAY=TH,TU;COUNT=4", "2026-03-24 10:00", "2026-04-02 10:00", true},
+		{"count across summer time", "FREQ=WEEKLY;COUNT=3", "2026-03-22 10:00", "2026-04-05 10:00", true},
+		{"until", "FREQ=WEEKLY;UNTIL=20260120T000000Z", "2026-01-01 09:00", "2026-01-15 09:00", true},
+		{"monthly count skips short months", "FREQ=MONTHLY;COUNT=2", "2026-01-31 12:00", "2026-03-31 12:00", true},
+		{"forever", "FREQ=WEEKLY;BYDAY=MO", "2026-01-05 09:00", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got, found := rule.Last(at(t, tt.start))
+			if found != tt.found {
+				t.Fatalf("Last() found = %v, want %v", found, tt.found)
+			}
+			if tt.found && !got.Equal(at(t, tt.want)) {
+				t.Errorf("Last() = %v, want %v", got, at(t, tt.want))
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	weekly, err := Parse("FREQ=WEEKLY;BYDAY=TU,TH")
+	if err != nil {
+		t.Fatal(err)
+	}
+	daily, err := Parse("FREQ=DAILY")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !weekly.Matches(at(t, "2026-03-24 10:00")) {
+		t.Error("Matches() = false for a Tuesday")
+	}
+	if weekly.Matches(at(t, "2026-03-25 10:00")) {
+		t.Error("Matches() = true for a Wednesday")
+	}
+	if !daily.Matches(at(t, "2026-03-25 10:00")) {
+		t.Error("Matches() = false for a rule without BYDAY")
+	}
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"neighborguard/pkg/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CheckIn is a moment a user reported being OK, kept for the user's data export
+type CheckIn struct {
+	ID     string    `json:"-" bson:"_id"`
+	UserID string    `json:"-" bson:"userId"`
+	At     time.Time `json:"at" bson:"at"`
+}
+
+// UserExport is a copy of all the data kept about a user
+type UserExport struct {
+	ExportedAt   time.Time       `json:"exportedAt"`
+	Profile      User            `json:"profile"`
+	Meetings     []Meeting       `json:"meetings"`
+	CheckIns     []CheckIn       `json:"checkIns"`
+	AuditEntries []AuditEntry    `json:"auditEntries"`
+	Availability *Availability   `json:"availability,omitempty"` // of volunteers who published a schedule
+	Series       []MeetingSeries `json:"series"`
+}
+
+// ExportUserData gathers the profile, meetings, meeting series, check-ins, audit entries and schedule of a user.
+// Only the user themselves or an administrator may export them. Each query may take DatabaseTimeout,
+// the export as a whole as long as the request's deadline allows.
+func ExportUserData(ctx context.Context, actorID string, uid string) (UserExport, error) {
+	ctx, span := tracer.Start(ctx, "services.ExportUserData")
+	defer span.End()
+
+	// Create a context with timeout for each query
+	query, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	err := authorizeAccountAccess(query, actorID, uid)
+	cancel()
+	if err != nil {
+		return UserExport{}, err
+	}
+
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	user, err := findActiveUser(query, uid)
+	cancel()
+	if err != nil {
+		return UserExport{}, err
+	}
+
+	// The export is recorded before it is gathered so it lists itself
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	err = recordAudit(query, actorID, uid, AuditUserExported)
+	cancel()
+	if err != nil {
+		return UserExport{}, err
+	}
+
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	meetings, err := findMeetings(query, uid, "")
+	cancel()
+	if err != nil {
+		return UserExport{}, err
+	}
+	if meetings == nil {
+		meetings = []Meeting{}
+	}
+
+	// Get the check-ins of the user, oldest first
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	checkIns, err := findCheckIns(query, uid)
+	cancel()
+	if err != nil {
+		return UserExport{}, err
+	}
+
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	auditEntries, err := getAuditEntries(query, uid)
+	cancel()
+	if err != nil {
+		return UserExport{}, err
+	}
+
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	availability, found, err := findAvailability(query, uid)
+	cancel()
+	if err != nil {
+		return UserExport{}, err
+	}
+
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	series, err := findUserSeries(query, uid, bson.M{})
+	cancel()
+	if err != nil {
+		return UserExport{}, err
+	}
+
+	// Never hand out the stored password, even to its owner
+	user.Password = ""
+
+	export := UserExport{
+		ExportedAt:   time.Now(),
+		Profile:      user,
+		Meetings:     meetings,
+		CheckIns:     checkIns,
+		AuditEntries: auditEntries,
+		Series:       series,
+	}
+	if found {
+		export.Availability = &availability
+	}
+	return export, nil
+}
+
+// findCheckIns returns the check-ins of a user, oldest first
+func findCheckIns(ctx context.Context, uid string) ([]CheckIn, error) {
+	cursor, err := database.CheckInsCollection.Find(ctx, bson.M{"userId": uid}, options.Find().SetSort(bson.M{"at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	checkIns := []CheckIn{}
+	if err = cursor.All(ctx, &checkIns); err != nil {
+		return nil, err
+	}
+	return checkIns, nil
+}
+
+// DeleteUser cancels the meeting series and active meetings of a user and then anonymizes their
+// personal data. Completed meetings keep referencing the anonymized user so
+// they still count in statistics. Only the user themselves or an administrator
+// may delete an account. Each query may take DatabaseTimeout, the deletion as a
+// whole as long as the request's deadline allows.
+func DeleteUser(ctx context.Context, actorID string, uid string) error {
+	ctx, span := tracer.Start(ctx, "services.DeleteUser")
+	defer span.End()
+
+	// Create a context with timeout for each query
+	query, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	err := authorizeAccountAccess(query, actorID, uid)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	user, err := findActiveUser(query, uid)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	// Stop the series first so they no longer create meetings
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	activeSeries, err := findUserSeries(query, uid, bson.M{"status": bson.M{"$ne": SeriesCancelled}})
+	cancel()
+	if err != nil {
+		return err
+	}
+	for _, series := range activeSeries {
+		// A series cancelled meanwhile by the other participant is already stopped
+		if err := CancelSeries(ctx, uid, series.ID); err != nil && !errors.Is(err, ErrSeriesCancelled) {
+			return err
+		}
+	}
+
+	// Cancel active meetings so their recipients are offered to other volunteers again
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	activeMeetings, err := findActiveMeetings(query, uid)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	for _, meeting := range activeMeetings {
+		// A meeting cancelled meanwhile by the other participant is already gone
+		if err := CancelMeeting(ctx, uid, meeting.ID); err != nil && !errors.Is(err, ErrMeetingCancelled) {
+			return err
+		}
+	}
+
+	// Erase the personal data, keeping the ID, role and timestamps for statistics.
+	// The erased fields are empty so they are stored without encryption.
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"firstName":    "",
+			"lastName":     "",
+			"age":          0,
+			"phoneNumber":  "",
+			"gender":       "",
+			"email":        "",
+			"password":     "",
+			"address":      Address{},
+			"languages":    []string{},
+			"services":     map[string]MeetingAssistanceStatus{},
+			"lonLat":       LonLat{},
+			"timezone":     "",
+			"lastOK":       time.Time{},
+			"profileImage": "",
+			"privacy":      PrivacySettings{HideFromSearch: true},
+			"verified":     false,
+			"deletedAt":    now,
+			"updatedAt":    now,
+			"version":      user.Version + 1,
+		},
+		"$unset": bson.M{"emailIndex": "", "calendarTokenHash": ""},
+	}
+
+	// Execute the update in MongoDB, only if the user was not modified meanwhile
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	result, err := database.UsersCollection.UpdateOne(query, versionFilter(uid, user.Version), update)
+	cancel()
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConcurrentUpdate
+	}
+
+	// Check-ins reveal when the user was active, they are not needed for statistics
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	_, err = database.CheckInsCollection.DeleteMany(query, bson.M{"userId": uid})
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	// Neither is the schedule of a volunteer
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	_, err = database.AvailabilityCollection.DeleteOne(query, bson.M{"_id": uid})
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	query, cancel = context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+	return recordAudit(query, actorID, uid, AuditUserDeleted)
+}
+
+// findActiveMeetings returns the meetings of a user that are still picked
+func findActiveMeetings(ctx context.Context, uid string) ([]Meeting, error) {
+	cursor, err := database.MeetingsCollection.Find(ctx, bson.M{
+		"$or": []bson.M{
+			{"recipientId": uid},
+			{"volunteerId": uid},
+		},
+		"meetingStatus": IsPicked,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var activeMeetings []Meeting
+	if err = cursor.All(ctx, &activeMeetings); err != nil {
+		return nil, err
+	}
+	return activeMeetings, nil
+}
+
+// CanViewProfile reports whether the signed-in user may see the full profile of
+// a user, which only the user themselves and administrators can
+func CanViewProfile(ctx context.Context, actorID string, uid string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "services.CanViewProfile")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	err := authorizeAccountAccess(ctx, actorID, uid)
+	if errors.Is(err, ErrNotAuthenticated) || errors.Is(err, ErrAccountAccessDenied) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// authorizeAccountAccess allows the owner of an account and administrators to manage it
+func authorizeAccountAccess(ctx context.Context, actorID string, uid string) error {
+	if actorID == "" {
+		return ErrNotAuthenticated
+	}
+	if actorID == uid {
+		return nil
+	}
+
+	actor, err := findActiveUser(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrNotAuthenticated
+		}
+		return err
+	}
+	if actor.Role != Admin {
+		return ErrAccountAccessDenied
+	}
+	return nil
+}
+
+// recordCheckIn stores a check-in of a user at the given time. Failures are
+// only logged since the check-in itself is already saved on the user.
+func recordCheckIn(ctx context.Context, userID string, at time.Time) {
+	_, err := database.CheckInsCollection.InsertOne(ctx, CheckIn{
+		ID:     primitive.NewObjectID().Hex(),
+		UserID: userID,
+		At:     at,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error recording check-in", "userId", userID, "error", err)
+	}
+}
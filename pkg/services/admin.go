@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"errors"
+)
+
+// AuthorizeAdmin checks that the signed-in user is an administrator
+func AuthorizeAdmin(ctx context.Context, actorID string) error {
+	ctx, span := tracer.Start(ctx, "services.AuthorizeAdmin")
+	defer span.End()
+
+	if actorID == "" {
+		return ErrNotAuthenticated
+	}
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	actor, err := findActiveUser(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrNotAuthenticated
+		}
+		return err
+	}
+	if actor.Role != Admin {
+		return ErrAdminsOnly
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"neighborguard/pkg/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditAction string
+
+const (
+	AuditUserExported         AuditAction = "USER_EXPORTED"
+	AuditUserDeleted          AuditAction = "USER_DELETED"
+	AuditCalendarTokenCreated AuditAction = "CALENDAR_TOKEN_CREATED"
+	AuditCalendarTokenRevoked AuditAction = "CALENDAR_TOKEN_REVOKED"
+)
+
+// AuditEntry records an action taken on a user's account and who took it
+type AuditEntry struct {
+	ID        string      `json:"uid" bson:"_id"`
+	SubjectID string      `json:"subjectId" bson:"subjectId"` // user the action was about
+	ActorID   string      `json:"actorId" bson:"actorId"`     // user who took the action, the subject or an administrator
+	Action    AuditAction `json:"action" bson:"action"`
+	CreatedAt time.Time   `json:"createdAt" bson:"createdAt"`
+}
+
+// recordAudit stores an audit entry about the subject
+func recordAudit(ctx context.Context, actorID string, subjectID string, action AuditAction) error {
+	_, err := database.AuditCollection.InsertOne(ctx, AuditEntry{
+		ID:        primitive.NewObjectID().Hex(),
+		SubjectID: subjectID,
+		ActorID:   actorID,
+		Action:    action,
+		CreatedAt: time.Now(),
+	})
+	return err
+}
+
+// getAuditEntries returns the audit entries about a user, oldest first
+func getAuditEntries(ctx context.Context, subjectID string) ([]AuditEntry, error) {
+	cursor, err := database.AuditCollection.Find(ctx, bson.M{"subjectId": subjectID}, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []AuditEntry{}
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"neighborguard/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Range of the free slots returned when none is given, and the longest range accepted
+const (
+	DefaultAvailabilityRange = 7 * 24 * time.Hour
+	maxAvailabilityRange     = 31 * 24 * time.Hour
+)
+
+// Days of the weekly availability windows
+var weekdays = map[string]time.Weekday{
+	"MONDAY":    time.Monday,
+	"TUESDAY":   time.Tuesday,
+	"WEDNESDAY": time.Wednesday,
+	"THURSDAY":  time.Thursday,
+	"FRIDAY":    time.Friday,
+	"SATURDAY":  time.Saturday,
+	"SUNDAY":    time.Sunday,
+}
+
+// AvailabilityWindow is a period of the week a volunteer is available, in the
+// timezone of their schedule
+type AvailabilityWindow struct {
+	Day   string `json:"day" bson:"day"`     // MONDAY to SUNDAY
+	Start string `json:"start" bson:"start"` // time of day, such as 09:00
+	End   string `json:"end" bson:"end"`     // time of day after Start, up to 24:00
+}
+
+// AvailabilityException overrides the weekly windows between two times, making
+// the volunteer unavailable, such as during a holiday, or available in addition
+type AvailabilityException struct {
+	Start     time.Time `json:"start" bson:"start"`
+	End       time.Time `json:"end" bson:"end"`
+	Available bool      `json:"available" bson:"available"`
+}
+
+type NewAvailability struct {
+	Timezone   string                  `json:"timezone"` // IANA timezone of the weekly windows, the volunteer's if empty
+	Weekly     []AvailabilityWindow    `json:"weekly"`
+	Exceptions []AvailabilityException `json:"exceptions"`
+}
+
+// Availability is the schedule a volunteer publishes. Volunteers without one can
+// be booked at any time.
+type Availability struct {
+	VolunteerID string                  `json:"-" bson:"_id"`
+	Timezone    string                  `json:"timezone" bson:"timezone"` // of the weekly windows, which follow its daylight saving time
+	Weekly      []AvailabilityWindow    `json:"weekly" bson:"weekly"`
+	Exceptions  []AvailabilityException `json:"exceptions" bson:"exceptions"`
+	UpdatedAt   time.Time               `json:"updatedAt" bson:"updatedAt"`
+}
+
+// TimeSlot is the period between two times, the end excluded
+type TimeSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// FreeSlots are the periods a volunteer can be booked between two times: those
+// covered by their availability and not taken by one of their meetings
+type FreeSlots struct {
+	Timezone   string                  `json:"timezone"`
+	Weekly     []AvailabilityWindow    `json:"weekly"`
+	Exceptions []AvailabilityException `json:"exceptions"`
+	From       time.Time               `json:"from"`
+	To         time.Time               `json:"to"`
+	Slots      []TimeSlot              `json:"slots"`
+}
+
+// SetAvailability replaces the schedule of a volunteer. Only the volunteer
+// themselves or an administrator may change it.
+func SetAvailability(ctx context.Context, actorID string, uid string, newAvailability NewAvailability) (Availability, error) {
+	ctx, span := tracer.Start(ctx, "services.SetAvailability")
+	defer span.End()
+
+	// Reject invalid payloads before touching the database
+	if err := newAvailability.Validate(); err != nil {
+		return Availability{}, err
+	}
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	if err := authorizeAccountAccess(ctx, actorID, uid); err != nil {
+		return Availability{}, err
+	}
+	volunteer, err := findVolunteer(ctx, uid)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	availability := Availability{
+		VolunteerID: uid,
+		Timezone:    newAvailability.Timezone,
+		Weekly:      newAvailability.Weekly,
+		Exceptions:  newAvailability.Exceptions,
+		UpdatedAt:   time.Now(),
+	}
+	if availability.Timezone == "" {
+		availability.Timezone = volunteer.Location().String()
+	}
+	if availability.Weekly == nil {
+		availability.Weekly = []AvailabilityWindow{}
+	}
+	if availability.Exceptions == nil {
+		availability.Exceptions = []AvailabilityException{}
+	}
+
+	_, err = database.AvailabilityCollection.ReplaceOne(ctx, bson.M{"_id": uid}, availability, options.Replace().SetUpsert(true))
+	if err != nil {
+		return Availability{}, err
+	}
+	return availability, nil
+}
+
+// GetFreeSlots returns the schedule of a volunteer and the periods between from
+// and to in which they can be booked, in the timezone of the schedule
+func GetFreeSlots(ctx context.Context, uid string, from time.Time, to time.Time) (FreeSlots, error) {
+	ctx, span := tracer.Start(ctx, "services.GetFreeSlots")
+	defer span.End()
+
+	if err := validateTimeRange(from, to); err != nil {
+		return FreeSlots{}, err
+	}
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	volunteer, err := findVolunteer(ctx, uid)
+	if err != nil {
+		return FreeSlots{}, err
+	}
+
+	availability, found, err := findAvailability(ctx, uid)
+	if err != nil {
+		return FreeSlots{}, err
+	}
+	if !found {
+		availability.Timezone = volunteer.Location().String()
+	}
+	location := loadLocation(availability.Timezone)
+
+	// Without a published schedule the volunteer is available at any time
+	open := []TimeSlot{{Start: from, End: to}}
+	if found {
+		open = availableSlots(availability, from, to)
+	}
+
+	busy, err := findBusySlots(ctx, bson.M{"volunteerId": uid}, TimeSlot{Start: from, End: to}, nil)
+	if err != nil {
+		return FreeSlots{}, err
+	}
+
+	slots := subtractSlots(open, busy)
+	for i := range slots {
+		slots[i] = TimeSlot{Start: slots[i].Start.In(location), End: slots[i].End.In(location)}
+	}
+
+	return FreeSlots{
+		Timezone:   availability.Timezone,
+		Weekly:     availability.Weekly,
+		Exceptions: availability.Exceptions,
+		From:       from.In(location),
+		To:         to.In(location),
+		Slots:      slots,
+	}, nil
+}
+
+// checkSchedule rejects a meeting between the volunteer and the recipient during
+// slot if either of them has another meeting then, or if the volunteer published
+// a schedule that does not cover it. Meetings matching ignore, such as the one
+// being rescheduled, do not count; nil ignores none.
+func checkSchedule(ctx context.Context, volunteerID string, recipientID string, slot TimeSlot, ignore bson.M) error {
+	availability, found, err := findAvailability(ctx, volunteerID)
+	if err != nil {
+		return err
+	}
+	if found && !covers(availableSlots(availability, slot.Start, slot.End), slot) {
+		return ErrVolunteerUnavailable
+	}
+
+	busy, err := findBusySlots(ctx, bson.M{"volunteerId": volunteerID}, slot, ignore)
+	if err != nil {
+		return err
+	}
+	if len(busy) > 0 {
+		return ErrVolunteerBusy
+	}
+
+	busy, err = findBusySlots(ctx, bson.M{"recipientId": recipientID}, slot, ignore)
+	if err != nil {
+		return err
+	}
+	if len(busy) > 0 {
+		return ErrRecipientBusy
+	}
+	return nil
+}
+
+// findVolunteer returns an active user with the volunteer role
+func findVolunteer(ctx context.Context, uid string) (User, error) {
+	user, err := findActiveUser(ctx, uid)
+	if err != nil {
+		return User{}, err
+	}
+	if user.Role != Volunteer {
+		return User{}, ErrVolunteerNotFound
+	}
+	return user, nil
+}
+
+// findAvailability returns the schedule of a volunteer, and whether they published one
+func findAvailability(ctx context.Context, uid string) (Availability, bool, error) {
+	var availability Availability
+	err := database.AvailabilityCollection.FindOne(ctx, bson.M{"_id": uid}).Decode(&availability)
+	if err == mongo.ErrNoDocuments {
+		return Availability{VolunteerID: uid, Weekly: []AvailabilityWindow{}, Exceptions: []AvailabilityException{}}, false, nil
+	}
+	if err != nil {
+		return Availability{}, false, err
+	}
+	return availability, true, nil
+}
+
+// findBusySlots returns the periods of the meetings matching filter, and not
+// ignore, that overlap slot. Cancelled meetings leave their slot free.
+func findBusySlots(ctx context.Context, filter bson.M, slot TimeSlot, ignore bson.M) ([]TimeSlot, error) {
+	filter["meetingStatus"] = bson.M{"$ne": Cancelled}
+	filter["date"] = bson.M{"$lt": slot.End}
+	filter["end"] = bson.M{"$gt": slot.Start}
+	if ignore != nil {
+		filter["$nor"] = bson.A{ignore}
+	}
+
+	cursor, err := database.MeetingsCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"date": 1, "end": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var meetings []Meeting
+	if err = cursor.All(ctx, &meetings); err != nil {
+		return nil, err
+	}
+
+	busy := make([]TimeSlot, 0, len(meetings))
+	for _, meeting := range meetings {
+		busy = append(busy, TimeSlot{Start: meeting.Date, End: meeting.End})
+	}
+	return busy, nil
+}
+
+// availableSlots returns the periods between from and to covered by the weekly
+// windows or the available exceptions, and not by the unavailable exceptions
+func availableSlots(availability Availability, from time.Time, to time.Time) []TimeSlot {
+	var open, closed []TimeSlot
+
+	// Expand the weekly windows on every local day of the range. Times of day are
+	// wall clock times, so windows keep them across daylight saving time changes.
+	location := loadLocation(availability.Timezone)
+	local := from.In(location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, window := range availability.Weekly {
+			if weekdays[window.Day] != day.Weekday() {
+				continue
+			}
+			start, _ := parseTimeOfDay(window.Start)
+			end, _ := parseTimeOfDay(window.End)
+			open = append(open, TimeSlot{Start: atTimeOfDay(day, start), End: atTimeOfDay(day, end)})
+		}
+	}
+
+	for _, exception := range availability.Exceptions {
+		slot := TimeSlot{Start: exception.Start, End: exception.End}
+		if exception.Available {
+			open = append(open, slot)
+		} else {
+			closed = append(closed, slot)
+		}
+	}
+
+	return subtractSlots(clipSlots(mergeSlots(open), from, to), closed)
+}
+
+// mergeSlots sorts slots and joins those that overlap or touch
+func mergeSlots(slots []TimeSlot) []TimeSlot {
+	sorted := append([]TimeSlot(nil), slots...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	merged := []TimeSlot{}
+	for _, slot := range sorted {
+		if !slot.End.After(slot.Start) {
+			continue
+		}
+		if last := len(merged) - 1; last >= 0 && !slot.Start.After(merged[last].End) {
+			if slot.End.After(merged[last].End) {
+				merged[last].End = slot.End
+			}
+			continue
+		}
+		merged = append(merged, slot)
+	}
+	return merged
+}
+
+// subtractSlots returns the parts of slots not covered by any of removed
+func subtractSlots(slots []TimeSlot, removed []TimeSlot) []TimeSlot {
+	removed = mergeSlots(removed)
+
+	remaining := []TimeSlot{}
+	for _, slot := range mergeSlots(slots) {
+		for _, r := range removed {
+			if !r.End.After(slot.Start) || !r.Start.Before(slot.End) {
+				continue
+			}
+			if r.Start.After(slot.Start) {
+				remaining = append(remaining, TimeSlot{Start: slot.Start, End: r.Start})
+			}
+			slot.Start = r.End
+			if !slot.Start.Before(slot.End) {
+				break
+			}
+		}
+		if slot.Start.Before(slot.End) {
+			remaining = append(remaining, slot)
+		}
+	}
+	return remaining
+}
+
+// clipSlots cuts merged slots to the period between from and to
+func clipSlots(slots []TimeSlot, from time.Time, to time.Time) []TimeSlot {
+	clipped := []TimeSlot{}
+	for _, slot := range slots {
+		if slot.Start.Before(from) {
+			slot.Start = from
+		}
+		if slot.End.After(to) {
+			slot.End = to
+		}
+		if slot.Start.Before(slot.End) {
+			clipped = append(clipped, slot)
+		}
+	}
+	return clipped
+}
+
+// covers tells whether one of merged slots contains the whole of slot
+func covers(slots []TimeSlot, slot TimeSlot) bool {
+	for _, s := range slots {
+		if !s.Start.After(slot.Start) && !slot.End.After(s.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// atTimeOfDay returns the wall clock time of day on the local midnight day. The end
+// of the day is the next midnight, which is not always 24 hours later.
+func atTimeOfDay(day time.Time, timeOfDay time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, int(timeOfDay/time.Minute), 0, 0, day.Location())
+}
+
+// parseTimeOfDay parses a time of day such as 09:30 into the time since midnight.
+// 24:00 is accepted as the end of the day.
+func parseTimeOfDay(value string) (time.Duration, bool) {
+	if value == "24:00" {
+		return 24 * time.Hour, true
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// weekTime returns a time in the week starting on Monday 2026-10-19, in UTC.
+// Day 0 is the Monday, day -1 the Sunday before.
+func weekTime(day int, hour int, minute int) time.Time {
+	return time.Date(2026, time.October, 19+day, hour, minute, 0, 0, time.UTC)
+}
+
+// equalSlots compares slots by instant, whatever their location
+func equalSlots(a []TimeSlot, b []TimeSlot) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Start.Equal(b[i].Start) || !a[i].End.Equal(b[i].End) {
+			return false
+		}
+	}
+	return true
+}
+
+func slot(startDay, startHour, startMinute, endDay, endHour, endMinute int) TimeSlot {
+	return TimeSlot{Start: weekTime(startDay, startHour, startMinute), End: weekTime(endDay, endHour, endMinute)}
+}
+
+var testAvailability = Availability{
+	Weekly: []AvailabilityWindow{
+		{Day: "MONDAY", Start: "14:00", End: "18:00"},
+		{Day: "MONDAY", Start: "09:00", End: "12:00"},
+		{Day: "TUESDAY", Start: "09:00", End: "10:00"},
+	},
+	Exceptions: []AvailabilityException{
+		{Start: weekTime(0, 10, 0), End: weekTime(0, 11, 0)},                  // unavailable on Monday morning
+		{Start: weekTime(2, 20, 0), End: weekTime(2, 21, 0), Available: true}, // available on Wednesday evening
+	},
+}
+
+func TestAvailableSlots(t *testing.T) {
+	tests := []struct {
+		name         string
+		availability Availability
+		from         time.Time
+		to           time.Time
+		want         []TimeSlot
+	}{
+		{
+			name:         "weekly windows and exceptions",
+			availability: testAvailability,
+			from:         weekTime(0, 0, 0),
+			to:           weekTime(3, 0, 0),
+			want: []TimeSlot{
+				slot(0, 9, 0, 0, 10, 0),
+				slot(0, 11, 0, 0, 12, 0),
+				slot(0, 14, 0, 0, 18, 0),
+				slot(1, 9, 0, 1, 10, 0),
+				slot(2, 20, 0, 2, 21, 0),
+			},
+		},
+		{
+			name:         "cut to the range",
+			availability: testAvailability,
+			from:         weekTime(0, 9, 30),
+			to:           weekTime(0, 15, 0),
+			want:         []TimeSlot{slot(0, 9, 30, 0, 10, 0), slot(0, 11, 0, 0, 12, 0), slot(0, 14, 0, 0, 15, 0)},
+		},
+		{
+			name: "overlapping windows are merged",
+			availability: Availability{Weekly: []AvailabilityWindow{
+				{Day: "MONDAY", Start: "09:00", End: "12:00"},
+				{Day: "MONDAY", Start: "11:00", End: "13:00"},
+			}},
+			from: weekTime(0, 0, 0),
+			to:   weekTime(1, 0, 0),
+			want: []TimeSlot{slot(0, 9, 0, 0, 13, 0)},
+		},
+		{
+			name: "windows meeting at midnight are joined",
+			availability: Availability{Weekly: []AvailabilityWindow{
+				{Day: "SUNDAY", Start: "22:00", End: "24:00"},
+				{Day: "MONDAY", Start: "00:00", End: "02:00"},
+			}},
+			from: weekTime(-1, 20, 0),
+			to:   weekTime(0, 12, 0),
+			want: []TimeSlot{slot(-1, 22, 0, 0, 2, 0)},
+		},
+		{
+			name:         "no windows",
+			availability: Availability{},
+			from:         weekTime(0, 0, 0),
+			to:           weekTime(7, 0, 0),
+			want:         []TimeSlot{},
+		},
+		{
+			// Paris leaves summer time on Sunday 2026-10-25
+			name: "windows follow daylight saving time",
+			availability: Availability{Timezone: "Europe/Paris", Weekly: []AvailabilityWindow{
+				{Day: "MONDAY", Start: "09:00", End: "10:00"},
+			}},
+			from: weekTime(0, 0, 0),
+			to:   weekTime(8, 0, 0),
+			want: []TimeSlot{slot(0, 7, 0, 0, 8, 0), slot(7, 8, 0, 7, 9, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := availableSlots(tt.availability, tt.from, tt.to); !equalSlots(got, tt.want) {
+				t.Errorf("availableSlots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubtractSlots(t *testing.T) {
+	morning := []TimeSlot{slot(0, 9, 0, 0, 12, 0)}
+
+	tests := []struct {
+		name string
+		busy []TimeSlot
+		want []TimeSlot
+	}{
+		{"no meetings", nil, morning},
+		{
+			"meetings inside and across the end",
+			[]TimeSlot{slot(0, 11, 30, 0, 13, 0), slot(0, 10, 0, 0, 10, 30)},
+			[]TimeSlot{slot(0, 9, 0, 0, 10, 0), slot(0, 10, 30, 0, 11, 30)},
+		},
+		{"meetings touching the slot", []TimeSlot{slot(0, 8, 0, 0, 9, 0), slot(0, 12, 0, 0, 13, 0)}, morning},
+		{"meeting covering the slot", []TimeSlot{slot(0, 8, 0, 0, 12, 0)}, []TimeSlot{}},
+		{"overlapping meetings", []TimeSlot{slot(0, 9, 0, 0, 10, 0), slot(0, 9, 30, 0, 10, 30)}, []TimeSlot{slot(0, 10, 30, 0, 12, 0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subtractSlots(morning, tt.busy); !equalSlots(got, tt.want) {
+				t.Errorf("subtractSlots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// A meeting can be booked if the availability of the volunteer covers all of it
+func TestAvailabilityCoversMeetings(t *testing.T) {
+	availability := testAvailability
+	availability.Weekly = append(availability.Weekly, AvailabilityWindow{Day: "MONDAY", Start: "12:00", End: "13:00"})
+
+	tests := []struct {
+		name    string
+		meeting TimeSlot
+		want    bool
+	}{
+		{"inside a window", slot(0, 9, 0, 0, 10, 0), true},
+		{"across windows that touch", slot(0, 11, 30, 0, 12, 30), true},
+		{"past the end of a window", slot(0, 12, 30, 0, 14, 30), false},
+		{"during an unavailable exception", slot(0, 9, 30, 0, 10, 30), false},
+		{"during an available exception", slot(2, 20, 0, 2, 21, 0), true},
+		{"on a day without windows", slot(3, 9, 0, 3, 10, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open := availableSlots(availability, tt.meeting.Start, tt.meeting.End)
+			if got := covers(open, tt.meeting); got != tt.want {
+				t.Errorf("covers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAvailabilityValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		availability NewAvailability
+		fields       []string
+	}{
+		{"valid", NewAvailability{Weekly: testAvailability.Weekly, Exceptions: testAvailability.Exceptions}, nil},
+		{"until midnight", NewAvailability{Weekly: []AvailabilityWindow{{Day: "SUNDAY", Start: "20:00", End: "24:00"}}}, nil},
+		{"unknown day", NewAvailability{Weekly: []AvailabilityWindow{{Day: "MON", Start: "09:00", End: "10:00"}}}, []string{"weekly[0].day"}},
+		{"malformed times", NewAvailability{Weekly: []AvailabilityWindow{{Day: "MONDAY", Start: "9am", End: "25:00"}}}, []string{"weekly[0].start", "weekly[0].end"}},
+		{"starting at midnight at the end of the day", NewAvailability{Weekly: []AvailabilityWindow{{Day: "MONDAY", Start: "24:00", End: "24:00"}}}, []string{"weekly[0].start", "weekly[0].end"}},
+		{"ending before it starts", NewAvailability{Weekly: []AvailabilityWindow{{Day: "MONDAY", Start: "10:00", End: "09:00"}}}, []string{"weekly[0].end"}},
+		{"unknown timezone", NewAvailability{Timezone: "Europe/Lyon"}, []string{"timezone"}},
+		{"exception ending before it starts", NewAvailability{Exceptions: []AvailabilityException{{Start: weekTime(0, 10, 0), End: weekTime(0, 9, 0)}}}, []string{"exceptions[0].end"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := invalidFields(t, tt.availability.Validate()); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("Validate() fields = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"neighborguard/pkg/database"
+	"neighborguard/pkg/ical"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// How long past meetings stay in calendar feeds
+const calendarHistory = 90 * 24 * time.Hour
+
+// How often subscribed calendar clients are asked to fetch the feed again
+const calendarRefreshInterval = time.Hour
+
+// Length of calendar tokens, in random bytes
+const calendarTokenBytes = 32
+
+// CreateCalendarToken creates the token of a user's calendar feed and returns it.
+// Only its hash is stored, so a new token replaces and revokes the previous one.
+func CreateCalendarToken(ctx context.Context, actorID string, uid string) (string, error) {
+	ctx, span := tracer.Start(ctx, "services.CreateCalendarToken")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	if err := authorizeAccountAccess(ctx, actorID, uid); err != nil {
+		return "", err
+	}
+	if _, err := findActiveUser(ctx, uid); err != nil {
+		return "", err
+	}
+
+	random := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(random)
+
+	result, err := database.UsersCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": uid, "deletedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"calendarTokenHash": hashCalendarToken(token)}},
+	)
+	if err != nil {
+		return "", err
+	}
+	if result.MatchedCount == 0 {
+		return "", ErrUserNotFound
+	}
+
+	if err := recordAudit(ctx, actorID, uid, AuditCalendarTokenCreated); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RevokeCalendarToken revokes the token of a user's calendar feed, if any
+func RevokeCalendarToken(ctx context.Context, actorID string, uid string) error {
+	ctx, span := tracer.Start(ctx, "services.RevokeCalendarToken")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	if err := authorizeAccountAccess(ctx, actorID, uid); err != nil {
+		return err
+	}
+
+	result, err := database.UsersCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": uid, "deletedAt": bson.M{"$exists": false}},
+		bson.M{"$unset": bson.M{"calendarTokenHash": ""}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return recordAudit(ctx, actorID, uid, AuditCalendarTokenRevoked)
+}
+
+// CalendarFeed returns the calendar of a user's meetings, from calendarHistory ago
+// on, if token is the current token of their feed
+func CalendarFeed(ctx context.Context, uid string, token string) (ical.Calendar, error) {
+	ctx, span := tracer.Start(ctx, "services.CalendarFeed")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	// A wrong token and a missing user look the same, so tokens cannot be probed
+	if token == "" {
+		return ical.Calendar{}, ErrCalendarNotFound
+	}
+	var user User
+	err := findUser(ctx, bson.M{
+		"_id":               uid,
+		"calendarTokenHash": hashCalendarToken(token),
+		"deletedAt":         bson.M{"$exists": false},
+	}, &user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ical.Calendar{}, ErrCalendarNotFound
+		}
+		return ical.Calendar{}, err
+	}
+
+	cursor, err := database.MeetingsCollection.Find(ctx,
+		bson.M{
+			"$or": []bson.M{
+				{"recipientId": uid},
+				{"volunteerId": uid},
+			},
+			"end": bson.M{"$gte": time.Now().Add(-calendarHistory)},
+		},
+		options.Find().SetSort(bson.M{"date": 1}),
+	)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var meetings []Meeting
+	if err = cursor.All(ctx, &meetings); err != nil {
+		return ical.Calendar{}, err
+	}
+
+	return meetingCalendar(ctx, meetings)
+}
+
+// MeetingCalendar returns a calendar with a single meeting, for its participants
+// and administrators
+func MeetingCalendar(ctx context.Context, actorID string, meetingID string) (ical.Calendar, error) {
+	ctx, span := tracer.Start(ctx, "services.MeetingCalendar")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	if actorID == "" {
+		return ical.Calendar{}, ErrNotAuthenticated
+	}
+
+	var meeting Meeting
+	err := database.MeetingsCollection.FindOne(ctx, bson.M{"_id": meetingID}).Decode(&meeting)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ical.Calendar{}, ErrMeetingNotFound
+		}
+		return ical.Calendar{}, err
+	}
+
+	if actorID != meeting.RecipientID && actorID != meeting.VolunteerID {
+		actor, err := findActiveUser(ctx, actorID)
+		if err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				return ical.Calendar{}, ErrNotAuthenticated
+			}
+			return ical.Calendar{}, err
+		}
+		if actor.Role != Admin {
+			return ical.Calendar{}, ErrMeetingAccessDenied
+		}
+	}
+
+	return meetingCalendar(ctx, []Meeting{meeting})
+}
+
+// meetingCalendar returns a calendar with an event for each meeting. Events show
+// the services by name and the recipient's location at the precision they chose.
+func meetingCalendar(ctx context.Context, meetings []Meeting) (ical.Calendar, error) {
+	catalogue, err := loadServiceCatalogue(ctx)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+
+	calendar := ical.Calendar{
+		ProdID:          "-//NeighborGuard//Meetings//EN",
+		Name:            "NeighborGuard meetings",
+		RefreshInterval: calendarRefreshInterval,
+		Events:          make([]ical.Event, 0, len(meetings)),
+	}
+
+	// Recipients often have several meetings in a feed
+	recipients := make(map[string]User)
+	for _, meeting := range meetings {
+		recipient, loaded := recipients[meeting.RecipientID]
+		if !loaded {
+			if err := findUser(ctx, bson.M{"_id": meeting.RecipientID}, &recipient); err != nil && err != mongo.ErrNoDocuments {
+				return ical.Calendar{}, err
+			}
+			recipients[meeting.RecipientID] = recipient
+		}
+
+		event := ical.Event{
+			UID:      meeting.ID + "@neighborguard",
+			Start:    meeting.Date,
+			End:      meeting.End,
+			Modified: meeting.UpdatedAt,
+			Sequence: max(meeting.Version-1, 0), // meetings stored before versioning have version 0
+			Summary:  serviceNames(catalogue, meeting.Services),
+			Location: recipient.Address.City,
+			Status:   ical.Confirmed,
+		}
+		// Deleted recipients have no location left
+		if recipient.ID != "" && recipient.DeletedAt == nil {
+			location := FuzzLocation(recipient)
+			event.Geo = &ical.Geo{Latitude: location.Latitude, Longitude: location.Longitude}
+		}
+		if meeting.MeetingStatus == Cancelled {
+			event.Status = ical.Cancelled
+		}
+		calendar.Events = append(calendar.Events, event)
+	}
+
+	return calendar, nil
+}
+
+// serviceNames returns the English names of services, or their IDs if the
+// catalogue has none, as a list
+func serviceNames(catalogue map[string]ServiceDefinition, services []string) string {
+	names := make([]string, 0, len(services))
+	for _, service := range services {
+		name := catalogue[service].Names["en"]
+		if name == "" {
+			name = service
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// hashCalendarToken returns the stored form of a calendar token
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
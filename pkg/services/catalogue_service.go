@@ -0,0 +1,610 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"neighborguard/pkg/database"
+	"neighborguard/pkg/validation"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ID of the catalogue entry for the periodic wellbeing check of recipients
+const GeneralCheck = "general-check"
+
+// Duration of services missing from the catalogue, in minutes
+const defaultMeetingMinutes = 30
+
+// Catalogue IDs are lowercase slugs, which are also safe MongoDB field names
+var serviceIDPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Characters of legacy service names that cannot appear in a catalogue ID
+var legacyServiceSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+type NewServiceDefinition struct {
+	ID                        string            `json:"id"`
+	Names                     map[string]string `json:"names"` //map[LanguageCode]DisplayName
+	Category                  string            `json:"category"`
+	DefaultDurationMinutes    int               `json:"defaultDurationMinutes"`
+	Recurring                 bool              `json:"recurring"`
+	RequiresVerifiedVolunteer bool              `json:"requiresVerifiedVolunteer"`
+}
+
+// ServiceDefinition is an entry of the service catalogue. Users and meetings
+// reference services by the ID of their catalogue entry.
+type ServiceDefinition struct {
+	ID                        string            `json:"id" bson:"_id"`
+	Names                     map[string]string `json:"names" bson:"names"`
+	Category                  string            `json:"category" bson:"category"`
+	DefaultDurationMinutes    int               `json:"defaultDurationMinutes" bson:"defaultDurationMinutes"`
+	Recurring                 bool              `json:"recurring" bson:"recurring"`
+	RequiresVerifiedVolunteer bool              `json:"requiresVerifiedVolunteer" bson:"requiresVerifiedVolunteer"`
+	CreatedAt                 time.Time         `json:"createdAt" bson:"createdAt"`
+	UpdatedAt                 time.Time         `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Entries created on startup if they are missing from the catalogue
+var defaultServiceDefinitions = []NewServiceDefinition{
+	{
+		ID:                     GeneralCheck,
+		Names:                  map[string]string{"en": "General Check"},
+		Category:               "wellbeing",
+		DefaultDurationMinutes: 30,
+		Recurring:              true,
+	},
+}
+
+// Validate checks a catalogue entry payload
+func (d NewServiceDefinition) Validate() error {
+	v := &validation.Validator{}
+
+	v.Check(serviceIDPattern.MatchString(d.ID), "id", "must be a lowercase slug such as general-check")
+	v.Check(len(d.ID) <= maxServiceNameLength, "id", fmt.Sprintf("must be at most %d characters", maxServiceNameLength))
+	v.Check(len(d.Names) > 0, "names", "must contain at least one display name")
+	for language, name := range d.Names {
+		v.Required(fmt.Sprintf("names.%s", language), name)
+	}
+	v.Required("category", d.Category)
+	v.IntRange("defaultDurationMinutes", d.DefaultDurationMinutes, 1, 24*60)
+
+	return invalid(v.Err())
+}
+
+// CreateServiceDefinition adds an entry to the catalogue. Only administrators may
+// change the catalogue.
+func CreateServiceDefinition(ctx context.Context, actorID string, newDefinition NewServiceDefinition) (ServiceDefinition, error) {
+	ctx, span := tracer.Start(ctx, "services.CreateServiceDefinition")
+	defer span.End()
+
+	if err := AuthorizeAdmin(ctx, actorID); err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	// Reject invalid payloads before touching the database
+	if err := newDefinition.Validate(); err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	if err := checkServiceNames(ctx, newDefinition.ID, newDefinition.Names); err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	now := time.Now()
+	definition := ServiceDefinition{
+		ID:                        newDefinition.ID,
+		Names:                     newDefinition.Names,
+		Category:                  newDefinition.Category,
+		DefaultDurationMinutes:    newDefinition.DefaultDurationMinutes,
+		Recurring:                 newDefinition.Recurring,
+		RequiresVerifiedVolunteer: newDefinition.RequiresVerifiedVolunteer,
+		CreatedAt:                 now,
+		UpdatedAt:                 now,
+	}
+
+	// The ID is the document key, so duplicates are rejected by MongoDB
+	_, err := database.ServicesCollection.InsertOne(ctx, definition)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ServiceDefinition{}, ErrServiceExists
+		}
+		return ServiceDefinition{}, err
+	}
+
+	return definition, nil
+}
+
+func GetServiceDefinitions(ctx context.Context) ([]ServiceDefinition, error) {
+	ctx, span := tracer.Start(ctx, "services.GetServiceDefinitions")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	cursor, err := database.ServicesCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	definitions := []ServiceDefinition{}
+	if err = cursor.All(ctx, &definitions); err != nil {
+		return nil, err
+	}
+
+	return definitions, nil
+}
+
+func GetServiceDefinition(ctx context.Context, id string) (ServiceDefinition, error) {
+	ctx, span := tracer.Start(ctx, "services.GetServiceDefinition")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	var definition ServiceDefinition
+	err := database.ServicesCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&definition)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ServiceDefinition{}, ErrServiceNotFound
+		}
+		return ServiceDefinition{}, err
+	}
+
+	return definition, nil
+}
+
+// UpdateServiceDefinition changes the display names and settings of a catalogue
+// entry. Only administrators may change the catalogue.
+func UpdateServiceDefinition(ctx context.Context, actorID string, id string, updatedDefinition NewServiceDefinition) (ServiceDefinition, error) {
+	ctx, span := tracer.Start(ctx, "services.UpdateServiceDefinition")
+	defer span.End()
+
+	if err := AuthorizeAdmin(ctx, actorID); err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	// The ID cannot be changed since users and meetings reference it
+	updatedDefinition.ID = id
+	if err := updatedDefinition.Validate(); err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	if err := checkServiceNames(ctx, id, updatedDefinition.Names); err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	var definition ServiceDefinition
+	err := database.ServicesCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{
+			"names":                     updatedDefinition.Names,
+			"category":                  updatedDefinition.Category,
+			"defaultDurationMinutes":    updatedDefinition.DefaultDurationMinutes,
+			"recurring":                 updatedDefinition.Recurring,
+			"requiresVerifiedVolunteer": updatedDefinition.RequiresVerifiedVolunteer,
+			"updatedAt":                 time.Now(),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&definition)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ServiceDefinition{}, ErrServiceNotFound
+		}
+		return ServiceDefinition{}, err
+	}
+
+	return definition, nil
+}
+
+// DeleteServiceDefinition removes a catalogue entry that no user or meeting references.
+// Only administrators may change the catalogue.
+func DeleteServiceDefinition(ctx context.Context, actorID string, id string) error {
+	ctx, span := tracer.Start(ctx, "services.DeleteServiceDefinition")
+	defer span.End()
+
+	if err := AuthorizeAdmin(ctx, actorID); err != nil {
+		return err
+	}
+
+	// The ID is used as a field path below, so only well-formed IDs can exist
+	if !serviceIDPattern.MatchString(id) {
+		return ErrServiceNotFound
+	}
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	// Refuse to orphan the references held by users and meetings
+	users, err := database.UsersCollection.CountDocuments(ctx, bson.M{"services." + id: bson.M{"$exists": true}})
+	if err != nil {
+		return err
+	}
+	meetings, err := database.MeetingsCollection.CountDocuments(ctx, bson.M{"services": id})
+	if err != nil {
+		return err
+	}
+	if users > 0 || meetings > 0 {
+		return ErrServiceInUse
+	}
+
+	result, err := database.ServicesCollection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrServiceNotFound
+	}
+
+	return nil
+}
+
+// EnsureServiceCatalogue creates the default catalogue entries if they are missing
+// and rewrites service names stored before the catalogue existed to catalogue IDs,
+// adding an entry for each stored name that no entry resolves
+func EnsureServiceCatalogue(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "services.EnsureServiceCatalogue")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	for _, d := range defaultServiceDefinitions {
+		_, err := database.ServicesCollection.UpdateOne(
+			ctx,
+			bson.M{"_id": d.ID},
+			bson.M{"$setOnInsert": bson.M{
+				"names":                     d.Names,
+				"category":                  d.Category,
+				"defaultDurationMinutes":    d.DefaultDurationMinutes,
+				"recurring":                 d.Recurring,
+				"requiresVerifiedVolunteer": d.RequiresVerifiedVolunteer,
+				"createdAt":                 now,
+				"updatedAt":                 now,
+			}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	definitions, err := loadServiceCatalogue(ctx)
+	if err != nil {
+		return err
+	}
+
+	names, err := storedServiceNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Rename legacy keys such as "General Check" to their catalogue ID, adding an
+	// entry for names no entry knows so that users keeping them can still be saved
+	for _, name := range names {
+		if _, ok := definitions[name]; ok {
+			continue
+		}
+		if strings.ContainsAny(name, ".$") {
+			slog.WarnContext(ctx, "Skipped service that cannot be migrated", "name", name)
+			continue
+		}
+
+		id, ok := resolveServiceID(definitions, name)
+		if !ok {
+			definition := ServiceDefinition{
+				ID:                     legacyServiceID(definitions, name),
+				Names:                  map[string]string{"en": strings.TrimSpace(name)},
+				Category:               "other",
+				DefaultDurationMinutes: defaultMeetingMinutes,
+				CreatedAt:              now,
+				UpdatedAt:              now,
+			}
+			_, err := database.ServicesCollection.UpdateOne(
+				ctx,
+				bson.M{"_id": definition.ID},
+				bson.M{"$setOnInsert": bson.M{
+					"names":                     definition.Names,
+					"category":                  definition.Category,
+					"defaultDurationMinutes":    definition.DefaultDurationMinutes,
+					"recurring":                 definition.Recurring,
+					"requiresVerifiedVolunteer": definition.RequiresVerifiedVolunteer,
+					"createdAt":                 now,
+					"updatedAt":                 now,
+				}},
+				options.Update().SetUpsert(true),
+			)
+			if err != nil {
+				return err
+			}
+			slog.InfoContext(ctx, "Added service to the catalogue", "name", name, "serviceId", definition.ID)
+
+			definitions[definition.ID] = definition
+			id = definition.ID
+		}
+
+		users, err := database.UsersCollection.UpdateMany(
+			ctx,
+			bson.M{"services." + name: bson.M{"$exists": true}},
+			bson.M{"$rename": bson.M{"services." + name: "services." + id}, "$inc": bson.M{"version": 1}},
+		)
+		if err != nil {
+			return err
+		}
+
+		meetings, err := database.MeetingsCollection.UpdateMany(
+			ctx,
+			bson.M{"services": name},
+			bson.M{"$set": bson.M{"services.$[service]": id}},
+			options.Update().SetArrayFilters(options.ArrayFilters{
+				Filters: []interface{}{bson.M{"service": name}},
+			}),
+		)
+		if err != nil {
+			return err
+		}
+
+		if users.ModifiedCount > 0 || meetings.ModifiedCount > 0 {
+			slog.InfoContext(ctx, "Migrated service", "name", name, "serviceId", id, "users", users.ModifiedCount, "meetings", meetings.ModifiedCount)
+		}
+	}
+
+	return nil
+}
+
+// storedServiceNames returns the distinct service keys of users and services of
+// meetings, sorted so that migrations always run in the same order
+func storedServiceNames(ctx context.Context) ([]string, error) {
+	cursor, err := database.UsersCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$project", Value: bson.M{"services": bson.M{"$objectToArray": "$services"}}}},
+		{{Key: "$unwind", Value: "$services"}},
+		{{Key: "$group", Value: bson.M{"_id": "$services.k"}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var keys []struct {
+		Name string `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	meetingServices, err := database.MeetingsCollection.Distinct(ctx, "services", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		seen[key.Name] = true
+	}
+	for _, service := range meetingServices {
+		if name, ok := service.(string); ok {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// legacyServiceID derives a catalogue ID from a service name stored before the
+// catalogue existed, such as "Grocery Shopping" to grocery-shopping, that no
+// entry of the catalogue has yet
+func legacyServiceID(catalogue map[string]ServiceDefinition, name string) string {
+	slug := strings.Trim(legacyServiceSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(slug) > maxServiceNameLength-4 {
+		slug = strings.TrimRight(slug[:maxServiceNameLength-4], "-")
+	}
+	if slug == "" {
+		slug = "service"
+	}
+
+	id := slug
+	for i := 2; ; i++ {
+		if _, taken := catalogue[id]; !taken {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", slug, i)
+	}
+}
+
+// loadServiceCatalogue returns every catalogue entry by ID
+func loadServiceCatalogue(ctx context.Context) (map[string]ServiceDefinition, error) {
+	cursor, err := database.ServicesCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var definitions []ServiceDefinition
+	if err = cursor.All(ctx, &definitions); err != nil {
+		return nil, err
+	}
+
+	catalogue := make(map[string]ServiceDefinition, len(definitions))
+	for _, definition := range definitions {
+		catalogue[definition.ID] = definition
+	}
+	return catalogue, nil
+}
+
+// meetingDuration returns how long a meeting takes to provide the services one
+// after the other. Services missing from the catalogue take the default duration.
+func meetingDuration(catalogue map[string]ServiceDefinition, services []string) time.Duration {
+	minutes := 0
+	for _, service := range services {
+		if definition, ok := catalogue[service]; ok {
+			minutes += definition.DefaultDurationMinutes
+		} else {
+			minutes += defaultMeetingMinutes
+		}
+	}
+	if minutes == 0 {
+		minutes = defaultMeetingMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// resolveServiceID maps a service reference to its catalogue ID. Display names
+// are accepted case-insensitively so that "general check" and "General Check"
+// both resolve to the same entry instead of splitting the data.
+func resolveServiceID(catalogue map[string]ServiceDefinition, reference string) (string, bool) {
+	if _, ok := catalogue[reference]; ok {
+		return reference, true
+	}
+
+	normalized := normalizeServiceName(reference)
+	if _, ok := catalogue[normalized]; ok {
+		return normalized, true
+	}
+
+	// Walk entries in a stable order, so a name shared by entries stored before
+	// names had to be unique always resolves to the same one
+	ids := make([]string, 0, len(catalogue))
+	for id := range catalogue {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		for _, name := range catalogue[id].Names {
+			if normalizeServiceName(name) == normalized {
+				return id, true
+			}
+		}
+	}
+	return "", false
+}
+
+// checkServiceNames rejects display names of the entry with the given ID that
+// another entry already has, in any language, or that are the ID of another
+// entry, since a reference by name must resolve to a single entry
+func checkServiceNames(ctx context.Context, id string, names map[string]string) error {
+	catalogue, err := loadServiceCatalogue(ctx)
+	if err != nil {
+		return err
+	}
+
+	taken := make(map[string]bool)
+	for otherID, other := range catalogue {
+		if otherID == id {
+			continue
+		}
+		taken[otherID] = true
+		for _, name := range other.Names {
+			taken[normalizeServiceName(name)] = true
+		}
+	}
+
+	for _, name := range names {
+		if taken[normalizeServiceName(name)] {
+			return ErrServiceNameTaken
+		}
+	}
+	return nil
+}
+
+// normalizeServiceName is the form in which display names are compared
+func normalizeServiceName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// resolveUserServices rewrites the keys of a user's services map to catalogue IDs
+func resolveUserServices(ctx context.Context, userServices map[string]MeetingAssistanceStatus) (map[string]MeetingAssistanceStatus, error) {
+	if len(userServices) == 0 {
+		return userServices, nil
+	}
+
+	catalogue, err := loadServiceCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk services in a stable order so errors are reported consistently
+	names := make([]string, 0, len(userServices))
+	for name := range userServices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	v := &validation.Validator{}
+	resolved := make(map[string]MeetingAssistanceStatus, len(userServices))
+	for _, name := range names {
+		id, ok := resolveServiceID(catalogue, name)
+		if !ok {
+			v.AddError(fmt.Sprintf("services.%s", name), "is not in the service catalogue")
+			continue
+		}
+		if _, duplicate := resolved[id]; duplicate {
+			v.AddError(fmt.Sprintf("services.%s", name), fmt.Sprintf("duplicates service %s", id))
+			continue
+		}
+		resolved[id] = userServices[name]
+	}
+
+	if err := v.Err(); err != nil {
+		return nil, invalid(err)
+	}
+	return resolved, nil
+}
+
+// resolveMeetingServices rewrites the services of a meeting to catalogue IDs and
+// checks that the volunteer is allowed to provide them
+func resolveMeetingServices(ctx context.Context, meetingServices []string, volunteer User) ([]string, error) {
+	catalogue, err := loadServiceCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validation.Validator{}
+	resolved := make([]string, 0, len(meetingServices))
+	for i, name := range meetingServices {
+		field := fmt.Sprintf("services[%d]", i)
+		id, ok := resolveServiceID(catalogue, name)
+		if !ok {
+			v.AddError(field, "is not in the service catalogue")
+			continue
+		}
+		if catalogue[id].RequiresVerifiedVolunteer && !volunteer.Verified {
+			v.AddError(field, "requires a verified volunteer")
+			continue
+		}
+		resolved = append(resolved, id)
+	}
+
+	if err := v.Err(); err != nil {
+		return nil, invalid(err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLegacyServiceID(t *testing.T) {
+	catalogue := map[string]ServiceDefinition{
+		GeneralCheck: {ID: GeneralCheck},
+		"walk":       {ID: "walk"},
+		"walk-2":     {ID: "walk-2"},
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Grocery Shopping", "grocery-shopping"},
+		{"  Help with  forms! ", "help-with-forms"},
+		{"Walk", "walk-3"},
+		{"General: check", "general-check-2"},
+		{"קניות", "service"},
+		{strings.Repeat("long ", 20), strings.TrimRight(strings.Repeat("long-", 12), "-")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := legacyServiceID(catalogue, tt.name)
+			if got != tt.want {
+				t.Errorf("legacyServiceID(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+			if !serviceIDPattern.MatchString(got) || len(got) > maxServiceNameLength {
+				t.Errorf("legacyServiceID(%q) = %q is not a valid catalogue ID", tt.name, got)
+			}
+		})
+	}
+}
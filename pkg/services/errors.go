@@ -0,0 +1,79 @@
+package services
+
+import "errors"
+
+// Kinds of failure, matched with errors.Is to choose a response
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrValidation   = errors.New("validation failed")
+
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error is a service failure of a given kind with a stable code that clients can rely on
+type Error struct {
+	Kind    error  // one of the kinds of failure above
+	Code    string // machine readable, never changes once published
+	Message string // human readable
+	Cause   error  // optional details, such as the invalid fields of a payload
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{e.Kind, e.Cause}
+	}
+	return []error{e.Kind}
+}
+
+// Failures returned by the service layer
+var (
+	ErrUserNotFound         = &Error{Kind: ErrNotFound, Code: "user_not_found", Message: "user not found"}
+	ErrRecipientNotFound    = &Error{Kind: ErrNotFound, Code: "recipient_not_found", Message: "recipient not found"}
+	ErrVolunteerNotFound    = &Error{Kind: ErrNotFound, Code: "volunteer_not_found", Message: "volunteer not found"}
+	ErrMeetingNotFound      = &Error{Kind: ErrNotFound, Code: "meeting_not_found", Message: "meeting not found"}
+	ErrCalendarNotFound     = &Error{Kind: ErrNotFound, Code: "calendar_not_found", Message: "calendar not found, the subscription may have been revoked"}
+	ErrSeriesNotFound       = &Error{Kind: ErrNotFound, Code: "series_not_found", Message: "meeting series not found"}
+	ErrServiceNotFound      = &Error{Kind: ErrNotFound, Code: "service_not_found", Message: "service not found"}
+	ErrEmailTaken           = &Error{Kind: ErrConflict, Code: "email_taken", Message: "user with this email already exists"}
+	ErrRecipientInProgress  = &Error{Kind: ErrConflict, Code: "recipient_in_progress", Message: "recipient already in progress"}
+	ErrServiceExists        = &Error{Kind: ErrConflict, Code: "service_exists", Message: "service already exists"}
+	ErrServiceNameTaken     = &Error{Kind: ErrConflict, Code: "service_name_taken", Message: "display name already names another service"}
+	ErrServiceInUse         = &Error{Kind: ErrConflict, Code: "service_in_use", Message: "service in use"}
+	ErrVolunteerUnavailable = &Error{Kind: ErrConflict, Code: "volunteer_unavailable", Message: "volunteer is not available at this time"}
+	ErrVolunteerBusy        = &Error{Kind: ErrConflict, Code: "volunteer_busy", Message: "volunteer already has a meeting at this time"}
+	ErrRecipientBusy        = &Error{Kind: ErrConflict, Code: "recipient_busy", Message: "recipient already has a meeting at this time"}
+	ErrScheduleBusy         = &Error{Kind: ErrConflict, Code: "schedule_busy", Message: "another booking of a participant is in progress, retry later"}
+	ErrMeetingCancelled     = &Error{Kind: ErrConflict, Code: "meeting_cancelled", Message: "meeting was cancelled"}
+	ErrMeetingDone          = &Error{Kind: ErrConflict, Code: "meeting_done", Message: "meeting is already done"}
+	ErrSeriesCancelled      = &Error{Kind: ErrConflict, Code: "series_cancelled", Message: "meeting series was cancelled"}
+	ErrNotAuthenticated     = &Error{Kind: ErrUnauthorized, Code: "not_authenticated", Message: "authentication required"}
+	ErrInvalidCredentials   = &Error{Kind: ErrUnauthorized, Code: "invalid_credentials", Message: "email or password is incorrect"}
+	ErrVolunteersOnly       = &Error{Kind: ErrForbidden, Code: "volunteers_only", Message: "only volunteers can use this endpoint"}
+	ErrAccountAccessDenied  = &Error{Kind: ErrForbidden, Code: "account_access_denied", Message: "only the user or an administrator can access this account"}
+	ErrAdminsOnly           = &Error{Kind: ErrForbidden, Code: "admins_only", Message: "only administrators can use this endpoint"}
+	ErrMeetingAccessDenied  = &Error{Kind: ErrForbidden, Code: "meeting_access_denied", Message: "only the participants or an administrator can access this meeting"}
+	ErrSeriesAccessDenied   = &Error{Kind: ErrForbidden, Code: "series_access_denied", Message: "only the participants or an administrator can access this series"}
+	ErrMeetingVolunteerOnly = &Error{Kind: ErrForbidden, Code: "meeting_volunteer_only", Message: "only the volunteer of a meeting can create it"}
+	ErrSeriesVolunteerOnly  = &Error{Kind: ErrForbidden, Code: "series_volunteer_only", Message: "only the volunteer of a series can create it"}
+	ErrConcurrentUpdate     = &Error{Kind: ErrConflict, Code: "concurrent_update", Message: "resource was modified by another request, retry"}
+	ErrVersionMismatch      = &Error{Kind: ErrPreconditionFailed, Code: "version_mismatch", Message: "resource does not match the If-Match version"}
+	ErrMalformedPatch       = &Error{Kind: ErrValidation, Code: "malformed_patch", Message: "merge patch must be a JSON object"}
+)
+
+// invalid wraps the field errors of a rejected payload, or returns nil if there are none
+func invalid(fieldErrors error) error {
+	if fieldErrors == nil {
+		return nil
+	}
+	return &Error{Kind: ErrValidation, Code: "validation_failed", Message: "validation failed", Cause: fieldErrors}
+}
@@ -4,322 +4,666 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"neighborguard/pkg/database"
+	"neighborguard/pkg/metrics"
+	"neighborguard/pkg/validation"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MeetingStatus string
 
 const (
-	IsPicked MeetingStatus = "IS_PICKED"
-	Done     MeetingStatus = "DONE"
+	IsPicked  MeetingStatus = "IS_PICKED"
+	Done      MeetingStatus = "DONE"
+	Cancelled MeetingStatus = "CANCELLED" // kept so calendars and participants learn about it
 )
 
 type NewMeeting struct {
 	Recipient     User          `json:"recipient"`
 	Volunteer     User          `json:"volunteer"`
-	Date          int64         `json:"date"`
-	Services      []string      `json:"services"` //list of services that will be provided on this meeting
+	Date          time.Time     `json:"date"`     // start; the end follows from the duration of the services
+	Services      []string      `json:"services"` //list of service catalogue IDs that will be provided on this meeting
 	MeetingStatus MeetingStatus `json:"meetingStatus"`
 }
 
 type Meeting struct {
 	ID            string        `json:"uid" bson:"_id,omitempty"`
-	Recipient     User          `json:"recipient" bson:"-"`       // Not stored directly in MongoDB
-	Volunteer     User          `json:"volunteer" bson:"-"`       // Not stored directly in MongoDB
-	RecipientID   string        `json:"-" bson:"recipientId"`     // Store only the ID in MongoDB
-	VolunteerID   string        `json:"-" bson:"volunteerId"`     // Store only the ID in MongoDB
-	Date          int64         `json:"date" bson:"date"`
+	Recipient     User          `json:"recipient" bson:"-"`   // Not stored directly in MongoDB
+	Volunteer     User          `json:"volunteer" bson:"-"`   // Not stored directly in MongoDB
+	RecipientID   string        `json:"-" bson:"recipientId"` // Store only the ID in MongoDB
+	VolunteerID   string        `json:"-" bson:"volunteerId"` // Store only the ID in MongoDB
+	Date          time.Time     `json:"date" bson:"date"`     // start
+	End           time.Time     `json:"end" bson:"end"`       // when the services are expected to be done
 	Services      []string      `json:"services" bson:"services"`
 	MeetingStatus MeetingStatus `json:"meetingStatus" bson:"meetingStatus"`
 	CreatedAt     time.Time     `json:"createdAt" bson:"createdAt"`
 	UpdatedAt     time.Time     `json:"updatedAt" bson:"updatedAt"`
+	SeriesID      string        `json:"seriesId,omitempty" bson:"seriesId,omitempty"` // series the meeting is an occurrence of, if any
+	Occurrence    time.Time     `json:"-" bson:"occurrence,omitempty"`                // time the series planned it at
+	Version       int64         `json:"version" bson:"version"`                       // incremented on every update
 }
 
+// CreateMeeting claims services of a recipient for a volunteer at the given date.
+// Only the volunteer of the meeting may create it.
+func CreateMeeting(ctx context.Context, actorID string, newMeeting NewMeeting) (Meeting, error) {
+	ctx, span := tracer.Start(ctx, "services.CreateMeeting")
+	defer span.End()
+
+	// Reject invalid payloads before touching the database
+	if err := newMeeting.Validate(); err != nil {
+		return Meeting{}, err
+	}
+
+	// Volunteers claim services for themselves, nobody books them on their behalf
+	if actorID == "" {
+		return Meeting{}, ErrNotAuthenticated
+	}
+	if actorID != newMeeting.Volunteer.ID {
+		return Meeting{}, ErrMeetingVolunteerOnly
+	}
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	now := time.Now()
+
+	// Keep the services the recipient still needs from the volunteer
+	claim, err := prepareClaim(ctx, newMeeting.Recipient.ID, newMeeting.Volunteer.ID, newMeeting.Services)
+	if err != nil {
+		return Meeting{}, err
+	}
+	slot := TimeSlot{Start: newMeeting.Date, End: newMeeting.Date.Add(claim.duration)}
+
+	// Hold the schedules of both participants until the meeting is saved
+	release, err := lockSchedules(ctx, claim.volunteer.ID, claim.recipient.ID)
+	if err != nil {
+		return Meeting{}, err
+	}
+	defer release()
+
+	// Reject double bookings and times the volunteer is not available
+	if err := checkSchedule(ctx, claim.volunteer.ID, claim.recipient.ID, slot, nil); err != nil {
+		return Meeting{}, err
+	}
+
+	if err := claim.commit(ctx, now); err != nil {
+		return Meeting{}, err
+	}
+
+	// Create a new meeting with reference IDs and a new MongoDB ObjectID
+	meeting := Meeting{
+		ID:            primitive.NewObjectID().Hex(),
+		RecipientID:   claim.recipient.ID,
+		VolunteerID:   claim.volunteer.ID,
+		Date:          slot.Start,
+		End:           slot.End,
+		Services:      claim.services,
+		MeetingStatus: IsPicked, // New meetings always start as picked
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Version:       1,
+	}
+
+	// For API response, include the full user objects
+	meeting.Recipient = claim.recipient
+	meeting.Volunteer = claim.volunteer
+
+	if err := insertMeeting(ctx, meeting); err != nil {
+		return Meeting{}, err
+	}
+
+	metrics.MeetingsCreated.Inc()
+	observeTimeToClaim(claim.recipient, claim.services, now)
+
+	return meeting, nil
+}
+
+// meetingClaim is what a volunteer takes on from a recipient by creating a meeting,
+// or a series of them
+type meetingClaim struct {
+	recipient User
+	volunteer User
+	services  []string      // catalogue IDs of the services the recipient needs and nobody provides yet
+	duration  time.Duration // that the services take one after the other
+}
 
+// prepareClaim loads the participants of a new meeting and keeps the services
+// the recipient still needs, failing if there are none
+func prepareClaim(ctx context.Context, recipientID string, volunteerID string, requested []string) (meetingClaim, error) {
+	// Verify the recipient exists in MongoDB
+	var recipient User
+	err := findUser(ctx, bson.M{"_id": recipientID, "deletedAt": bson.M{"$exists": false}}, &recipient)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return meetingClaim{}, ErrRecipientNotFound
+		}
+		return meetingClaim{}, err
+	}
+
+	// Verify the volunteer exists in MongoDB
+	var volunteer User
+	err = findUser(ctx, bson.M{"_id": volunteerID, "deletedAt": bson.M{"$exists": false}}, &volunteer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return meetingClaim{}, ErrVolunteerNotFound
+		}
+		return meetingClaim{}, err
+	}
+
+	// Verify the users have the roles the meeting expects
+	if err := validateMeetingParticipants(recipient, volunteer); err != nil {
+		return meetingClaim{}, err
+	}
+
+	// Reference services by their catalogue ID
+	requestedServices, err := resolveMeetingServices(ctx, requested, volunteer)
+	if err != nil {
+		return meetingClaim{}, err
+	}
+
+	// Identify services that are available (not already InProgress)
+	var availableServices []string
+	for _, service := range requestedServices {
+		if status, exists := recipient.Services[service]; exists && status != InProgress {
+			availableServices = append(availableServices, service)
+		}
+	}
+
+	// If no services are available, return an error
+	if len(availableServices) == 0 {
+		return meetingClaim{}, ErrRecipientInProgress
+	}
+
+	// The meeting lasts as long as its services take one after the other
+	catalogue, err := loadServiceCatalogue(ctx)
+	if err != nil {
+		return meetingClaim{}, err
+	}
+
+	return meetingClaim{
+		recipient: recipient,
+		volunteer: volunteer,
+		services:  availableServices,
+		duration:  meetingDuration(catalogue, availableServices),
+	}, nil
+}
 
-func CreateMeeting(newMeeting NewMeeting) (Meeting, error) {
-    // Create a context with timeout
-    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-    defer cancel()
-
-    now := time.Now()
-    
-    // Verify the recipient exists in MongoDB
-    var recipient User
-    err := database.UsersCollection.FindOne(ctx, bson.M{"_id": newMeeting.Recipient.ID}).Decode(&recipient)
-    if err != nil {
-        if err == mongo.ErrNoDocuments {
-            return Meeting{}, errors.New("recipient not found")
-        }
-        return Meeting{}, err
-    }
-
-    // Verify the volunteer exists in MongoDB
-    var volunteer User
-    err = database.UsersCollection.FindOne(ctx, bson.M{"_id": newMeeting.Volunteer.ID}).Decode(&volunteer)
-    if err != nil {
-        if err == mongo.ErrNoDocuments {
-            return Meeting{}, errors.New("volunteer not found")
-        }
-        return Meeting{}, err
-    }
-
-    // Identify services that are available (not already InProgress)
-    var availableServices []string
-    
-    // Create update document for MongoDB
-    servicesUpdate := bson.M{"updatedAt": now}
-    
-    for _, service := range newMeeting.Services {
-        if status, exists := recipient.Services[service]; exists && status != InProgress {
-            availableServices = append(availableServices, service)
-            
-            // Update local recipient object's service status
-            recipient.Services[service] = InProgress
-            
-            // Create the field path for this specific service
-            serviceFieldPath := fmt.Sprintf("services.%s", service)
-            
-            // Add to MongoDB update using explicit field path
-            servicesUpdate[serviceFieldPath] = string(InProgress)
-        }
-    }
-
-    // If no services are available, return an error
-    if len(availableServices) == 0 {
-        return Meeting{}, errors.New("recipient already in progress")
-    }
-
-    // Update the recipient's services in MongoDB using explicit field paths
-    updateResult, err := database.UsersCollection.UpdateOne(
-        ctx,
-        bson.M{"_id": recipient.ID},
-        bson.M{"$set": servicesUpdate},
-    )
-    
-    if err != nil {
-        return Meeting{}, err
-    }
-    
-    if updateResult.ModifiedCount == 0 {
-        return Meeting{}, errors.New("failed to update recipient services")
-    }
-    
-    // Log successful update
-    fmt.Printf("Updated recipient %s services: %+v\n", recipient.ID, updateResult)
-
-    // Create a new meeting with reference IDs and a new MongoDB ObjectID
-    meeting := Meeting{
-        ID:            primitive.NewObjectID().Hex(),
-        RecipientID:   recipient.ID,
-        VolunteerID:   volunteer.ID,
-        Date:          newMeeting.Date,
-        Services:      availableServices,
-        MeetingStatus: newMeeting.MeetingStatus,
-        CreatedAt:     now,
-        UpdatedAt:     now,
-    }
-
-    // For API response, include the full user objects
-    meeting.Recipient = recipient
-    meeting.Volunteer = volunteer
-
-    // Insert the meeting into MongoDB
-    _, err = database.MeetingsCollection.InsertOne(ctx, bson.M{
-        "_id":           meeting.ID,
-        "recipientId":   meeting.RecipientID,
-        "volunteerId":   meeting.VolunteerID,
-        "date":          meeting.Date,
-        "services":      meeting.Services,
-        "meetingStatus": meeting.MeetingStatus,
-        "createdAt":     meeting.CreatedAt,
-        "updatedAt":     meeting.UpdatedAt,
-    })
-    if err != nil {
-        return Meeting{}, err
-    }
-
-    return meeting, nil
+// commit marks the claimed services of the recipient as in progress. It fails with
+// ErrRecipientInProgress if another volunteer claimed any of them since they were read.
+func (c meetingClaim) commit(ctx context.Context, now time.Time) error {
+	// Create update document for MongoDB, and only match the recipient while
+	// nobody else has the services in progress
+	filter := bson.M{"_id": c.recipient.ID}
+	servicesUpdate := bson.M{"updatedAt": now}
+	for _, service := range c.services {
+		// Update local recipient object's service status
+		c.recipient.Services[service] = InProgress
+
+		// Add to MongoDB update using explicit field path
+		field := fmt.Sprintf("services.%s", service)
+		filter[field] = bson.M{"$exists": true, "$ne": string(InProgress)}
+		servicesUpdate[field] = string(InProgress)
+	}
+
+	// Update the recipient's services in MongoDB using explicit field paths, bumping
+	// the version so that updates of the profile read before fail instead of undoing it
+	updateResult, err := database.UsersCollection.UpdateOne(
+		ctx,
+		filter,
+		bson.M{"$set": servicesUpdate, "$inc": bson.M{"version": 1}},
+	)
+	if err != nil {
+		return err
+	}
+	if updateResult.MatchedCount == 0 {
+		return ErrRecipientInProgress
+	}
+
+	// Log successful update
+	slog.DebugContext(ctx, "Updated recipient services", "recipientId", c.recipient.ID, "modified", updateResult.ModifiedCount)
+	return nil
 }
 
+// insertMeeting stores a new meeting
+func insertMeeting(ctx context.Context, meeting Meeting) error {
+	document := bson.M{
+		"_id":           meeting.ID,
+		"recipientId":   meeting.RecipientID,
+		"volunteerId":   meeting.VolunteerID,
+		"date":          meeting.Date,
+		"end":           meeting.End,
+		"services":      meeting.Services,
+		"meetingStatus": meeting.MeetingStatus,
+		"createdAt":     meeting.CreatedAt,
+		"updatedAt":     meeting.UpdatedAt,
+		"version":       meeting.Version,
+	}
+
+	// Occurrences of a series remember which one they are, even once rescheduled
+	if meeting.SeriesID != "" {
+		document["seriesId"] = meeting.SeriesID
+		document["occurrence"] = meeting.Occurrence
+	}
+
+	_, err := database.MeetingsCollection.InsertOne(ctx, document)
+	return err
+}
 
 // if the user is volunteer, update the recipient's service statuses
 // if the user is recipient, cancel the meeting, in the client side the recipient will be updated
-func CancelMeeting(meetingID string, userUID string) error {
-    // Create a context with timeout
-    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-    defer cancel()
-
-    // Find the meeting in MongoDB
-    var meeting Meeting
-    err := database.MeetingsCollection.FindOne(ctx, bson.M{"_id": meetingID}).Decode(&meeting)
-    if err != nil {
-        if err == mongo.ErrNoDocuments {
-            return errors.New("meeting not found")
-        }
-        return err
-    }
-
-    // Get the user who is cancelling
-    var user User
-    err = database.UsersCollection.FindOne(ctx, bson.M{"_id": userUID}).Decode(&user)
-    if err != nil {
-        if err == mongo.ErrNoDocuments {
-            return errors.New("user not found")
-        }
-        return err
-    }
-
-    // If a volunteer is cancelling, update recipient's service statuses
-    if user.Role == Volunteer {
-        var recipient User
-        err = database.UsersCollection.FindOne(ctx, bson.M{"_id": meeting.RecipientID}).Decode(&recipient)
-        if err != nil {
-            if err == mongo.ErrNoDocuments {
-                return errors.New("recipient not found")
-            }
-            return err
-        }
-
-        // Create a map of field updates for MongoDB
-        servicesUpdate := make(map[string]interface{})
-        for _, service := range meeting.Services {
-            servicesUpdate[fmt.Sprintf("services.%s", service)] = string(NeedAssistance)
-        }
-
-        // Update the recipient's services in MongoDB
-        _, err = database.UsersCollection.UpdateOne(
-            ctx,
-            bson.M{"_id": recipient.ID},
-            bson.M{"$set": servicesUpdate},
-        )
-        if err != nil {
-            return err
-        }
-    }
-
-    // Delete the meeting from MongoDB
-    _, err = database.MeetingsCollection.DeleteOne(ctx, bson.M{"_id": meetingID})
-    if err != nil {
-        return err
-    }
-
-    return nil
+// occurrences of a series are cancelled alone, the series keeps the recipient's services
+// cancelled meetings are kept with the CANCELLED status and no longer count as busy
+// only the participants or an administrator may cancel a meeting
+func CancelMeeting(ctx context.Context, actorID string, meetingID string) error {
+	ctx, span := tracer.Start(ctx, "services.CancelMeeting")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	// Find the meeting in MongoDB
+	var meeting Meeting
+	err := database.MeetingsCollection.FindOne(ctx, bson.M{"_id": meetingID}).Decode(&meeting)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrMeetingNotFound
+		}
+		return err
+	}
+
+	// Get the user who is cancelling
+	user, err := authorizeMeetingAccess(ctx, actorID, meeting)
+	if err != nil {
+		return err
+	}
+	if meeting.MeetingStatus == Cancelled {
+		return ErrMeetingCancelled
+	}
+
+	// Cancelling one occurrence of a series keeps it from being created again, and
+	// the recipient's services stay claimed by the series
+	if meeting.SeriesID != "" {
+		_, err = database.SeriesCollection.UpdateOne(
+			ctx,
+			bson.M{"_id": meeting.SeriesID},
+			bson.M{"$addToSet": bson.M{"exceptions": meeting.Occurrence}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// If the volunteer or an administrator is cancelling, update recipient's service statuses
+	if user.ID != meeting.RecipientID && meeting.SeriesID == "" {
+		var recipient User
+		err = findUser(ctx, bson.M{"_id": meeting.RecipientID}, &recipient)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return ErrRecipientNotFound
+			}
+			return err
+		}
+
+		// Create a map of field updates for MongoDB
+		servicesUpdate := make(map[string]interface{})
+		for _, service := range meeting.Services {
+			servicesUpdate[fmt.Sprintf("services.%s", service)] = string(NeedAssistance)
+		}
+
+		// Update the recipient's services in MongoDB
+		_, err = database.UsersCollection.UpdateOne(
+			ctx,
+			bson.M{"_id": recipient.ID},
+			bson.M{"$set": servicesUpdate, "$inc": bson.M{"version": 1}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Mark the meeting as cancelled, unless it was cancelled meanwhile
+	result, err := database.MeetingsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": meetingID, "meetingStatus": bson.M{"$ne": Cancelled}},
+		bson.M{
+			"$set": bson.M{"meetingStatus": Cancelled, "updatedAt": time.Now()},
+			"$inc": bson.M{"version": 1},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrMeetingCancelled
+	}
+
+	metrics.MeetingsCancelled.WithLabelValues(string(user.Role)).Inc()
+
+	return nil
+}
+
+// GetMeetings lists the meetings of a user, the signed-in one if userId is empty.
+// Only the user themselves or an administrator may list them.
+func GetMeetings(ctx context.Context, actorID string, userId string, status MeetingStatus) ([]Meeting, error) {
+	ctx, span := tracer.Start(ctx, "services.GetMeetings")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	// Users list their own meetings, only administrators those of others
+	if userId == "" {
+		userId = actorID
+	}
+	if err := authorizeAccountAccess(ctx, actorID, userId); err != nil {
+		return nil, err
+	}
+
+	return findMeetings(ctx, userId, status)
 }
 
+// findMeetings loads the meetings of a user, optionally with the given status,
+// with the details of both participants
+func findMeetings(ctx context.Context, userId string, status MeetingStatus) ([]Meeting, error) {
+	// Filter meetings where user is either recipient or volunteer
+	filter := bson.M{
+		"$or": []bson.M{
+			{"recipientId": userId},
+			{"volunteerId": userId},
+		},
+	}
+	if status != "" {
+		filter["meetingStatus"] = status
+	}
+
+	// Find meetings in MongoDB that match the filter
+	cursor, err := database.MeetingsCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	// Decode meetings from MongoDB
+	var meetingsData []Meeting
+	if err = cursor.All(ctx, &meetingsData); err != nil {
+		return nil, err
+	}
+
+	// Load user details for each meeting
+	var meetings []Meeting
+	for _, m := range meetingsData {
+		// Get recipient details
+		var recipient User
+		err = findUser(ctx, bson.M{"_id": m.RecipientID}, &recipient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load recipient data: %v", err)
+		}
+
+		// Get volunteer details
+		var volunteer User
+		err = findUser(ctx, bson.M{"_id": m.VolunteerID}, &volunteer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load volunteer data: %v", err)
+		}
+
+		// Populate meeting with user details for API response
+		m.Recipient = recipient
+		m.Volunteer = volunteer
+		meetings = append(meetings, m)
+	}
+
+	return meetings, nil
+}
 
-func GetMeetings(userId string, status MeetingStatus) ([]Meeting, error) {
-    // Create a context with timeout
-    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-    defer cancel()
-
-    // Build a filter based on the provided parameters
-    filter := bson.M{}
-    if userId != "" {
-        // Filter meetings where user is either recipient or volunteer
-        filter["$or"] = []bson.M{
-            {"recipientId": userId},
-            {"volunteerId": userId},
-        }
-    }
-    if status != "" {
-        filter["meetingStatus"] = status
-    }
-
-    // Find meetings in MongoDB that match the filter
-    cursor, err := database.MeetingsCollection.Find(ctx, filter)
-    if err != nil {
-        return nil, err
-    }
-    defer cursor.Close(ctx)
-
-    // Decode meetings from MongoDB
-    var meetingsData []Meeting
-    if err = cursor.All(ctx, &meetingsData); err != nil {
-        return nil, err
-    }
-
-    // Load user details for each meeting
-    var meetings []Meeting
-    for _, m := range meetingsData {
-        // Get recipient details
-        var recipient User
-        err = database.UsersCollection.FindOne(ctx, bson.M{"_id": m.RecipientID}).Decode(&recipient)
-        if err != nil {
-            return nil, fmt.Errorf("failed to load recipient data: %v", err)
-        }
-
-        // Get volunteer details
-        var volunteer User
-        err = database.UsersCollection.FindOne(ctx, bson.M{"_id": m.VolunteerID}).Decode(&volunteer)
-        if err != nil {
-            return nil, fmt.Errorf("failed to load volunteer data: %v", err)
-        }
-
-        // Populate meeting with user details for API response
-        m.Recipient = recipient
-        m.Volunteer = volunteer
-        meetings = append(meetings, m)
-    }
-
-    return meetings, nil
+// UpdateMeetingStatus sets the status of a meeting. Only the participants or an administrator
+// may update a meeting. An expectedVersion other than AnyVersion makes the update fail
+// unless the stored meeting still has that version.
+func UpdateMeetingStatus(ctx context.Context, actorID string, meetingID string, newStatus MeetingStatus, expectedVersion int64) (Meeting, error) {
+	ctx, span := tracer.Start(ctx, "services.UpdateMeetingStatus")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	// Find the meeting in MongoDB
+	var meeting Meeting
+	err := database.MeetingsCollection.FindOne(ctx, bson.M{"_id": meetingID}).Decode(&meeting)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return Meeting{}, ErrMeetingNotFound
+		}
+		return Meeting{}, err
+	}
+	if _, err := authorizeMeetingAccess(ctx, actorID, meeting); err != nil {
+		return Meeting{}, err
+	}
+
+	if err := checkVersion(meeting.Version, expectedVersion); err != nil {
+		return Meeting{}, err
+	}
+	if meeting.MeetingStatus == Cancelled {
+		return Meeting{}, ErrMeetingCancelled
+	}
+
+	if err := checkStatusTransition(meeting.MeetingStatus, newStatus); err != nil {
+		return Meeting{}, err
+	}
+
+	updatedMeeting := meeting
+	updatedMeeting.MeetingStatus = newStatus
+	return saveMeeting(ctx, meeting, updatedMeeting, expectedVersion)
 }
 
+// PatchMeeting applies a JSON Merge Patch to the date and status of a meeting. Only the
+// participants or an administrator may update a meeting.
+// An expectedVersion other than AnyVersion makes the update fail unless the stored meeting still has that version.
+func PatchMeeting(ctx context.Context, actorID string, meetingID string, patch []byte, expectedVersion int64) (Meeting, error) {
+	ctx, span := tracer.Start(ctx, "services.PatchMeeting")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	// Find the meeting in MongoDB
+	var meeting Meeting
+	err := database.MeetingsCollection.FindOne(ctx, bson.M{"_id": meetingID}).Decode(&meeting)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return Meeting{}, ErrMeetingNotFound
+		}
+		return Meeting{}, err
+	}
+	if _, err := authorizeMeetingAccess(ctx, actorID, meeting); err != nil {
+		return Meeting{}, err
+	}
+
+	if err := checkVersion(meeting.Version, expectedVersion); err != nil {
+		return Meeting{}, err
+	}
+	if meeting.MeetingStatus == Cancelled {
+		return Meeting{}, ErrMeetingCancelled
+	}
+
+	// Fields missing from the patch keep their stored value
+	var updatedMeeting Meeting
+	if err := applyMergePatch(meeting, patch, updatableMeetingFields, &updatedMeeting); err != nil {
+		return Meeting{}, err
+	}
+
+	// Validate the patched fields
+	v := &validation.Validator{}
+	if !updatedMeeting.Date.Equal(meeting.Date) {
+		validateMeetingDate(v, "date", updatedMeeting.Date)
+	}
+	validation.OneOf(v, "meetingStatus", updatedMeeting.MeetingStatus, IsPicked, Done)
+	if err := invalid(v.Err()); err != nil {
+		return Meeting{}, err
+	}
+	if err := checkStatusTransition(meeting.MeetingStatus, updatedMeeting.MeetingStatus); err != nil {
+		return Meeting{}, err
+	}
+
+	// A rescheduled meeting keeps its duration and must fit the new time
+	if !updatedMeeting.Date.Equal(meeting.Date) {
+		updatedMeeting.End = updatedMeeting.Date.Add(meeting.End.Sub(meeting.Date))
+		slot := TimeSlot{Start: updatedMeeting.Date, End: updatedMeeting.End}
+		release, err := lockSchedules(ctx, meeting.VolunteerID, meeting.RecipientID)
+		if err != nil {
+			return Meeting{}, err
+		}
+		defer release()
+		if err := checkSchedule(ctx, meeting.VolunteerID, meeting.RecipientID, slot, bson.M{"_id": meeting.ID}); err != nil {
+			return Meeting{}, err
+		}
+	}
+
+	return saveMeeting(ctx, meeting, updatedMeeting, expectedVersion)
+}
+
+// checkStatusTransition only lets a meeting move forward, from IS_PICKED to DONE,
+// so a completed meeting cannot be reopened
+func checkStatusTransition(from MeetingStatus, to MeetingStatus) error {
+	if from == Done && to != Done {
+		return ErrMeetingDone
+	}
+	return nil
+}
+
+// authorizeMeetingAccess allows the participants of a meeting and administrators to
+// access it, and returns the acting user
+func authorizeMeetingAccess(ctx context.Context, actorID string, meeting Meeting) (User, error) {
+	if actorID == "" {
+		return User{}, ErrNotAuthenticated
+	}
+
+	actor, err := findActiveUser(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return User{}, ErrNotAuthenticated
+		}
+		return User{}, err
+	}
+	if actor.ID != meeting.RecipientID && actor.ID != meeting.VolunteerID && actor.Role != Admin {
+		return User{}, ErrMeetingAccessDenied
+	}
+	return actor, nil
+}
+
+// saveMeeting stores the date, end and status of a meeting, bumping its version.
+// The write only succeeds if nobody else updated the meeting since it was read.
+func saveMeeting(ctx context.Context, meeting Meeting, updatedMeeting Meeting, expectedVersion int64) (Meeting, error) {
+	// Update the meeting in MongoDB, only if it was not modified meanwhile
+	now := time.Now()
+	result, err := database.MeetingsCollection.UpdateOne(
+		ctx,
+		versionFilter(meeting.ID, meeting.Version),
+		bson.M{"$set": bson.M{
+			"date":          updatedMeeting.Date,
+			"end":           updatedMeeting.End,
+			"meetingStatus": updatedMeeting.MeetingStatus,
+			"updatedAt":     now,
+			"version":       meeting.Version + 1,
+		}},
+	)
+	if err != nil {
+		return Meeting{}, err
+	}
+	if result.MatchedCount == 0 {
+		return Meeting{}, lostUpdate(expectedVersion)
+	}
+
+	if meeting.MeetingStatus != Done && updatedMeeting.MeetingStatus == Done {
+		metrics.MeetingsCompleted.Inc()
+	}
+
+	// Update the meeting object with the new values
+	meeting.Date = updatedMeeting.Date
+	meeting.End = updatedMeeting.End
+	meeting.MeetingStatus = updatedMeeting.MeetingStatus
+	meeting.UpdatedAt = now
+	meeting.Version++
+
+	// Load user details for API response
+	var recipient User
+	err = findUser(ctx, bson.M{"_id": meeting.RecipientID}, &recipient)
+	if err != nil {
+		return Meeting{}, fmt.Errorf("failed to load recipient data: %v", err)
+	}
+
+	var volunteer User
+	err = findUser(ctx, bson.M{"_id": meeting.VolunteerID}, &volunteer)
+	if err != nil {
+		return Meeting{}, fmt.Errorf("failed to load volunteer data: %v", err)
+	}
+
+	// Set user data for API response
+	meeting.Recipient = recipient
+	meeting.Volunteer = volunteer
+
+	return meeting, nil
+}
 
-func UpdateMeetingStatus(meetingID string, newStatus MeetingStatus) (Meeting, error) {
-    // Create a context with timeout
-    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-    defer cancel()
-
-    // Find the meeting in MongoDB
-    var meeting Meeting
-    err := database.MeetingsCollection.FindOne(ctx, bson.M{"_id": meetingID}).Decode(&meeting)
-    if err != nil {
-        if err == mongo.ErrNoDocuments {
-            return Meeting{}, errors.New("meeting not found")
-        }
-        return Meeting{}, err
-    }
-
-    // Update the meeting status in MongoDB
-    now := time.Now()
-    _, err = database.MeetingsCollection.UpdateOne(
-        ctx,
-        bson.M{"_id": meetingID},
-        bson.M{"$set": bson.M{
-            "meetingStatus": newStatus,
-            "updatedAt":     now,
-        }},
-    )
-    if err != nil {
-        return Meeting{}, err
-    }
-
-    // Update the meeting object with the new status
-    meeting.MeetingStatus = newStatus
-    meeting.UpdatedAt = now
-    
-    // Load user details for API response
-    var recipient User
-    err = database.UsersCollection.FindOne(ctx, bson.M{"_id": meeting.RecipientID}).Decode(&recipient)
-    if err != nil {
-        return Meeting{}, fmt.Errorf("failed to load recipient data: %v", err)
-    }
-
-    var volunteer User
-    err = database.UsersCollection.FindOne(ctx, bson.M{"_id": meeting.VolunteerID}).Decode(&volunteer)
-    if err != nil {
-        return Meeting{}, fmt.Errorf("failed to load volunteer data: %v", err)
-    }
-
-    // Set user data for API response
-    meeting.Recipient = recipient
-    meeting.Volunteer = volunteer
-
-    return meeting, nil
+// EnsureMeetingSchedule indexes meetings by participant and start, so overlapping
+// meetings are found quickly, and by occurrence of their series. It also sets the
+// end of meetings created before they had one.
+func EnsureMeetingSchedule(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "services.EnsureMeetingSchedule")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Each occurrence of a series is only created once, even by concurrent instances
+	_, err := database.MeetingsCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "volunteerId", Value: 1}, {Key: "date", Value: 1}}},
+		{Keys: bson.D{{Key: "recipientId", Value: 1}, {Key: "date", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "seriesId", Value: 1}, {Key: "occurrence", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"seriesId": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	catalogue, err := loadServiceCatalogue(ctx)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := database.MeetingsCollection.Find(ctx, bson.M{"end": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var meetings []Meeting
+	if err = cursor.All(ctx, &meetings); err != nil {
+		return err
+	}
+
+	for _, meeting := range meetings {
+		end := meeting.Date.Add(meetingDuration(catalogue, meeting.Services))
+		_, err := database.MeetingsCollection.UpdateOne(ctx, bson.M{"_id": meeting.ID}, bson.M{"$set": bson.M{"end": end}})
+		if err != nil {
+			return err
+		}
+	}
+	if len(meetings) > 0 {
+		slog.InfoContext(ctx, "Migrated meeting ends", "meetings", len(meetings))
+	}
+
+	return nil
 }
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"neighborguard/pkg/database"
+	"neighborguard/pkg/metrics"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RefreshMetrics recomputes the gauges that describe the stored data: open
+// needs by service, active meetings and overdue check-ins
+func RefreshMetrics(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "services.RefreshMetrics")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	catalogue, err := loadServiceCatalogue(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Recipients needing each service of the catalogue
+	activeRecipients := bson.M{"role": string(Recipient), "deletedAt": bson.M{"$exists": false}}
+	for id := range catalogue {
+		filter := bson.M{"services." + id: string(NeedAssistance)}
+		for key, value := range activeRecipients {
+			filter[key] = value
+		}
+		count, err := database.UsersCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			return err
+		}
+		metrics.OpenNeeds.WithLabelValues(id).Set(float64(count))
+	}
+
+	activeMeetings, err := database.MeetingsCollection.CountDocuments(ctx, bson.M{"meetingStatus": IsPicked})
+	if err != nil {
+		return err
+	}
+	metrics.ActiveMeetings.Set(float64(activeMeetings))
+
+	// Recipients who did not check in within the threshold, counted in their own
+	// timezone like checkInDeadline
+	deadline := bson.M{"$add": bson.A{
+		bson.M{"$dateAdd": bson.M{
+			"startDate": "$lastOK",
+			"unit":      "day",
+			"amount":    int(CheckInThreshold / (24 * time.Hour)),
+			"timezone":  bson.M{"$ifNull": bson.A{"$timezone", "UTC"}},
+		}},
+		int64((CheckInThreshold % (24 * time.Hour)) / time.Millisecond),
+	}}
+	overdueFilter := bson.M{"$expr": bson.M{"$lt": bson.A{deadline, time.Now()}}}
+	for key, value := range activeRecipients {
+		overdueFilter[key] = value
+	}
+	overdue, err := database.UsersCollection.CountDocuments(ctx, overdueFilter)
+	if err != nil {
+		return err
+	}
+	metrics.OverdueCheckIns.Set(float64(overdue))
+
+	return nil
+}
+
+// observeTimeToClaim records how long the services of a new meeting waited for
+// a volunteer. A General Check is needed from the moment the recipient's last
+// check-in became overdue; for other services the need is only known to exist
+// since the recipient last updated their profile.
+func observeTimeToClaim(recipient User, services []string, claimedAt time.Time) {
+	for _, service := range services {
+		neededSince := recipient.UpdatedAt
+		if service == GeneralCheck {
+			neededSince = checkInDeadline(recipient)
+		}
+		if wait := claimedAt.Sub(neededSince); wait >= 0 {
+			metrics.TimeToClaim.WithLabelValues(service).Observe(wait.Seconds())
+		}
+	}
+}
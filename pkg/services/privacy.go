@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"neighborguard/pkg/database"
+
+	"github.com/umahmood/haversine"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type LocationPrecision string
+type FuzzingMethod string
+
+const (
+	ExactPrecision         LocationPrecision = "EXACT"
+	StreetPrecision        LocationPrecision = "STREET"
+	NeighbourhoodPrecision LocationPrecision = "NEIGHBOURHOOD"
+)
+
+const (
+	GridSnapping FuzzingMethod = "GRID_SNAPPING"
+	RandomOffset FuzzingMethod = "RANDOM_OFFSET"
+)
+
+// LocationFuzzing is the method used to fuzz locations served to non-participants
+var LocationFuzzing = GridSnapping
+
+// LocationSecret keys the random offsets of RandomOffset. It must stay secret and
+// stable: anyone seeing the offsets from two secrets can narrow down the location.
+var LocationSecret []byte
+
+// Size (in degrees) of the area a fuzzed location may point anywhere within.
+// 0.001 degrees is roughly 100 meters of latitude.
+var precisionCellSize = map[LocationPrecision]float64{
+	ExactPrecision:         0,
+	StreetPrecision:        0.001,
+	NeighbourhoodPrecision: 0.005,
+}
+
+// PrivacySettings control how a user's location is shared with other users
+type PrivacySettings struct {
+	LocationPrecision LocationPrecision `json:"locationPrecision" bson:"locationPrecision"` // defaults to NEIGHBOURHOOD
+	HideFromSearch    bool              `json:"hideFromSearch" bson:"hideFromSearch"`       // opt out of proximity search
+}
+
+// NearbyRecipient is a recipient matched by a proximity search together with
+// the search origin, from which distances to the fuzzed location are served
+type NearbyRecipient struct {
+	Recipient User
+	Origin    LonLat
+}
+
+// Distance returns the distance in kilometers between two locations
+func Distance(from LonLat, to LonLat) float64 {
+	point1 := haversine.Coord{Lat: from.Latitude, Lon: from.Longitude}
+	point2 := haversine.Coord{Lat: to.Latitude, Lon: to.Longitude}
+
+	_, km := haversine.Distance(point1, point2)
+	return km
+}
+
+// ApproximateDistance rounds a distance up to the next 100 meters
+func ApproximateDistance(km float64) float64 {
+	return math.Max(0.1, math.Ceil(km*10)/10)
+}
+
+// Precision returns the user's location precision, falling back to NEIGHBOURHOOD
+func (p PrivacySettings) Precision() LocationPrecision {
+	if _, ok := precisionCellSize[p.LocationPrecision]; ok {
+		return p.LocationPrecision
+	}
+	return NeighbourhoodPrecision
+}
+
+// FuzzLocation returns the location of a user as served to non-participants,
+// at the precision the user chose. Exact coordinates stay internal.
+func FuzzLocation(user User) LonLat {
+	cellSize := precisionCellSize[user.Privacy.Precision()]
+	if cellSize == 0 {
+		return user.LonLat
+	}
+
+	if LocationFuzzing == RandomOffset {
+		return offsetLocation(user.ID, user.LonLat, cellSize)
+	}
+	return snapLocation(user.LonLat, cellSize)
+}
+
+// snapLocation snaps a location to the center of its grid cell
+func snapLocation(location LonLat, cellSize float64) LonLat {
+	snap := func(value float64) float64 {
+		return (math.Floor(value/cellSize) + 0.5) * cellSize
+	}
+	return LonLat{Longitude: snap(location.Longitude), Latitude: snap(location.Latitude)}
+}
+
+// offsetLocation moves a location by a random offset of up to half a cell.
+// The offset is derived from the user ID so repeated requests return the same
+// point and cannot be averaged back to the exact location, and keyed by
+// LocationSecret so that knowing the public ID is not enough to undo it.
+func offsetLocation(userID string, location LonLat, cellSize float64) LonLat {
+	mac := hmac.New(sha256.New, LocationSecret)
+	mac.Write([]byte(userID))
+	sum := mac.Sum(nil)
+
+	// Two numbers in [0, 1) from the 53 high bits of each half of the MAC
+	unit := func(b []byte) float64 {
+		return float64(binary.BigEndian.Uint64(b)>>11) / (1 << 53)
+	}
+	angle := unit(sum[0:8]) * 2 * math.Pi
+	radius := (0.5 + unit(sum[8:16])/2) * cellSize / 2
+
+	// Degrees of longitude shrink towards the poles
+	lonScale := math.Max(math.Cos(location.Latitude*math.Pi/180), 0.01)
+	longitude := location.Longitude + radius*math.Cos(angle)/lonScale
+	latitude := location.Latitude + radius*math.Sin(angle)
+
+	// Offsets past a pole are mirrored back, and longitudes wrap around the antimeridian
+	if math.Abs(latitude) > 90 {
+		latitude = location.Latitude - radius*math.Sin(angle)
+	}
+	if longitude > 180 {
+		longitude -= 360
+	} else if longitude < -180 {
+		longitude += 360
+	}
+	return LonLat{Longitude: longitude, Latitude: latitude}
+}
+
+// NeededServices returns the sorted names of the services the user currently needs help with
+func NeededServices(user User) []string {
+	needed := []string{}
+	for service, status := range user.Services {
+		if status == NeedAssistance {
+			needed = append(needed, service)
+		}
+	}
+	sort.Strings(needed)
+	return needed
+}
+
+// DisclosesContactTo reports whether the meeting entitles the given user to
+// the recipient's full contact details. Only the volunteer of an active
+// meeting gets them, and only while the meeting is active.
+func (m Meeting) DisclosesContactTo(userID string) bool {
+	return userID != "" && m.VolunteerID == userID && m.MeetingStatus == IsPicked
+}
+
+// DisclosesVolunteerContactTo reports whether the meeting entitles the given user
+// to the volunteer's contact details. Only the recipient of an active meeting
+// gets them, and only while the meeting is active.
+func (m Meeting) DisclosesVolunteerContactTo(userID string) bool {
+	return userID != "" && m.RecipientID == userID && m.MeetingStatus == IsPicked
+}
+
+// GetRecipientForVolunteer returns a recipient and its distance from a volunteer,
+// and whether the volunteer is entitled to the recipient's contact details
+func GetRecipientForVolunteer(ctx context.Context, recipientUID string, volunteerUID string) (NearbyRecipient, bool, error) {
+	ctx, span := tracer.Start(ctx, "services.GetRecipientForVolunteer")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	// Get the volunteer's details from MongoDB
+	var volunteer User
+	err := findUser(ctx, bson.M{"_id": volunteerUID}, &volunteer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return NearbyRecipient{}, false, ErrVolunteerNotFound
+		}
+		return NearbyRecipient{}, false, err
+	}
+
+	// Verify that the user is actually a volunteer
+	if volunteer.Role != Volunteer {
+		return NearbyRecipient{}, false, ErrVolunteersOnly
+	}
+
+	// Get the recipient's details from MongoDB
+	var recipient User
+	err = findUser(ctx, bson.M{"_id": recipientUID, "role": string(Recipient), "deletedAt": bson.M{"$exists": false}}, &recipient)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return NearbyRecipient{}, false, ErrRecipientNotFound
+		}
+		return NearbyRecipient{}, false, err
+	}
+
+	// Contact details are disclosed only while the volunteer holds an active meeting with the recipient
+	count, err := database.MeetingsCollection.CountDocuments(ctx, bson.M{
+		"recipientId":   recipient.ID,
+		"volunteerId":   volunteer.ID,
+		"meetingStatus": IsPicked,
+	})
+	if err != nil {
+		return NearbyRecipient{}, false, err
+	}
+
+	// Recipients who opted out of search are only visible to their active volunteers
+	if recipient.Privacy.HideFromSearch && count == 0 {
+		return NearbyRecipient{}, false, ErrRecipientNotFound
+	}
+
+	match := NearbyRecipient{Recipient: recipient, Origin: volunteer.LonLat}
+	return match, count > 0, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+// withLocationFuzzing runs a test with the given fuzzing method and secret
+func withLocationFuzzing(t *testing.T, method FuzzingMethod, secret string) {
+	t.Helper()
+	previousMethod, previousSecret := LocationFuzzing, LocationSecret
+	LocationFuzzing, LocationSecret = method, []byte(secret)
+	t.Cleanup(func() {
+		LocationFuzzing, LocationSecret = previousMethod, previousSecret
+	})
+}
+
+func userAt(id string, precision LocationPrecision, lon float64, lat float64) User {
+	return User{
+		ID:      id,
+		LonLat:  LonLat{Longitude: lon, Latitude: lat},
+		Privacy: PrivacySettings{LocationPrecision: precision},
+	}
+}
+
+func TestFuzzLocationGridSnapping(t *testing.T) {
+	withLocationFuzzing(t, GridSnapping, "")
+
+	tests := []struct {
+		name      string
+		precision LocationPrecision
+		location  LonLat
+		want      LonLat
+	}{
+		{"exact", ExactPrecision, LonLat{Longitude: 34.78184, Latitude: 32.08533}, LonLat{Longitude: 34.78184, Latitude: 32.08533}},
+		{"street", StreetPrecision, LonLat{Longitude: 34.78184, Latitude: 32.08533}, LonLat{Longitude: 34.7815, Latitude: 32.0855}},
+		{"neighbourhood", NeighbourhoodPrecision, LonLat{Longitude: 34.78184, Latitude: 32.08533}, LonLat{Longitude: 34.7825, Latitude: 32.0875}},
+		{"default precision", "", LonLat{Longitude: 34.78184, Latitude: 32.08533}, LonLat{Longitude: 34.7825, Latitude: 32.0875}},
+		{"unknown precision", "BUILDING", LonLat{Longitude: 34.78184, Latitude: 32.08533}, LonLat{Longitude: 34.7825, Latitude: 32.0875}},
+		{"negative coordinates", StreetPrecision, LonLat{Longitude: -58.38162, Latitude: -34.60371}, LonLat{Longitude: -58.3815, Latitude: -34.6035}},
+		{"on a cell border", StreetPrecision, LonLat{Longitude: 34.781, Latitude: 32.085}, LonLat{Longitude: 34.7815, Latitude: 32.0855}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FuzzLocation(userAt("u1", tt.precision, tt.location.Longitude, tt.location.Latitude))
+			if math.Abs(got.Longitude-tt.want.Longitude) > 1e-9 || math.Abs(got.Latitude-tt.want.Latitude) > 1e-9 {
+				t.Errorf("FuzzLocation() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzLocationRandomOffset(t *testing.T) {
+	withLocationFuzzing(t, RandomOffset, "a secret of at least thirty-two bytes")
+
+	tests := []struct {
+		name      string
+		precision LocationPrecision
+		lon       float64
+		lat       float64
+	}{
+		{"street", StreetPrecision, 34.78184, 32.08533},
+		{"neighbourhood", NeighbourhoodPrecision, 34.78184, 32.08533},
+		{"equator", NeighbourhoodPrecision, -78.46783, 0},
+		{"southern hemisphere", StreetPrecision, -58.38162, -34.60371},
+		{"near the north pole", NeighbourhoodPrecision, 15.64, 89.999},
+		{"near the south pole", NeighbourhoodPrecision, 139.27, -89.9999},
+		{"near the antimeridian", NeighbourhoodPrecision, 179.9995, -16.5},
+		{"near the antimeridian westwards", NeighbourhoodPrecision, -179.9995, -16.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cellSize := precisionCellSize[tt.precision]
+			for _, id := range []string{"u1", "u2", "65f1c2a3b4d5e6f708192a3b"} {
+				user := userAt(id, tt.precision, tt.lon, tt.lat)
+				got := FuzzLocation(user)
+
+				// The same user always gets the same point, so it cannot be averaged away
+				if again := FuzzLocation(user); again != got {
+					t.Fatalf("FuzzLocation(%s) = %+v then %+v", id, got, again)
+				}
+
+				if got.Latitude < -90 || got.Latitude > 90 || got.Longitude < -180 || got.Longitude > 180 {
+					t.Fatalf("FuzzLocation(%s) = %+v is not a valid location", id, got)
+				}
+
+				// Measured on the ground, the point stays within half a cell of the location
+				dLat := got.Latitude - tt.lat
+				dLon := math.Remainder(got.Longitude-tt.lon, 360) * math.Cos(tt.lat*math.Pi/180)
+				if offset := math.Hypot(dLon, dLat); offset > cellSize/2+1e-12 {
+					t.Errorf("FuzzLocation(%s) moved by %g degrees, more than half a cell of %g", id, offset, cellSize)
+				}
+				if got == user.LonLat {
+					t.Errorf("FuzzLocation(%s) returned the exact location", id)
+				}
+			}
+		})
+	}
+}
+
+func TestFuzzLocationRandomOffsetPerUser(t *testing.T) {
+	withLocationFuzzing(t, RandomOffset, "a secret of at least thirty-two bytes")
+
+	first := FuzzLocation(userAt("u1", StreetPrecision, 34.78184, 32.08533))
+	second := FuzzLocation(userAt("u2", StreetPrecision, 34.78184, 32.08533))
+	if first == second {
+		t.Errorf("users at the same location got the same offset %+v", first)
+	}
+}
+
+func TestFuzzLocationRandomOffsetSecret(t *testing.T) {
+	user := userAt("u1", StreetPrecision, 34.78184, 32.08533)
+
+	withLocationFuzzing(t, RandomOffset, "a secret of at least thirty-two bytes")
+	first := FuzzLocation(user)
+
+	withLocationFuzzing(t, RandomOffset, "another secret of thirty-two bytes")
+	second := FuzzLocation(user)
+
+	if first == second {
+		t.Errorf("changing the secret kept the offset %+v, it can be computed from the user ID", first)
+	}
+}
+
+func TestFuzzLocationExactIgnoresFuzzing(t *testing.T) {
+	withLocationFuzzing(t, RandomOffset, "a secret of at least thirty-two bytes")
+
+	user := userAt("u1", ExactPrecision, 34.78184, 32.08533)
+	if got := FuzzLocation(user); got != user.LonLat {
+		t.Errorf("FuzzLocation() = %+v, want the exact location %+v", got, user.LonLat)
+	}
+}
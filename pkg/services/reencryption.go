@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"neighborguard/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ReencryptUsers encrypts the sensitive fields of every user with the active
+// key of the keyring. It picks up users stored in clear text before encryption
+// was enabled and users encrypted with a retired key, so it is run after each
+// key rotation. It returns the number of users re-encrypted.
+func ReencryptUsers(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "services.ReencryptUsers")
+	defer span.End()
+
+	if Keyring == nil {
+		return 0, errNoKeyring
+	}
+
+	// Deleted users have no personal data left to encrypt
+	cursor, err := database.UsersCollection.Find(ctx, bson.M{"deletedAt": bson.M{"$exists": false}})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	reencrypted := 0
+	for cursor.Next(ctx) {
+		var document bson.M
+		if err := cursor.Decode(&document); err != nil {
+			return reencrypted, err
+		}
+
+		needed, err := needsReencryption(document)
+		if err != nil {
+			return reencrypted, err
+		}
+		if !needed {
+			continue
+		}
+
+		var user User
+		if err := openUser(document, &user); err != nil {
+			return reencrypted, err
+		}
+
+		fields := bson.M{
+			"email":       user.Email,
+			"phoneNumber": user.PhoneNumber,
+			"address":     user.Address,
+			"lonLat":      user.LonLat,
+		}
+		if err := sealUserFields(user.ID, fields); err != nil {
+			return reencrypted, err
+		}
+
+		// The content does not change so the version is kept. A user updated
+		// meanwhile was already saved with the active key and is skipped.
+		result, err := database.UsersCollection.UpdateOne(ctx, versionFilter(user.ID, user.Version), bson.M{"$set": fields})
+		if err != nil {
+			return reencrypted, err
+		}
+		if result.MatchedCount > 0 {
+			reencrypted++
+		}
+	}
+
+	return reencrypted, cursor.Err()
+}
+
+// needsReencryption tells whether a stored user has a sensitive field in clear
+// text or encrypted with a key other than the active one
+func needsReencryption(document bson.M) (bool, error) {
+	if _, indexed := document["emailIndex"]; !indexed {
+		return true, nil
+	}
+
+	for _, field := range encryptedUserFields {
+		envelope, sealed, err := storedEnvelope(document[field])
+		if err != nil {
+			return false, err
+		}
+		if !sealed || Keyring.NeedsRotation(envelope) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
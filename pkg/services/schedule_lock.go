@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"neighborguard/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// How long a booking waits for the schedule of a participant that another booking
+// holds, and how often it tries again meanwhile
+const (
+	scheduleLockWait  = 2 * time.Second
+	scheduleLockRetry = 25 * time.Millisecond
+)
+
+// lockSchedules keeps other bookings from checking or changing the schedules of the
+// given users until release is called, so two meetings cannot both pass the overlap
+// check before either is saved. The lock is a conditional update of each user
+// document, taken in a fixed order. A lock that was never released, because its
+// server stopped, expires after DatabaseTimeout.
+func lockSchedules(ctx context.Context, userIDs ...string) (release func(), err error) {
+	ids := slices.Clone(userIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	token := primitive.NewObjectID().Hex()
+	var locked []string
+	release = func() {
+		// Released even if the request was cancelled, so others need not wait for the lock to expire
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), DatabaseTimeout)
+		defer cancel()
+
+		for _, id := range locked {
+			_, err := database.UsersCollection.UpdateOne(ctx,
+				bson.M{"_id": id, "scheduleLock.token": token},
+				bson.M{"$unset": bson.M{"scheduleLock": ""}},
+			)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to release schedule lock", "userId", id, "error", err)
+			}
+		}
+	}
+
+	for _, id := range ids {
+		if err := lockSchedule(ctx, id, token); err != nil {
+			release()
+			return nil, err
+		}
+		locked = append(locked, id)
+	}
+	return release, nil
+}
+
+// lockSchedule takes the schedule lock of a user once it is free or expired
+func lockSchedule(ctx context.Context, uid string, token string) error {
+	deadline := time.Now().Add(scheduleLockWait)
+	for {
+		now := time.Now()
+		result, err := database.UsersCollection.UpdateOne(ctx,
+			bson.M{"_id": uid, "scheduleLock.expiresAt": bson.M{"$not": bson.M{"$gt": now}}},
+			bson.M{"$set": bson.M{"scheduleLock": bson.M{"token": token, "expiresAt": now.Add(DatabaseTimeout)}}},
+		)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+		if now.After(deadline) {
+			return ErrScheduleBusy
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(scheduleLockRetry):
+		}
+	}
+}
@@ -0,0 +1,608 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"neighborguard/pkg/database"
+	"neighborguard/pkg/metrics"
+	"neighborguard/pkg/rrule"
+	"neighborguard/pkg/validation"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SeriesHorizon is how far ahead the occurrences of meeting series are created
+var SeriesHorizon = 28 * 24 * time.Hour
+
+type SeriesStatus string
+
+const (
+	SeriesActive    SeriesStatus = "ACTIVE"
+	SeriesEnded     SeriesStatus = "ENDED" // every occurrence was created
+	SeriesCancelled SeriesStatus = "CANCELLED"
+)
+
+// Fields of a series that a PATCH may change
+var updatableSeriesFields = []string{"start", "rule"}
+
+type NewSeries struct {
+	Recipient User      `json:"recipient"`
+	Volunteer User      `json:"volunteer"`
+	Start     time.Time `json:"start"` // first occurrence
+	Rule      string    `json:"rule"`  // RRULE such as FREQ=WEEKLY;BYDAY=TU
+	Services  []string  `json:"services"`
+}
+
+// MeetingSeries repeats a meeting between a volunteer and a recipient following a
+// recurrence rule. Its occurrences are created as meetings SeriesHorizon ahead, and
+// the recipient's services stay claimed by the volunteer until the series is cancelled.
+// Occurrences keep the time of day of the start in the timezone of the series, the
+// recipient's when it was created, across daylight saving time changes.
+type MeetingSeries struct {
+	ID                string       `json:"id" bson:"_id"`
+	RecipientID       string       `json:"recipientId" bson:"recipientId"`
+	VolunteerID       string       `json:"volunteerId" bson:"volunteerId"`
+	Start             time.Time    `json:"start" bson:"start"` // first occurrence
+	Timezone          string       `json:"timezone" bson:"timezone"`
+	Duration          int64        `json:"duration" bson:"duration"` // of each occurrence, in seconds
+	Rule              string       `json:"rule" bson:"rule"`
+	Services          []string     `json:"services" bson:"services"`
+	Status            SeriesStatus `json:"status" bson:"status"`
+	Exceptions        []time.Time  `json:"exceptions" bson:"exceptions"` // occurrences cancelled on their own
+	MaterializedUntil time.Time    `json:"-" bson:"materializedUntil"`   // occurrences before this time were created
+	CreatedAt         time.Time    `json:"createdAt" bson:"createdAt"`
+	UpdatedAt         time.Time    `json:"updatedAt" bson:"updatedAt"`
+	Version           int64        `json:"version" bson:"version"` // incremented on every update
+}
+
+// CreateSeries claims the services of a recipient for a volunteer and creates the
+// upcoming occurrences of the series. It fails if any of them clashes with another
+// meeting of the participants or falls outside the volunteer's schedule. Only the
+// volunteer of the series may create it.
+func CreateSeries(ctx context.Context, actorID string, newSeries NewSeries) (MeetingSeries, []Meeting, error) {
+	ctx, span := tracer.Start(ctx, "services.CreateSeries")
+	defer span.End()
+
+	// Reject invalid payloads before touching the database
+	if err := newSeries.Validate(); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+
+	// Volunteers claim services for themselves, nobody books them on their behalf
+	if actorID == "" {
+		return MeetingSeries{}, nil, ErrNotAuthenticated
+	}
+	if actorID != newSeries.Volunteer.ID {
+		return MeetingSeries{}, nil, ErrSeriesVolunteerOnly
+	}
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	now := time.Now()
+
+	// Keep the services the recipient still needs from the volunteer
+	claim, err := prepareClaim(ctx, newSeries.Recipient.ID, newSeries.Volunteer.ID, newSeries.Services)
+	if err != nil {
+		return MeetingSeries{}, nil, err
+	}
+
+	series := MeetingSeries{
+		ID:                primitive.NewObjectID().Hex(),
+		RecipientID:       claim.recipient.ID,
+		VolunteerID:       claim.volunteer.ID,
+		Start:             newSeries.Start,
+		Timezone:          claim.recipient.Location().String(),
+		Duration:          int64(claim.duration / time.Second),
+		Rule:              newSeries.Rule,
+		Services:          claim.services,
+		Status:            SeriesActive,
+		Exceptions:        []time.Time{},
+		MaterializedUntil: newSeries.Start,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		Version:           1,
+	}
+
+	// The days of the rule are those of the recipient's timezone
+	v := &validation.Validator{}
+	validateSeriesRule(v, series.Start.In(series.Location()), series.Rule)
+	if err := invalid(v.Err()); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+
+	// Hold the schedules of both participants until the occurrences are saved
+	release, err := lockSchedules(ctx, series.VolunteerID, series.RecipientID)
+	if err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	defer release()
+
+	// Every occurrence created now must fit, as a single meeting would
+	until := now.Add(SeriesHorizon)
+	for _, slot := range seriesSlots(series, until) {
+		if err := checkSchedule(ctx, series.VolunteerID, series.RecipientID, slot, nil); err != nil {
+			return MeetingSeries{}, nil, err
+		}
+	}
+
+	if err := claim.commit(ctx, now); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	if _, err := database.SeriesCollection.InsertOne(ctx, series); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+
+	meetings, err := materializeSeries(ctx, &series, until)
+	if err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	for i := range meetings {
+		meetings[i].Recipient = claim.recipient
+		meetings[i].Volunteer = claim.volunteer
+	}
+
+	metrics.MeetingsCreated.Add(float64(len(meetings)))
+	observeTimeToClaim(claim.recipient, claim.services, now)
+
+	return series, meetings, nil
+}
+
+// GetSeries returns a series and its upcoming occurrences. Only the participants or
+// an administrator may read a series.
+func GetSeries(ctx context.Context, actorID string, seriesID string) (MeetingSeries, []Meeting, error) {
+	ctx, span := tracer.Start(ctx, "services.GetSeries")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	series, err := findSeries(ctx, seriesID)
+	if err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	if _, err := authorizeSeriesAccess(ctx, actorID, series); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+
+	meetings, err := upcomingOccurrences(ctx, series)
+	if err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	return series, meetings, nil
+}
+
+// PatchSeries applies a JSON Merge Patch to the start and rule of a series. Upcoming
+// occurrences are replaced by those of the new rule, dropping the ones cancelled or
+// rescheduled on their own. Only the participants or an administrator may change a
+// series. An expectedVersion other than AnyVersion makes the update fail unless the stored series
+// still has that version.
+func PatchSeries(ctx context.Context, actorID string, seriesID string, patch []byte, expectedVersion int64) (MeetingSeries, []Meeting, error) {
+	ctx, span := tracer.Start(ctx, "services.PatchSeries")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	series, err := findSeries(ctx, seriesID)
+	if err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	if _, err := authorizeSeriesAccess(ctx, actorID, series); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	if err := checkVersion(series.Version, expectedVersion); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	if series.Status == SeriesCancelled {
+		return MeetingSeries{}, nil, ErrSeriesCancelled
+	}
+
+	// Fields missing from the patch keep their stored value
+	var updatedSeries MeetingSeries
+	if err := applyMergePatch(series, patch, updatableSeriesFields, &updatedSeries); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+
+	// Validate the patched fields
+	v := &validation.Validator{}
+	if !updatedSeries.Start.Equal(series.Start) {
+		validateMeetingDate(v, "start", updatedSeries.Start)
+	}
+	validateSeriesRule(v, updatedSeries.Start.In(series.Location()), updatedSeries.Rule)
+	if err := invalid(v.Err()); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+
+	// The new occurrences replace the upcoming ones, so those do not count as clashes
+	now := time.Now()
+	series.Start = updatedSeries.Start
+	series.Rule = updatedSeries.Rule
+	series.Exceptions = []time.Time{}
+	series.MaterializedUntil = later(now, series.Start)
+	series.Status = SeriesActive
+
+	release, err := lockSchedules(ctx, series.VolunteerID, series.RecipientID)
+	if err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	defer release()
+
+	until := now.Add(SeriesHorizon)
+	ownOccurrences := bson.M{"seriesId": series.ID}
+	for _, slot := range seriesSlots(series, until) {
+		if err := checkSchedule(ctx, series.VolunteerID, series.RecipientID, slot, ownOccurrences); err != nil {
+			return MeetingSeries{}, nil, err
+		}
+	}
+
+	// Save the series, only if it was not modified meanwhile
+	result, err := database.SeriesCollection.UpdateOne(
+		ctx,
+		versionFilter(series.ID, series.Version),
+		bson.M{"$set": bson.M{
+			"start":             series.Start,
+			"rule":              series.Rule,
+			"exceptions":        series.Exceptions,
+			"materializedUntil": series.MaterializedUntil,
+			"status":            series.Status,
+			"updatedAt":         now,
+			"version":           series.Version + 1,
+		}},
+	)
+	if err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	if result.MatchedCount == 0 {
+		return MeetingSeries{}, nil, lostUpdate(expectedVersion)
+	}
+	series.UpdatedAt = now
+	series.Version++
+
+	if err := deleteUpcomingOccurrences(ctx, series.ID, now); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	if _, err := materializeSeries(ctx, &series, until); err != nil {
+		return MeetingSeries{}, nil, err
+	}
+
+	meetings, err := upcomingOccurrences(ctx, series)
+	if err != nil {
+		return MeetingSeries{}, nil, err
+	}
+	return series, meetings, nil
+}
+
+// CancelSeries cancels the upcoming occurrences of a series and stops it, offering
+// the recipient's services to other volunteers again whoever cancels. Only the
+// participants or an administrator may cancel a series.
+func CancelSeries(ctx context.Context, actorID string, seriesID string) error {
+	ctx, span := tracer.Start(ctx, "services.CancelSeries")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	series, err := findSeries(ctx, seriesID)
+	if err != nil {
+		return err
+	}
+	actor, err := authorizeSeriesAccess(ctx, actorID, series)
+	if err != nil {
+		return err
+	}
+	if series.Status == SeriesCancelled {
+		return ErrSeriesCancelled
+	}
+
+	// Stop the series first so no occurrence is created meanwhile
+	now := time.Now()
+	result, err := database.SeriesCollection.UpdateOne(
+		ctx,
+		versionFilter(series.ID, series.Version),
+		bson.M{"$set": bson.M{"status": SeriesCancelled, "updatedAt": now, "version": series.Version + 1}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConcurrentUpdate
+	}
+
+	// The occurrences are kept cancelled, so calendars learn about it
+	_, err = database.MeetingsCollection.UpdateMany(
+		ctx,
+		bson.M{"seriesId": series.ID, "date": bson.M{"$gt": now}, "meetingStatus": IsPicked},
+		bson.M{
+			"$set": bson.M{"meetingStatus": Cancelled, "updatedAt": now},
+			"$inc": bson.M{"version": 1},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := releaseSeriesServices(ctx, series); err != nil {
+		return err
+	}
+
+	metrics.MeetingsCancelled.WithLabelValues(string(actor.Role)).Inc()
+	return nil
+}
+
+// MaterializeSeries creates the occurrences of every active series up to
+// SeriesHorizon ahead. Occurrences that clash with a meeting booked since, or no
+// longer fit the volunteer's schedule, are skipped.
+func MaterializeSeries(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "services.MaterializeSeries")
+	defer span.End()
+
+	until := time.Now().Add(SeriesHorizon)
+
+	cursor, err := database.SeriesCollection.Find(ctx, bson.M{
+		"status":            SeriesActive,
+		"materializedUntil": bson.M{"$lt": until},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var pending []MeetingSeries
+	if err = cursor.All(ctx, &pending); err != nil {
+		return err
+	}
+
+	for _, series := range pending {
+		// Each series gets its own timeout, since there may be many
+		seriesCtx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+		meetings, err := materializeLockedSeries(seriesCtx, &series, until)
+		cancel()
+		if errors.Is(err, ErrScheduleBusy) {
+			// Booked meanwhile, the series is picked up again on the next run
+			slog.WarnContext(ctx, "Postponed occurrences of series", "seriesId", series.ID, "error", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		metrics.MeetingsCreated.Add(float64(len(meetings)))
+	}
+	return nil
+}
+
+// materializeLockedSeries creates the occurrences of a series up to until while
+// holding the schedules of its participants
+func materializeLockedSeries(ctx context.Context, series *MeetingSeries, until time.Time) ([]Meeting, error) {
+	release, err := lockSchedules(ctx, series.VolunteerID, series.RecipientID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return materializeSeries(ctx, series, until)
+}
+
+// materializeSeries creates the occurrences of a series up to until,
+// except those cancelled on their own or clashing with another meeting, and records
+// how far it got. It returns the meetings created.
+func materializeSeries(ctx context.Context, series *MeetingSeries, until time.Time) ([]Meeting, error) {
+	rule, err := rrule.Parse(series.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	created := []Meeting{}
+	for _, slot := range seriesSlots(*series, until) {
+		err := checkSchedule(ctx, series.VolunteerID, series.RecipientID, slot, bson.M{"seriesId": series.ID, "occurrence": slot.Start})
+		if errors.Is(err, ErrConflict) {
+			slog.WarnContext(ctx, "Skipped occurrence of series", "seriesId", series.ID, "occurrence", slot.Start, "error", err)
+			continue
+		}
+		if err != nil {
+			return created, err
+		}
+
+		meeting := Meeting{
+			ID:            primitive.NewObjectID().Hex(),
+			RecipientID:   series.RecipientID,
+			VolunteerID:   series.VolunteerID,
+			Date:          slot.Start,
+			End:           slot.End,
+			Services:      series.Services,
+			MeetingStatus: IsPicked,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			Version:       1,
+			SeriesID:      series.ID,
+			Occurrence:    slot.Start,
+		}
+		if err := insertMeeting(ctx, meeting); err != nil {
+			// Created meanwhile by another instance
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			return created, err
+		}
+		created = append(created, meeting)
+	}
+
+	// A series whose last occurrence was created has ended
+	update := bson.M{"$max": bson.M{"materializedUntil": until}}
+	last, finite := rule.Last(series.Start.In(series.Location()))
+	ended := finite && last.Before(until)
+	if ended {
+		update["$set"] = bson.M{"status": SeriesEnded}
+		series.Status = SeriesEnded
+	}
+	result, err := database.SeriesCollection.UpdateOne(ctx, bson.M{"_id": series.ID, "status": SeriesActive}, update)
+	if err != nil {
+		return created, err
+	}
+
+	// Its services no longer need to stay claimed by the volunteer
+	if ended && result.MatchedCount > 0 {
+		if err := releaseSeriesServices(ctx, *series); err != nil {
+			return created, err
+		}
+	}
+	series.MaterializedUntil = later(series.MaterializedUntil, until)
+
+	return created, nil
+}
+
+// releaseSeriesServices offers the services claimed by a series to other volunteers
+// again. Services the recipient changed since, for instance to no longer need
+// them, are left alone.
+func releaseSeriesServices(ctx context.Context, series MeetingSeries) error {
+	for _, service := range series.Services {
+		field := fmt.Sprintf("services.%s", service)
+		_, err := database.UsersCollection.UpdateOne(
+			ctx,
+			bson.M{"_id": series.RecipientID, field: string(InProgress)},
+			bson.M{"$set": bson.M{field: string(NeedAssistance)}, "$inc": bson.M{"version": 1}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seriesSlots returns the occurrences of a series that are still to be created up
+// to until, except those cancelled on their own
+func seriesSlots(series MeetingSeries, until time.Time) []TimeSlot {
+	rule, err := rrule.Parse(series.Rule)
+	if err != nil {
+		return nil
+	}
+
+	start := series.Start.In(series.Location())
+	duration := time.Duration(series.Duration) * time.Second
+
+	var slots []TimeSlot
+	for _, occurrence := range rule.Between(start, later(series.MaterializedUntil, series.Start), until) {
+		if slices.ContainsFunc(series.Exceptions, occurrence.Equal) {
+			continue
+		}
+		slots = append(slots, TimeSlot{Start: occurrence, End: occurrence.Add(duration)})
+	}
+	return slots
+}
+
+// Location returns the timezone the occurrences of the series follow
+func (s MeetingSeries) Location() *time.Location {
+	return loadLocation(s.Timezone)
+}
+
+// later returns the later of two times
+func later(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// upcomingOccurrences returns the occurrences of a series that have not started yet,
+// with their participants
+func upcomingOccurrences(ctx context.Context, series MeetingSeries) ([]Meeting, error) {
+	cursor, err := database.MeetingsCollection.Find(ctx,
+		bson.M{"seriesId": series.ID, "date": bson.M{"$gte": time.Now()}},
+		options.Find().SetSort(bson.M{"date": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	meetings := []Meeting{}
+	if err = cursor.All(ctx, &meetings); err != nil {
+		return nil, err
+	}
+	if len(meetings) == 0 {
+		return meetings, nil
+	}
+
+	var recipient, volunteer User
+	if err := findUser(ctx, bson.M{"_id": series.RecipientID}, &recipient); err != nil {
+		return nil, fmt.Errorf("failed to load recipient data: %v", err)
+	}
+	if err := findUser(ctx, bson.M{"_id": series.VolunteerID}, &volunteer); err != nil {
+		return nil, fmt.Errorf("failed to load volunteer data: %v", err)
+	}
+	for i := range meetings {
+		meetings[i].Recipient = recipient
+		meetings[i].Volunteer = volunteer
+	}
+	return meetings, nil
+}
+
+// deleteUpcomingOccurrences deletes the occurrences of a series that have not started
+// by now and are not done, including those cancelled on their own
+func deleteUpcomingOccurrences(ctx context.Context, seriesID string, now time.Time) error {
+	_, err := database.MeetingsCollection.DeleteMany(ctx, bson.M{
+		"seriesId":      seriesID,
+		"date":          bson.M{"$gt": now},
+		"meetingStatus": bson.M{"$in": bson.A{IsPicked, Cancelled}},
+	})
+	return err
+}
+
+// findUserSeries returns the series a user takes part in and that match filter, oldest first
+func findUserSeries(ctx context.Context, uid string, filter bson.M) ([]MeetingSeries, error) {
+	filter["$or"] = []bson.M{{"recipientId": uid}, {"volunteerId": uid}}
+	cursor, err := database.SeriesCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	series := []MeetingSeries{}
+	if err = cursor.All(ctx, &series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// findSeries returns a series by ID
+func findSeries(ctx context.Context, seriesID string) (MeetingSeries, error) {
+	var series MeetingSeries
+	err := database.SeriesCollection.FindOne(ctx, bson.M{"_id": seriesID}).Decode(&series)
+	if err == mongo.ErrNoDocuments {
+		return MeetingSeries{}, ErrSeriesNotFound
+	}
+	return series, err
+}
+
+// authorizeSeriesAccess allows the participants of a series and administrators to
+// read and change it, and returns the acting user
+func authorizeSeriesAccess(ctx context.Context, actorID string, series MeetingSeries) (User, error) {
+	if actorID == "" {
+		return User{}, ErrNotAuthenticated
+	}
+
+	actor, err := findActiveUser(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return User{}, ErrNotAuthenticated
+		}
+		return User{}, err
+	}
+	if actor.ID != series.RecipientID && actor.ID != series.VolunteerID && actor.Role != Admin {
+		return User{}, ErrSeriesAccessDenied
+	}
+	return actor, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"time"
+
+	"neighborguard/pkg/session"
+	"neighborguard/pkg/validation"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Sessions signs the tokens that identify signed-in users. It must be set before
+// users can sign in.
+var Sessions *session.Signer
+
+// Credentials are what a user signs in with
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Session identifies a signed-in user until it expires
+type Session struct {
+	Token     string
+	UserID    string
+	ExpiresAt time.Time
+}
+
+// CreateSession signs a user in with their email and password. Unknown emails and
+// wrong passwords fail alike, so signing in does not tell which emails are registered.
+func CreateSession(ctx context.Context, credentials Credentials) (Session, error) {
+	ctx, span := tracer.Start(ctx, "services.CreateSession")
+	defer span.End()
+
+	v := &validation.Validator{}
+	v.Required("email", credentials.Email)
+	v.Required("password", credentials.Password)
+	if err := invalid(v.Err()); err != nil {
+		return Session{}, err
+	}
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	// Deleted users keep no password, but are excluded anyway
+	var user User
+	filter := bson.M{"$and": []bson.M{emailFilter(credentials.Email), {"deletedAt": bson.M{"$exists": false}}}}
+	err := findUser(ctx, filter, &user)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return Session{}, err
+	}
+	if err == mongo.ErrNoDocuments || user.Password == "" || subtle.ConstantTimeCompare([]byte(user.Password), []byte(credentials.Password)) != 1 {
+		return Session{}, ErrInvalidCredentials
+	}
+
+	token, expiresAt := Sessions.Issue(user.ID)
+	return Session{Token: token, UserID: user.ID, ExpiresAt: expiresAt}, nil
+}
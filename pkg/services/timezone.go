@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"neighborguard/pkg/database"
+	"neighborguard/pkg/validation"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DefaultTimezone is the IANA timezone of users who register without one,
+// overridden from the configuration at startup
+var DefaultTimezone = "UTC"
+
+// Locations loaded by IANA name, since loading one reads the timezone database
+var locations sync.Map
+
+// loadLocation returns the location of an IANA timezone, or UTC if the name is
+// empty or unknown
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	if location, ok := locations.Load(name); ok {
+		return location.(*time.Location)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	locations.Store(name, location)
+	return location
+}
+
+// Location returns the timezone of the user, UTC if they have none
+func (u User) Location() *time.Location {
+	return loadLocation(u.Timezone)
+}
+
+// validateTimezone checks that a timezone is an IANA name such as Europe/Paris
+func validateTimezone(v *validation.Validator, field string, name string) {
+	// LoadLocation also accepts the empty name and Local, both of the server
+	_, err := time.LoadLocation(name)
+	v.Check(err == nil && name != "" && name != "Local", field, "must be an IANA timezone such as Europe/Paris")
+}
+
+// checkInDeadline returns when the next check-in of a user is due. Whole days of
+// the threshold are calendar days in the user's timezone, so a recipient who checks
+// in every morning is not overdue when the clocks go back.
+func checkInDeadline(user User) time.Time {
+	days := int(CheckInThreshold / (24 * time.Hour))
+	return user.LastOK.In(user.Location()).AddDate(0, 0, days).Add(CheckInThreshold % (24 * time.Hour))
+}
+
+// EnsureTimestamps converts the times stored as Unix seconds before timestamps were
+// used into dates, and gives a timezone to users, schedules and series stored before
+// they had one. Schedules and series get UTC, the timezone they were expanded in.
+func EnsureTimestamps(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "services.EnsureTimestamps")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	migrations := []struct {
+		name       string
+		collection *mongo.Collection
+		filter     bson.M
+		update     bson.A
+	}{
+		{"users.lastOK", database.UsersCollection, numberFilter("lastOK"), setDates("lastOK")},
+		{"users.timezone", database.UsersCollection,
+			bson.M{"timezone": bson.M{"$exists": false}, "deletedAt": bson.M{"$exists": false}},
+			bson.A{bson.M{"$set": bson.M{"timezone": DefaultTimezone}}}},
+		{"meetings.date", database.MeetingsCollection, numberFilter("date"), setDates("date")},
+		{"meetings.end", database.MeetingsCollection, numberFilter("end"), setDates("end")},
+		{"meetings.occurrence", database.MeetingsCollection, numberFilter("occurrence"), setDates("occurrence")},
+		{"series.start", database.SeriesCollection, numberFilter("start"), setDates("start", "materializedUntil")},
+		{"series.exceptions", database.SeriesCollection, numberFilter("exceptions"),
+			bson.A{bson.M{"$set": bson.M{"exceptions": bson.M{"$map": bson.M{
+				"input": "$exceptions",
+				"in":    unixToDate("$$this"),
+			}}}}}},
+		{"series.timezone", database.SeriesCollection, bson.M{"timezone": bson.M{"$exists": false}},
+			bson.A{bson.M{"$set": bson.M{"timezone": "UTC"}}}},
+		{"availability.exceptions", database.AvailabilityCollection, numberFilter("exceptions.start"),
+			bson.A{bson.M{"$set": bson.M{"exceptions": bson.M{"$map": bson.M{
+				"input": "$exceptions",
+				"in": bson.M{"$mergeObjects": bson.A{"$$this", bson.M{
+					"start": unixToDate("$$this.start"),
+					"end":   unixToDate("$$this.end"),
+				}}},
+			}}}}}},
+		{"availability.timezone", database.AvailabilityCollection, bson.M{"timezone": bson.M{"$exists": false}},
+			bson.A{bson.M{"$set": bson.M{"timezone": "UTC"}}}},
+	}
+
+	for _, migration := range migrations {
+		result, err := migration.collection.UpdateMany(ctx, migration.filter, migration.update)
+		if err != nil {
+			return err
+		}
+		if result.ModifiedCount > 0 {
+			slog.InfoContext(ctx, "Migrated stored times", "field", migration.name, "documents", result.ModifiedCount)
+		}
+	}
+	return nil
+}
+
+// numberFilter matches documents where field, or an element of it, is a number
+func numberFilter(field string) bson.M {
+	return bson.M{field: bson.M{"$type": "number"}}
+}
+
+// setDates is an update pipeline converting fields from Unix seconds to dates
+func setDates(fields ...string) bson.A {
+	set := bson.M{}
+	for _, field := range fields {
+		set[field] = unixToDate("$" + field)
+	}
+	return bson.A{bson.M{"$set": set}}
+}
+
+// unixToDate is an aggregation expression converting Unix seconds to a date,
+// leaving values that are not numbers as they are
+func unixToDate(expression string) bson.M {
+	return bson.M{"$cond": bson.A{
+		bson.M{"$isNumber": expression},
+		bson.M{"$toDate": bson.M{"$multiply": bson.A{expression, 1000}}},
+		expression,
+	}}
+}
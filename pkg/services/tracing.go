@@ -0,0 +1,6 @@
+package services
+
+import "go.opentelemetry.io/otel"
+
+// tracer records a span for every service function, nested under the span of the request
+var tracer = otel.Tracer("neighborguard/pkg/services")
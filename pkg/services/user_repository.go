@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"neighborguard/pkg/database"
+	"neighborguard/pkg/fieldcrypt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Keyring encrypts the sensitive fields of users before they are stored.
+// Without a keyring, users are stored in clear text.
+var Keyring *fieldcrypt.Keyring
+
+// Fields of a user document that are encrypted at rest
+var encryptedUserFields = []string{"email", "phoneNumber", "address", "lonLat"}
+
+var errNoKeyring = errors.New("user data is encrypted but no keyring is configured")
+
+// findUser loads the user matching filter and decrypts its sensitive fields.
+// Like FindOne, it returns mongo.ErrNoDocuments if no user matches.
+func findUser(ctx context.Context, filter interface{}, user *User) error {
+	var document bson.M
+	if err := database.UsersCollection.FindOne(ctx, filter).Decode(&document); err != nil {
+		return err
+	}
+	return openUser(document, user)
+}
+
+// findUsers loads the users matching filter and decrypts their sensitive fields
+func findUsers(ctx context.Context, filter interface{}) ([]User, error) {
+	cursor, err := database.UsersCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []User
+	for cursor.Next(ctx) {
+		var document bson.M
+		if err := cursor.Decode(&document); err != nil {
+			return nil, err
+		}
+
+		var user User
+		if err := openUser(document, &user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, cursor.Err()
+}
+
+// insertUser stores a new user with its sensitive fields encrypted
+func insertUser(ctx context.Context, user User) error {
+	raw, err := bson.Marshal(user)
+	if err != nil {
+		return err
+	}
+	var document bson.M
+	if err := bson.Unmarshal(raw, &document); err != nil {
+		return err
+	}
+
+	if err := sealUserFields(user.ID, document); err != nil {
+		return err
+	}
+
+	_, err = database.UsersCollection.InsertOne(ctx, document)
+	return err
+}
+
+// emailFilter matches the user with the given email, whether it is stored
+// encrypted (through its blind index) or still in clear text
+func emailFilter(email string) bson.M {
+	if Keyring == nil {
+		return bson.M{"email": email}
+	}
+	return bson.M{"$or": []bson.M{
+		{"emailIndex": Keyring.BlindIndex(email)},
+		{"email": email},
+	}}
+}
+
+// sealUserFields encrypts the sensitive fields present in the document of the
+// user with the given ID or in the fields of a $set, and adds the blind index of the email
+func sealUserFields(uid string, fields bson.M) error {
+	if Keyring == nil {
+		return nil
+	}
+
+	if email, ok := fields["email"].(string); ok {
+		fields["emailIndex"] = Keyring.BlindIndex(email)
+	}
+
+	for _, field := range encryptedUserFields {
+		value, ok := fields[field]
+		if !ok {
+			continue
+		}
+
+		plaintext, err := bson.Marshal(bson.M{"value": value})
+		if err != nil {
+			return err
+		}
+		envelope, err := Keyring.Encrypt(plaintext, userFieldContext(uid, field))
+		if err != nil {
+			return err
+		}
+		fields[field] = envelope
+	}
+	return nil
+}
+
+// openUser decrypts the sensitive fields of a stored user document and decodes it
+func openUser(document bson.M, user *User) error {
+	uid, _ := document["_id"].(string)
+	for _, field := range encryptedUserFields {
+		envelope, sealed, err := storedEnvelope(document[field])
+		if err != nil {
+			return err
+		}
+		if !sealed {
+			// Stored before encryption was enabled
+			continue
+		}
+		if Keyring == nil {
+			return errNoKeyring
+		}
+
+		plaintext, err := Keyring.Decrypt(envelope, userFieldContext(uid, field))
+		if err != nil {
+			return err
+		}
+		var wrapper struct {
+			Value interface{} `bson:"value"`
+		}
+		if err := bson.Unmarshal(plaintext, &wrapper); err != nil {
+			return err
+		}
+		document[field] = wrapper.Value
+	}
+
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+	if err := bson.Unmarshal(raw, user); err != nil {
+		return err
+	}
+
+	// Times are stored in UTC, the user sees them in their own timezone
+	user.LastOK = user.LastOK.In(user.Location())
+	return nil
+}
+
+// userFieldContext binds an encrypted value to both the field and the user it
+// was stored for, so it cannot be copied into another field or another user
+func userFieldContext(uid string, field string) string {
+	return "users/" + uid + "/" + field
+}
+
+// storedEnvelope tells whether a stored value is encrypted and returns its envelope
+func storedEnvelope(value interface{}) (fieldcrypt.Envelope, bool, error) {
+	document, ok := value.(bson.M)
+	if !ok || document["ciphertext"] == nil {
+		return fieldcrypt.Envelope{}, false, nil
+	}
+
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return fieldcrypt.Envelope{}, false, err
+	}
+	var envelope fieldcrypt.Envelope
+	if err := bson.Unmarshal(raw, &envelope); err != nil {
+		return fieldcrypt.Envelope{}, false, err
+	}
+	return envelope, true, nil
+}
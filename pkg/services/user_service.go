@@ -2,19 +2,24 @@ package services
 
 import (
 	"context"
-	"errors"
+	"log/slog"
 	"neighborguard/pkg/database"
 	"sort"
 	"time"
-	"fmt"
 
-	"github.com/umahmood/haversine"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-
+// Settings of the service layer, overridden from the configuration at startup
+var (
+	DatabaseTimeout  = 10 * time.Second // applied to every database operation
+	SearchRadiusKm   = 1.0              // how far from the filter location recipients are searched
+	CheckInThreshold = time.Minute      // how long after their last check-in recipients need a General Check
+)
 
 type Gender string
 type Role string
@@ -28,6 +33,7 @@ const (
 const (
 	Volunteer Role = "VOLUNTEER"
 	Recipient Role = "RECIPIENT"
+	Admin     Role = "ADMIN" // granted in the database only, never through the API
 )
 
 const (
@@ -60,11 +66,13 @@ type NewUser struct {
 	Password     string                             `json:"password"`
 	Address      Address                            `json:"address"`
 	Languages    []string                           `json:"languages"`
-	Services     map[string]MeetingAssistanceStatus `json:"services"` //map[ServiceName]MeetingAssistanceStatus
+	Services     map[string]MeetingAssistanceStatus `json:"services"` //map[ServiceCatalogueID]MeetingAssistanceStatus
 	Role         Role                               `json:"role"`
 	LonLat       LonLat                             `json:"lonLat"`
-	LastOK       int64                              `json:"lastOK"`
+	Timezone     string                             `json:"timezone"` // IANA timezone such as Europe/Paris, DefaultTimezone if empty
+	LastOK       time.Time                          `json:"lastOK"`
 	ProfileImage string                             `json:"profileImage"`
+	Privacy      PrivacySettings                    `json:"privacy"`
 }
 
 type User struct {
@@ -81,57 +89,67 @@ type User struct {
 	Services     map[string]MeetingAssistanceStatus `json:"services" bson:"services"`
 	Role         Role                               `json:"role" bson:"role"`
 	LonLat       LonLat                             `json:"lonLat" bson:"lonLat"`
-	LastOK       int64                              `json:"lastOK" bson:"lastOK"`
+	Timezone     string                             `json:"timezone" bson:"timezone"` // IANA timezone that schedules and check-ins follow
+	LastOK       time.Time                          `json:"lastOK" bson:"lastOK"`     // last check-in
 	ProfileImage string                             `json:"profileImage" bson:"profileImage"`
+	Privacy      PrivacySettings                    `json:"privacy" bson:"privacy"`
+	Verified     bool                               `json:"verified" bson:"verified"` // set by administrators only
+	Version      int64                              `json:"version" bson:"version"`   // incremented on every update
 	CreatedAt    time.Time                          `json:"createdAt" bson:"createdAt"`
 	UpdatedAt    time.Time                          `json:"updatedAt" bson:"updatedAt"`
+	DeletedAt    *time.Time                         `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"` // set once the account is deleted and anonymized
 }
 
-
 func GetNearbyRecipients(
+	ctx context.Context,
 	volunteerUID string,
 	filterByLat *float64,
 	filterByLon *float64,
-) ([]User, error) {
+) ([]NearbyRecipient, error) {
+	ctx, span := tracer.Start(ctx, "services.GetNearbyRecipients")
+	defer span.End()
+
 	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
 	defer cancel()
 
 	// Get the volunteer's details from MongoDB
 	var volunteer User
-	err := database.UsersCollection.FindOne(ctx, bson.M{"_id": volunteerUID}).Decode(&volunteer)
+	err := findUser(ctx, bson.M{"_id": volunteerUID}, &volunteer)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("volunteer not found")
+			return nil, ErrVolunteerNotFound
 		}
 		return nil, err
 	}
 
 	// Verify that the user is actually a volunteer
 	if volunteer.Role != Volunteer {
-		return nil, errors.New("only volunteers can use this endpoint")
+		return nil, ErrVolunteersOnly
 	}
 
-	// Find all recipients in the database
-	cursor, err := database.UsersCollection.Find(ctx, bson.M{"role": string(Recipient)})
+	// Load all recipients who did not opt out of proximity search into memory
+	recipients, err := findUsers(ctx, bson.M{
+		"role":                   string(Recipient),
+		"privacy.hideFromSearch": bson.M{"$ne": true},
+		"deletedAt":              bson.M{"$exists": false},
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	// Load all recipients into memory
-	var recipients []User
-	if err = cursor.All(ctx, &recipients); err != nil {
-		return nil, err
+	// Distances are measured from the filter location if provided, otherwise from the volunteer
+	origin := volunteer.LonLat
+	if filterByLat != nil && filterByLon != nil {
+		origin = LonLat{Longitude: *filterByLon, Latitude: *filterByLat}
 	}
 
 	// Apply additional filtering criteria in memory
-	var filtered []User
+	var filtered []NearbyRecipient
 	for _, user := range recipients {
 		// Filter by location if coordinates are provided
 		if filterByLat != nil && filterByLon != nil {
-			nearLocation := LonLat{Longitude: *filterByLon, Latitude: *filterByLat}
-			if !isInLocation(user.LonLat, nearLocation) {
+			if !isInLocation(user.LonLat, origin) {
 				continue
 			}
 		}
@@ -142,25 +160,25 @@ func GetNearbyRecipients(
 		}
 
 		// Check for service matching and assistance need
-		if !checkAssistanceAndServices(user, volunteer) {
+		if !checkAssistanceAndServices(ctx, user, volunteer) {
 			continue
 		}
 
-		filtered = append(filtered, user)
+		filtered = append(filtered, NearbyRecipient{Recipient: user, Origin: origin})
 	}
 
 	// Sort recipients by priority (General Check needs and LastOK time)
 	sort.Slice(filtered, func(i, j int) bool {
-		recipientI := filtered[i]
-		recipientJ := filtered[j]
+		recipientI := filtered[i].Recipient
+		recipientJ := filtered[j].Recipient
 
 		// Check if either recipient needs General Check
-		needsGeneralCheckI := recipientI.Services["General Check"] == NeedAssistance
-		needsGeneralCheckJ := recipientJ.Services["General Check"] == NeedAssistance
+		needsGeneralCheckI := recipientI.Services[GeneralCheck] == NeedAssistance
+		needsGeneralCheckJ := recipientJ.Services[GeneralCheck] == NeedAssistance
 
 		// If both have the same General Check status, sort by LastOK time
 		if needsGeneralCheckI == needsGeneralCheckJ {
-			return recipientI.LastOK < recipientJ.LastOK
+			return recipientI.LastOK.Before(recipientJ.LastOK)
 		}
 
 		// Prioritize recipients who need General Check
@@ -170,25 +188,42 @@ func GetNearbyRecipients(
 	return filtered, nil
 }
 
+func CreateUser(ctx context.Context, newUser NewUser) (User, error) {
+	ctx, span := tracer.Start(ctx, "services.CreateUser")
+	defer span.End()
+
+	// Reject invalid payloads before touching the database
+	if err := newUser.Validate(); err != nil {
+		return User{}, err
+	}
 
-func CreateUser(newUser NewUser) (User, error) {
 	// Create a context with timeout for database operations
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
 	defer cancel()
 
+	// Reference services by their catalogue ID
+	userServices, err := resolveUserServices(ctx, newUser.Services)
+	if err != nil {
+		return User{}, err
+	}
+
 	// Check if email already exists to prevent duplicates
-	existingFilter := bson.M{"email": newUser.Email}
-	count, err := database.UsersCollection.CountDocuments(ctx, existingFilter)
+	count, err := database.UsersCollection.CountDocuments(ctx, emailFilter(newUser.Email))
 	if err != nil {
 		return User{}, err
 	}
 	if count > 0 {
-		return User{}, errors.New("user with this email already exists")
+		return User{}, ErrEmailTaken
 	}
 
 	// Get current time for timestamps
 	now := time.Now()
-	
+
+	timezone := newUser.Timezone
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+
 	// Create a new user with a MongoDB ObjectID
 	user := User{
 		ID:           primitive.NewObjectID().Hex(), // Generate a new MongoDB ObjectID
@@ -198,75 +233,229 @@ func CreateUser(newUser NewUser) (User, error) {
 		PhoneNumber:  newUser.PhoneNumber,
 		Gender:       newUser.Gender,
 		Email:        newUser.Email,
-		Password:     newUser.Password,  // Note: In production, passwords should be hashed
+		Password:     newUser.Password, // Note: In production, passwords should be hashed
 		Address:      newUser.Address,
 		Languages:    newUser.Languages,
-		Services:     newUser.Services,
+		Services:     userServices,
 		Role:         newUser.Role,
 		LonLat:       newUser.LonLat,
-		LastOK:       now.Unix(),
+		Timezone:     timezone,
+		LastOK:       now.In(loadLocation(timezone)),
 		ProfileImage: newUser.ProfileImage,
+		Privacy:      newUser.Privacy,
 		CreatedAt:    now,
 		UpdatedAt:    now,
+		Version:      1,
 	}
 
 	// Insert the user document into MongoDB
-	_, err = database.UsersCollection.InsertOne(ctx, user)
+	err = insertUser(ctx, user)
 	if err != nil {
 		return User{}, err
 	}
 
+	// Registering counts as the first check-in
+	recordCheckIn(ctx, user.ID, user.LastOK)
+
 	return user, nil
 }
 
+// UpdateUser replaces the updatable fields of a user. Only the user themselves or an
+// administrator may update a profile. An expectedVersion other than AnyVersion
+// makes the update fail unless the stored user still has that version.
+func UpdateUser(ctx context.Context, actorID string, uid string, updatedUser User, expectedVersion int64) (User, error) {
+	ctx, span := tracer.Start(ctx, "services.UpdateUser")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	if err := authorizeAccountAccess(ctx, actorID, uid); err != nil {
+		return User{}, err
+	}
+
+	// Verify the user exists before updating
+	existingUser, err := findActiveUser(ctx, uid)
+	if err != nil {
+		return User{}, err
+	}
+
+	if err := checkVersion(existingUser.Version, expectedVersion); err != nil {
+		return User{}, err
+	}
+
+	return saveUser(ctx, existingUser, updatedUser, expectedVersion)
+}
+
+// PatchUser applies a JSON Merge Patch to the updatable fields of a user. Only the user
+// themselves or an administrator may update a profile.
+// An expectedVersion other than AnyVersion makes the update fail unless the stored user still has that version.
+func PatchUser(ctx context.Context, actorID string, uid string, patch []byte, expectedVersion int64) (User, error) {
+	ctx, span := tracer.Start(ctx, "services.PatchUser")
+	defer span.End()
 
-func UpdateUser(uid string, updatedUser User) error {
 	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
 	defer cancel()
 
+	if err := authorizeAccountAccess(ctx, actorID, uid); err != nil {
+		return User{}, err
+	}
+
 	// Verify the user exists before updating
-	var existingUser User
-	err := database.UsersCollection.FindOne(ctx, bson.M{"_id": uid}).Decode(&existingUser)
+	existingUser, err := findActiveUser(ctx, uid)
+	if err != nil {
+		return User{}, err
+	}
+
+	if err := checkVersion(existingUser.Version, expectedVersion); err != nil {
+		return User{}, err
+	}
+
+	// Fields missing from the patch keep their stored value
+	var updatedUser User
+	if err := applyMergePatch(existingUser, patch, updatableUserFields, &updatedUser); err != nil {
+		return User{}, err
+	}
+
+	return saveUser(ctx, existingUser, updatedUser, expectedVersion)
+}
+
+// saveUser validates and stores the updatable fields of a user, bumping its version.
+// The write only succeeds if nobody else updated the user since it was read.
+func saveUser(ctx context.Context, existingUser User, updatedUser User, expectedVersion int64) (User, error) {
+	// The role cannot be updated, but it decides which service statuses are valid
+	updatedUser.Role = existingUser.Role
+
+	// Clients that do not know about timezones keep the stored one
+	if updatedUser.Timezone == "" {
+		updatedUser.Timezone = existingUser.Timezone
+	}
+
+	// Services in progress belong to their meetings, whatever the client sends for them
+	inProgress := map[string]MeetingAssistanceStatus{}
+	for service, status := range existingUser.Services {
+		if status == InProgress {
+			inProgress[service] = status
+			delete(updatedUser.Services, service)
+		}
+	}
+
+	if err := updatedUser.Validate(); err != nil {
+		return User{}, err
+	}
+
+	// Reference services by their catalogue ID
+	userServices, err := resolveUserServices(ctx, updatedUser.Services)
+	if err != nil {
+		return User{}, err
+	}
+	if userServices == nil && len(inProgress) > 0 {
+		userServices = map[string]MeetingAssistanceStatus{}
+	}
+	for service, status := range inProgress {
+		userServices[service] = status
+	}
+
+	// Create an update operation with the fields to be modified, encrypting the sensitive ones
+	now := time.Now()
+	fields := bson.M{
+		"firstName":    updatedUser.FirstName,
+		"lastName":     updatedUser.LastName,
+		"phoneNumber":  updatedUser.PhoneNumber,
+		"languages":    updatedUser.Languages,
+		"services":     userServices,
+		"address":      updatedUser.Address,
+		"lonLat":       updatedUser.LonLat,
+		"timezone":     updatedUser.Timezone,
+		"lastOK":       updatedUser.LastOK,
+		"profileImage": updatedUser.ProfileImage,
+		"privacy":      updatedUser.Privacy,
+		"updatedAt":    now,
+		"version":      existingUser.Version + 1,
+	}
+	if err := sealUserFields(existingUser.ID, fields); err != nil {
+		return User{}, err
+	}
+	update := bson.M{"$set": fields}
+
+	// Execute the update in MongoDB, only if the user was not modified meanwhile
+	result, err := database.UsersCollection.UpdateOne(ctx, versionFilter(existingUser.ID, existingUser.Version), update)
+	if err != nil {
+		return User{}, err
+	}
+	if result.MatchedCount == 0 {
+		return User{}, lostUpdate(expectedVersion)
+	}
+
+	// A new LastOK means the user checked in
+	if !updatedUser.LastOK.Equal(existingUser.LastOK) {
+		recordCheckIn(ctx, existingUser.ID, updatedUser.LastOK)
+	}
+
+	// Return the user as stored
+	user := existingUser
+	user.FirstName = updatedUser.FirstName
+	user.LastName = updatedUser.LastName
+	user.PhoneNumber = updatedUser.PhoneNumber
+	user.Languages = updatedUser.Languages
+	user.Services = userServices
+	user.Address = updatedUser.Address
+	user.LonLat = updatedUser.LonLat
+	user.Timezone = updatedUser.Timezone
+	user.LastOK = updatedUser.LastOK.In(user.Location())
+	user.ProfileImage = updatedUser.ProfileImage
+	user.Privacy = updatedUser.Privacy
+	user.UpdatedAt = now
+	user.Version = existingUser.Version + 1
+	return user, nil
+}
+
+func GetUserByID(ctx context.Context, uid string) (*User, error) {
+	ctx, span := tracer.Start(ctx, "services.GetUserByID")
+	defer span.End()
+
+	// Create a context with timeout
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	// Query MongoDB for the user with the specified ID
+	user, err := findActiveUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// findActiveUser loads a user by ID, treating deleted accounts as missing
+func findActiveUser(ctx context.Context, uid string) (User, error) {
+	var user User
+	err := findUser(ctx, bson.M{"_id": uid, "deletedAt": bson.M{"$exists": false}}, &user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return errors.New("user not found")
+			return User{}, ErrUserNotFound
 		}
-		return err
-	}
-
-	// Create an update operation with the fields to be modified
-	update := bson.M{
-		"$set": bson.M{
-			"firstName":    updatedUser.FirstName,
-			"lastName":     updatedUser.LastName,
-			"phoneNumber":  updatedUser.PhoneNumber,
-			"languages":    updatedUser.Languages,
-			"services":     updatedUser.Services,
-			"address":      updatedUser.Address,
-			"lonLat":       updatedUser.LonLat,
-			"lastOK":       updatedUser.LastOK,
-			"profileImage": updatedUser.ProfileImage,
-			"updatedAt":    time.Now(),
-		},
-	}
-
-	// Execute the update in MongoDB
-	_, err = database.UsersCollection.UpdateOne(ctx, bson.M{"_id": uid}, update)
-	return err
+		return User{}, err
+	}
+	return user, nil
 }
 
-func GetUserByEmail(email string) (*User, error) {
+func GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	ctx, span := tracer.Start(ctx, "services.GetUserByEmail")
+	defer span.End()
+
 	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
 	defer cancel()
 
 	// Query MongoDB for a user with the specified email
 	var user User
-	err := database.UsersCollection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	err := findUser(ctx, emailFilter(email), &user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -290,91 +479,90 @@ func hasCommonElements(slice1, slice2 []string) bool {
 	return false
 }
 
-// Helper function to check if a user is within 1km of a location
+// Helper function to check if a user is within the search radius of a location
 func isInLocation(userLonLat LonLat, nearLocation LonLat) bool {
-	point1 := haversine.Coord{Lat: userLonLat.Latitude, Lon: userLonLat.Longitude}
-	point2 := haversine.Coord{Lat: nearLocation.Latitude, Lon: nearLocation.Longitude}
-
-	_, km := haversine.Distance(point1, point2)
-	return km <= 1.0
+	return Distance(userLonLat, nearLocation) <= SearchRadiusKm
 }
 
-func checkAssistanceAndServices(recipient User, volunteer User) bool {
-    // Initialize services map if nil
-    if recipient.Services == nil {
-        recipient.Services = make(map[string]MeetingAssistanceStatus)
-    }
-
-    // Print recipient's services for debugging
-    fmt.Printf("Checking services for recipient %s: %+v\n", recipient.ID, recipient.Services)
-
-    // Check for time-based general check need
-    timeBasedNeed := time.Now().Unix() - recipient.LastOK > 60 // 1 minute for testing
-    
-    // Track if services were updated
-    updated := false
-    
-    // If time-based need detected, update General Check status in MongoDB
-    if timeBasedNeed && recipient.Services["General Check"] != NeedAssistance && recipient.Services["General Check"] != InProgress {
-        fmt.Printf("Recipient %s needs General Check (time-based)\n", recipient.ID)
-        
-        // Update the recipient's General Check service in MongoDB
-        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-        defer cancel()
-        
-        // Set the General Check service to NeedAssistance
-        update := bson.M{"$set": bson.M{"services.General Check": string(NeedAssistance)}}
-        
-        // Update the document in MongoDB
-        result, err := database.UsersCollection.UpdateOne(ctx, bson.M{"_id": recipient.ID}, update)
-        if err != nil {
-            // Log the error but continue processing
-            fmt.Printf("Error updating General Check status: %v\n", err)
-        } else {
-            fmt.Printf("Updated General Check status for recipient %s: %+v\n", recipient.ID, result)
-            updated = true
-        }
-        
-        // Also update our local copy of the recipient for this function
-        recipient.Services["General Check"] = NeedAssistance
-    }
-
-    // Check for any service in NEED_ASSISTANCE that matches volunteer's provided services
-    hasMatchingService := false
-    for service, status := range recipient.Services {
-        // Skip services already in progress
-        if status == InProgress {
-            fmt.Printf("Service %s is already in progress for recipient %s\n", service, recipient.ID)
-            continue
-        }
-
-        // Check if this service needs assistance and volunteer can provide
-        if status == NeedAssistance && volunteer.Services[service] == Provide {
-            fmt.Printf("Found matching service %s for recipient %s\n", service, recipient.ID)
-            hasMatchingService = true
-            break
-        }
-    }
-
-    // If we updated the recipient's services but didn't find a matching service yet, 
-    // reload the recipient from the database to get the freshest data
-    if updated && !hasMatchingService {
-        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-        defer cancel()
-        
-        var updatedRecipient User
-        err := database.UsersCollection.FindOne(ctx, bson.M{"_id": recipient.ID}).Decode(&updatedRecipient)
-        if err == nil {
-            // Check again with the fresh data
-            for service, status := range updatedRecipient.Services {
-                if status == NeedAssistance && volunteer.Services[service] == Provide {
-                    fmt.Printf("Found matching service %s for recipient %s after refresh\n", service, recipient.ID)
-                    hasMatchingService = true
-                    break
-                }
-            }
-        }
-    }
-
-    return hasMatchingService
+func checkAssistanceAndServices(ctx context.Context, recipient User, volunteer User) bool {
+	ctx, span := tracer.Start(ctx, "services.checkAssistanceAndServices", trace.WithAttributes(attribute.String("neighborguard.recipient_id", recipient.ID)))
+	defer span.End()
+
+	// Initialize services map if nil
+	if recipient.Services == nil {
+		recipient.Services = make(map[string]MeetingAssistanceStatus)
+	}
+
+	// Print recipient's services for debugging
+	slog.DebugContext(ctx, "Checking services of recipient", "recipientId", recipient.ID, "services", recipient.Services)
+
+	// Check for time-based general check need
+	timeBasedNeed := time.Now().After(checkInDeadline(recipient))
+
+	// Track if services were updated
+	updated := false
+
+	// If time-based need detected, update General Check status in MongoDB
+	if timeBasedNeed && recipient.Services[GeneralCheck] != NeedAssistance && recipient.Services[GeneralCheck] != InProgress {
+		slog.DebugContext(ctx, "Recipient needs a General Check", "recipientId", recipient.ID)
+
+		// Update the recipient's General Check service in MongoDB
+		ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+		defer cancel()
+
+		// Set the General Check service to NeedAssistance
+		update := bson.M{"$set": bson.M{"services." + GeneralCheck: string(NeedAssistance)}, "$inc": bson.M{"version": 1}}
+
+		// Update the document in MongoDB
+		result, err := database.UsersCollection.UpdateOne(ctx, bson.M{"_id": recipient.ID}, update)
+		if err != nil {
+			// Log the error but continue processing
+			slog.ErrorContext(ctx, "Error updating General Check status", "recipientId", recipient.ID, "error", err)
+		} else {
+			slog.DebugContext(ctx, "Updated General Check status", "recipientId", recipient.ID, "modified", result.ModifiedCount)
+			updated = true
+		}
+
+		// Also update our local copy of the recipient for this function
+		recipient.Services[GeneralCheck] = NeedAssistance
+	}
+
+	// Check for any service in NEED_ASSISTANCE that matches volunteer's provided services
+	hasMatchingService := false
+	for service, status := range recipient.Services {
+		// Skip services already in progress
+		if status == InProgress {
+			slog.DebugContext(ctx, "Service already in progress", "recipientId", recipient.ID, "service", service)
+			continue
+		}
+
+		// Check if this service needs assistance and volunteer can provide
+		if status == NeedAssistance && volunteer.Services[service] == Provide {
+			slog.DebugContext(ctx, "Found matching service", "recipientId", recipient.ID, "service", service)
+			hasMatchingService = true
+			break
+		}
+	}
+
+	// If we updated the recipient's services but didn't find a matching service yet,
+	// reload the recipient from the database to get the freshest data
+	if updated && !hasMatchingService {
+		ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+		defer cancel()
+
+		var updatedRecipient User
+		err := findUser(ctx, bson.M{"_id": recipient.ID}, &updatedRecipient)
+		if err == nil {
+			// Check again with the fresh data
+			for service, status := range updatedRecipient.Services {
+				if status == NeedAssistance && volunteer.Services[service] == Provide {
+					slog.DebugContext(ctx, "Found matching service after refresh", "recipientId", recipient.ID, "service", service)
+					hasMatchingService = true
+					break
+				}
+			}
+		}
+	}
+
+	return hasMatchingService
 }
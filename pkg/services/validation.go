@@ -0,0 +1,221 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"neighborguard/pkg/rrule"
+	"neighborguard/pkg/validation"
+)
+
+// Longest service name accepted in a payload
+const maxServiceNameLength = 64
+
+// Most windows and exceptions a volunteer schedule may have
+const (
+	maxAvailabilityWindows    = 50
+	maxAvailabilityExceptions = 100
+)
+
+// How far ahead meetings may be scheduled, and how long ago their start may be to
+// allow for the clock of the client being late
+const (
+	maxMeetingLeadTime    = 366 * 24 * time.Hour
+	meetingClockTolerance = 5 * time.Minute
+)
+
+// Validate checks a user registration payload
+func (u NewUser) Validate() error {
+	v := &validation.Validator{}
+
+	v.Email("email", u.Email)
+	validation.OneOf(v, "role", u.Role, Volunteer, Recipient)
+	validateProfile(v, User{
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Age:       u.Age,
+		Gender:    u.Gender,
+		Languages: u.Languages,
+		Services:  u.Services,
+		Role:      u.Role,
+		LonLat:    u.LonLat,
+		Timezone:  u.Timezone,
+		Privacy:   u.Privacy,
+	})
+
+	return invalid(v.Err())
+}
+
+// Validate checks a user update payload. The role must already be set from
+// the stored user since it decides which service statuses are allowed.
+func (u User) Validate() error {
+	v := &validation.Validator{}
+	validateProfile(v, u)
+	return invalid(v.Err())
+}
+
+// Validate checks a meeting creation payload. Rules that depend on the stored
+// users are checked by CreateMeeting once they are loaded.
+func (m NewMeeting) Validate() error {
+	v := &validation.Validator{}
+
+	v.Required("recipient.uid", m.Recipient.ID)
+	v.Required("volunteer.uid", m.Volunteer.ID)
+	v.Check(m.Recipient.ID == "" || m.Recipient.ID != m.Volunteer.ID, "volunteer.uid", "must differ from the recipient")
+	validateMeetingDate(v, "date", m.Date)
+
+	// The server decides the initial status of a meeting
+	v.Check(m.MeetingStatus == "" || m.MeetingStatus == IsPicked, "meetingStatus", fmt.Sprintf("must be empty or %s", IsPicked))
+
+	v.Check(len(m.Services) > 0, "services", "must contain at least one service")
+	for i, service := range m.Services {
+		validateServiceName(v, fmt.Sprintf("services[%d]", i), service)
+	}
+
+	return invalid(v.Err())
+}
+
+// Validate checks a volunteer schedule payload
+func (a NewAvailability) Validate() error {
+	v := &validation.Validator{}
+
+	v.Check(len(a.Weekly) <= maxAvailabilityWindows, "weekly", fmt.Sprintf("must contain at most %d windows", maxAvailabilityWindows))
+	for i, window := range a.Weekly {
+		field := fmt.Sprintf("weekly[%d]", i)
+		_, validDay := weekdays[window.Day]
+		v.Check(validDay, field+".day", "must be a day of the week such as MONDAY")
+		start, validStart := parseTimeOfDay(window.Start)
+		end, validEnd := parseTimeOfDay(window.End)
+		v.Check(validStart && start < 24*time.Hour, field+".start", "must be a time of day such as 09:00")
+		v.Check(validEnd, field+".end", "must be a time of day such as 17:00, or 24:00")
+		v.Check(!validStart || !validEnd || start < end, field+".end", "must be after the start")
+	}
+
+	if a.Timezone != "" {
+		validateTimezone(v, "timezone", a.Timezone)
+	}
+
+	v.Check(len(a.Exceptions) <= maxAvailabilityExceptions, "exceptions", fmt.Sprintf("must contain at most %d exceptions", maxAvailabilityExceptions))
+	for i, exception := range a.Exceptions {
+		field := fmt.Sprintf("exceptions[%d]", i)
+		v.Check(!exception.Start.IsZero(), field+".start", "is required")
+		v.Check(exception.End.After(exception.Start), field+".end", "must be after the start")
+	}
+
+	return invalid(v.Err())
+}
+
+// validateMeetingDate checks the start of a new or rescheduled meeting or series
+func validateMeetingDate(v *validation.Validator, field string, date time.Time) {
+	now := time.Now()
+	v.Check(!date.IsZero(), field, "is required")
+	v.Check(date.IsZero() || !date.Before(now.Add(-meetingClockTolerance)), field, "must not be in the past")
+	v.Check(!date.After(now.Add(maxMeetingLeadTime)), field, "must be within a year")
+}
+
+// Validate checks a meeting series payload. Rules that depend on the stored
+// users, such as whether the start falls on a day of the rule in the recipient's
+// timezone, are checked by CreateSeries once they are loaded.
+func (s NewSeries) Validate() error {
+	v := &validation.Validator{}
+
+	v.Required("recipient.uid", s.Recipient.ID)
+	v.Required("volunteer.uid", s.Volunteer.ID)
+	v.Check(s.Recipient.ID == "" || s.Recipient.ID != s.Volunteer.ID, "volunteer.uid", "must differ from the recipient")
+	validateMeetingDate(v, "start", s.Start)
+	v.Required("rule", s.Rule)
+
+	v.Check(len(s.Services) > 0, "services", "must contain at least one service")
+	for i, service := range s.Services {
+		validateServiceName(v, fmt.Sprintf("services[%d]", i), service)
+	}
+
+	return invalid(v.Err())
+}
+
+// validateSeriesRule checks the recurrence rule of a series starting at start, in
+// the timezone of the series
+func validateSeriesRule(v *validation.Validator, start time.Time, value string) {
+	v.Required("rule", value)
+	if value == "" {
+		return
+	}
+	rule, err := rrule.Parse(value)
+	if err != nil {
+		v.AddError("rule", err.Error())
+		return
+	}
+	v.Check(rule.Matches(start), "start", "must fall on one of the days of the rule")
+}
+
+// validateTimeRange checks a range of times to look for free slots in
+func validateTimeRange(from time.Time, to time.Time) error {
+	v := &validation.Validator{}
+	v.Check(to.After(from), "to", "must be after from")
+	v.Check(to.Sub(from) <= maxAvailabilityRange, "to", fmt.Sprintf("must be at most %d days after from", int(maxAvailabilityRange/(24*time.Hour))))
+	return invalid(v.Err())
+}
+
+// validateMeetingParticipants checks that the users of a meeting have the expected roles
+func validateMeetingParticipants(recipient User, volunteer User) error {
+	v := &validation.Validator{}
+	v.Check(recipient.Role == Recipient, "recipient.uid", fmt.Sprintf("user must have the %s role", Recipient))
+	v.Check(volunteer.Role == Volunteer, "volunteer.uid", fmt.Sprintf("user must have the %s role", Volunteer))
+	return invalid(v.Err())
+}
+
+// validateProfile checks the fields shared by registration and update payloads.
+// Services in progress are set by meetings, never by clients.
+func validateProfile(v *validation.Validator, u User) {
+	v.Required("firstName", u.FirstName)
+	v.Required("lastName", u.LastName)
+	v.IntRange("age", u.Age, 1, 120)
+	validation.OneOf(v, "gender", u.Gender, Male, Female)
+	v.FloatRange("lonLat.latitude", u.LonLat.Latitude, -90, 90)
+	v.FloatRange("lonLat.longitude", u.LonLat.Longitude, -180, 180)
+	if u.Timezone != "" {
+		validateTimezone(v, "timezone", u.Timezone)
+	}
+
+	for i, language := range u.Languages {
+		v.Required(fmt.Sprintf("languages[%d]", i), language)
+	}
+
+	if u.Privacy.LocationPrecision != "" {
+		validation.OneOf(v, "privacy.locationPrecision", u.Privacy.LocationPrecision,
+			ExactPrecision, StreetPrecision, NeighbourhoodPrecision)
+	}
+
+	// Volunteers declare what they provide, recipients declare what they need
+	var allowed []MeetingAssistanceStatus
+	switch u.Role {
+	case Volunteer:
+		allowed = []MeetingAssistanceStatus{Provide, DoNotProvide}
+	case Recipient:
+		allowed = []MeetingAssistanceStatus{NeedAssistance, DoNotNeedAssistance}
+	}
+
+	// Walk services in a stable order so errors are reported consistently
+	names := make([]string, 0, len(u.Services))
+	for service := range u.Services {
+		names = append(names, service)
+	}
+	sort.Strings(names)
+
+	for _, service := range names {
+		field := fmt.Sprintf("services.%s", service)
+		validateServiceName(v, field, service)
+		if allowed != nil {
+			validation.OneOf(v, field, u.Services[service], allowed...)
+		}
+	}
+}
+
+// validateServiceName rejects names that cannot safely be used as a MongoDB field path
+func validateServiceName(v *validation.Validator, field string, service string) {
+	v.Required(field, service)
+	v.Check(len(service) <= maxServiceNameLength, field, fmt.Sprintf("must be at most %d characters", maxServiceNameLength))
+	v.Check(!strings.ContainsAny(service, ".$"), field, "must not contain '.' or '$'")
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"neighborguard/pkg/validation"
+)
+
+// invalidFields returns the fields reported by a validation error
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var fieldErrors validation.Errors
+	if !errors.As(err, &fieldErrors) {
+		t.Fatalf("error = %v, want validation errors", err)
+	}
+	fields := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		fields = append(fields, fieldError.Field)
+	}
+	return fields
+}
+
+func validNewUser() NewUser {
+	return NewUser{
+		FirstName: "Ada",
+		LastName:  "Lovelace",
+		Age:       72,
+		Gender:    Female,
+		Email:     "ada@example.com",
+		Languages: []string{"en", "fr"},
+		Services:  map[string]MeetingAssistanceStatus{"Shopping": NeedAssistance, "Walk": DoNotNeedAssistance},
+		Role:      Recipient,
+		LonLat:    LonLat{Longitude: 4.8357, Latitude: 45.764},
+	}
+}
+
+func TestNewUserValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(u *NewUser)
+		fields []string
+	}{
+		{"valid", func(u *NewUser) {}, nil},
+		{"missing email", func(u *NewUser) { u.Email = "" }, []string{"email"}},
+		{"negative age", func(u *NewUser) { u.Age = -3 }, []string{"age"}},
+		{"unknown gender and role", func(u *NewUser) { u.Gender = "OTHER"; u.Role = "ADMIN" }, []string{"role", "gender"}},
+		{"coordinates out of range", func(u *NewUser) { u.LonLat = LonLat{Longitude: 181, Latitude: -91} }, []string{"lonLat.latitude", "lonLat.longitude"}},
+		{"blank language", func(u *NewUser) { u.Languages = []string{"en", " "} }, []string{"languages[1]"}},
+		{"unknown location precision", func(u *NewUser) { u.Privacy.LocationPrecision = "CITY" }, []string{"privacy.locationPrecision"}},
+		{"service name used as a field path", func(u *NewUser) { u.Services["a.b"] = NeedAssistance }, []string{"services.a.b"}},
+		{
+			"recipient offering a service",
+			func(u *NewUser) { u.Services["Shopping"] = Provide },
+			[]string{"services.Shopping"},
+		},
+		{
+			"volunteer needing a service",
+			func(u *NewUser) {
+				u.Role = Volunteer
+				u.Services = map[string]MeetingAssistanceStatus{"Walk": NeedAssistance}
+			},
+			[]string{"services.Walk"},
+		},
+		{
+			"recipient claiming a meeting at registration",
+			func(u *NewUser) { u.Services["Shopping"] = InProgress },
+			[]string{"services.Shopping"},
+		},
+		{
+			"every invalid field at once",
+			func(u *NewUser) { *u = NewUser{Role: Volunteer} },
+			[]string{"email", "firstName", "lastName", "age", "gender"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := validNewUser()
+			tt.change(&user)
+			if got := invalidFields(t, user.Validate()); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("Validate() fields = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+}
+
+func TestUserValidateRejectsServicesInProgress(t *testing.T) {
+	user := User{
+		FirstName: "Ada",
+		LastName:  "Lovelace",
+		Age:       36,
+		Gender:    Female,
+		Role:      Recipient,
+		Services:  map[string]MeetingAssistanceStatus{"Shopping": InProgress, "Walk": NeedAssistance},
+	}
+
+	if got := invalidFields(t, user.Validate()); !reflect.DeepEqual(got, []string{"services.Shopping"}) {
+		t.Errorf("Validate() fields = %v, want [services.Shopping]", got)
+	}
+}
+
+func TestNewMeetingValidate(t *testing.T) {
+	valid := func() NewMeeting {
+		return NewMeeting{
+			Recipient: User{ID: "recipient"},
+			Volunteer: User{ID: "volunteer"},
+			Date:      time.Now().Add(24 * time.Hour),
+			Services:  []string{"Shopping"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(m *NewMeeting)
+		fields []string
+	}{
+		{"valid", func(m *NewMeeting) {}, nil},
+		{"picked status", func(m *NewMeeting) { m.MeetingStatus = IsPicked }, nil},
+		{"missing participants", func(m *NewMeeting) { m.Recipient.ID = ""; m.Volunteer.ID = "" }, []string{"recipient.uid", "volunteer.uid"}},
+		{"meeting with oneself", func(m *NewMeeting) { m.Volunteer.ID = "recipient" }, []string{"volunteer.uid"}},
+		{"missing date", func(m *NewMeeting) { m.Date = time.Time{} }, []string{"date"}},
+		{"date in the past", func(m *NewMeeting) { m.Date = time.Now().Add(-time.Hour) }, []string{"date"}},
+		{"date more than a year ahead", func(m *NewMeeting) { m.Date = time.Now().AddDate(1, 1, 0) }, []string{"date"}},
+		{"status set by the client", func(m *NewMeeting) { m.MeetingStatus = "COMPLETED" }, []string{"meetingStatus"}},
+		{"no services", func(m *NewMeeting) { m.Services = nil }, []string{"services"}},
+		{"invalid service name", func(m *NewMeeting) { m.Services = []string{"Shopping", "$where"} }, []string{"services[1]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meeting := valid()
+			tt.change(&meeting)
+			if got := invalidFields(t, meeting.Validate()); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("Validate() fields = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+}
+
+func TestValidateMeetingParticipants(t *testing.T) {
+	err := validateMeetingParticipants(User{Role: Volunteer}, User{Role: Recipient})
+	if got := invalidFields(t, err); !reflect.DeepEqual(got, []string{"recipient.uid", "volunteer.uid"}) {
+		t.Errorf("validateMeetingParticipants() fields = %v", got)
+	}
+	if err := validateMeetingParticipants(User{Role: Recipient}, User{Role: Volunteer}); err != nil {
+		t.Errorf("validateMeetingParticipants() = %v for the expected roles", err)
+	}
+}
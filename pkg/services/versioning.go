@@ -0,0 +1,91 @@
+package services
+
+import (
+	"encoding/json"
+	"sort"
+
+	"neighborguard/pkg/mergepatch"
+	"neighborguard/pkg/validation"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// AnyVersion is the expected version of an update without a precondition
+const AnyVersion int64 = -1
+
+// Fields of a user that a PUT or PATCH may change
+var updatableUserFields = []string{
+	"firstName", "lastName", "phoneNumber", "languages", "services",
+	"address", "lonLat", "timezone", "lastOK", "profileImage", "privacy",
+}
+
+// Fields of a meeting that a PATCH may change
+var updatableMeetingFields = []string{"date", "meetingStatus"}
+
+// versionFilter matches a document by ID only if it still has the given version.
+// Documents stored before versioning existed have no version and count as version 0.
+func versionFilter(id string, version int64) bson.M {
+	if version == 0 {
+		return bson.M{"_id": id, "version": bson.M{"$in": bson.A{nil, 0}}}
+	}
+	return bson.M{"_id": id, "version": version}
+}
+
+// checkVersion fails if the caller expects a version other than the current one.
+// An expected version of AnyVersion means the caller set no precondition.
+func checkVersion(current int64, expected int64) error {
+	if expected != AnyVersion && expected != current {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// lostUpdate is returned when a document changed between reading and writing it
+func lostUpdate(expected int64) error {
+	if expected != AnyVersion {
+		return ErrVersionMismatch
+	}
+	return ErrConcurrentUpdate
+}
+
+// applyMergePatch applies a JSON Merge Patch to the JSON form of current and
+// decodes the result into patched. Only the given top-level fields may be patched.
+func applyMergePatch(current interface{}, patch []byte, updatable []string, patched interface{}) error {
+	members, err := mergepatch.Keys(patch)
+	if err != nil {
+		return ErrMalformedPatch
+	}
+
+	allowed := make(map[string]bool, len(updatable))
+	for _, field := range updatable {
+		allowed[field] = true
+	}
+
+	// Report read-only fields in a stable order
+	fields := make([]string, 0, len(members))
+	for field := range members {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	v := &validation.Validator{}
+	for _, field := range fields {
+		v.Check(allowed[field], field, "cannot be changed")
+	}
+	if err := v.Err(); err != nil {
+		return invalid(err)
+	}
+
+	original, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	result, err := mergepatch.Apply(original, patch)
+	if err != nil {
+		return ErrMalformedPatch
+	}
+	if err := json.Unmarshal(result, patched); err != nil {
+		return invalid(validation.Errors{{Field: "body", Message: err.Error()}})
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testKey = []byte("a secret of at least thirty-two bytes")
+
+// signerAt returns a signer whose clock reads now
+func signerAt(key []byte, now time.Time) *Signer {
+	s := NewSigner(key, time.Hour)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestIssueAndVerify(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	for _, userID := range []string{"65f1c2a3b4d5e6f708192a3b", "user.with.dots", "ünïcode"} {
+		token, expiresAt := signerAt(testKey, now).Issue(userID)
+		if want := now.Add(time.Hour); !expiresAt.Equal(want) {
+			t.Errorf("Issue(%q) expires at %v, want %v", userID, expiresAt, want)
+		}
+
+		got, err := signerAt(testKey, now.Add(59*time.Minute)).Verify(token)
+		if err != nil || got != userID {
+			t.Errorf("Verify(Issue(%q)) = %q, %v", userID, got, err)
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	token, _ := signerAt(testKey, now).Issue("u1")
+	other, _ := signerAt(testKey, now).Issue("u2")
+	payload, signature, _ := cutLast(token, ".")
+	_, otherSignature, _ := cutLast(other, ".")
+
+	tests := []struct {
+		name   string
+		signer *Signer
+		token  string
+		want   error
+	}{
+		{"expired", signerAt(testKey, now.Add(time.Hour)), token, ErrExpiredToken},
+		{"another key", signerAt([]byte("another secret of thirty-two bytes"), now), token, ErrInvalidToken},
+		{"signature of another token", signerAt(testKey, now), payload + "." + otherSignature, ErrInvalidToken},
+		{"extended expiry", signerAt(testKey, now), strings.Replace(payload, ".", ".x", 1) + "." + signature, ErrInvalidToken},
+		{"missing signature", signerAt(testKey, now), payload, ErrInvalidToken},
+		{"empty", signerAt(testKey, now), "", ErrInvalidToken},
+		{"user ID alone", signerAt(testKey, now), "u1", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.signer.Verify(tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %q, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// FieldError describes why a single field of a payload is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors lists every invalid field of a payload
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		messages = append(messages, fmt.Sprintf("%s: %s", fieldError.Field, fieldError.Message))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validator collects field errors so that all of them can be reported at once
+type Validator struct {
+	errors Errors
+}
+
+// AddError records an invalid field
+func (v *Validator) AddError(field string, message string) {
+	v.errors = append(v.errors, FieldError{Field: field, Message: message})
+}
+
+// Check records an invalid field if ok is false
+func (v *Validator) Check(ok bool, field string, message string) {
+	if !ok {
+		v.AddError(field, message)
+	}
+}
+
+// Required checks that a string field is not blank
+func (v *Validator) Required(field string, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// Email checks that a field holds a single plain email address
+func (v *Validator) Email(field string, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.AddError(field, "is required")
+		return
+	}
+	address, err := mail.ParseAddress(value)
+	v.Check(err == nil && address.Address == value, field, "must be a valid email address")
+}
+
+// IntRange checks that an integer field lies within [min, max]
+func (v *Validator) IntRange(field string, value int, min int, max int) {
+	v.Check(value >= min && value <= max, field, fmt.Sprintf("must be between %d and %d", min, max))
+}
+
+// FloatRange checks that a number field lies within [min, max]
+func (v *Validator) FloatRange(field string, value float64, min float64, max float64) {
+	v.Check(value >= min && value <= max, field, fmt.Sprintf("must be between %g and %g", min, max))
+}
+
+// OneOf checks that a field holds one of the allowed values
+func OneOf[T ~string](v *Validator, field string, value T, allowed ...T) {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+
+	names := make([]string, 0, len(allowed))
+	for _, candidate := range allowed {
+		names = append(names, string(candidate))
+	}
+	v.AddError(field, "must be one of "+strings.Join(names, ", "))
+}
+
+// Valid reports whether no field errors were recorded
+func (v *Validator) Valid() bool {
+	return len(v.errors) == 0
+}
+
+// Err returns the recorded field errors, or nil if the payload is valid
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return v.errors
+}
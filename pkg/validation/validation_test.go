@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type color string
+
+func TestValidatorCollectsEveryError(t *testing.T) {
+	v := &Validator{}
+	v.Required("firstName", "  ")
+	v.Email("email", "ada@example.com")
+	v.IntRange("age", 0, 1, 120)
+	v.FloatRange("lonLat.latitude", 45.76, -90, 90)
+	OneOf(v, "color", color("PURPLE"), "RED", "GREEN")
+
+	var fieldErrors Errors
+	if !errors.As(v.Err(), &fieldErrors) {
+		t.Fatalf("Err() = %v, want validation errors", v.Err())
+	}
+	want := Errors{
+		{Field: "firstName", Message: "is required"},
+		{Field: "age", Message: "must be between 1 and 120"},
+		{Field: "color", Message: "must be one of RED, GREEN"},
+	}
+	if !reflect.DeepEqual(fieldErrors, want) {
+		t.Errorf("Err() = %v, want %v", fieldErrors, want)
+	}
+	if v.Valid() {
+		t.Error("Valid() = true with errors recorded")
+	}
+}
+
+func TestValidatorWithoutErrors(t *testing.T) {
+	v := &Validator{}
+	v.Required("firstName", "Ada")
+	OneOf(v, "color", color("RED"), "RED", "GREEN")
+
+	if !v.Valid() || v.Err() != nil {
+		t.Errorf("Valid() = %v, Err() = %v, want a valid payload", v.Valid(), v.Err())
+	}
+}
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		value   string
+		message string
+	}{
+		{"ada@example.com", ""},
+		{"", "is required"},
+		{"ada", "must be a valid email address"},
+		{"Ada <ada@example.com>", "must be a valid email address"},
+		{"ada@example.com, bob@example.com", "must be a valid email address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			v := &Validator{}
+			v.Email("email", tt.value)
+
+			message := ""
+			if !v.Valid() {
+				message = v.errors[0].Message
+			}
+			if message != tt.message {
+				t.Errorf("Email(%q) message = %q, want %q", tt.value, message, tt.message)
+			}
+		})
+	}
+}